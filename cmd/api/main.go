@@ -12,6 +12,7 @@ import (
 	"time"
 
 	authHandlers "system-portal/internal/domains/auth/handlers"
+	authRepo "system-portal/internal/domains/auth/repositories"
 	sessionRepoimpl "system-portal/internal/domains/auth/repositories/impl"
 	authRoutes "system-portal/internal/domains/auth/routes"
 	authUsecases "system-portal/internal/domains/auth/usecases"
@@ -26,12 +27,16 @@ import (
 	portalUsecases "system-portal/internal/domains/portal/usecases"
 	"system-portal/internal/shared/config"
 	"system-portal/internal/shared/database"
+	"system-portal/internal/shared/infrastructure/archive"
+	"system-portal/internal/shared/infrastructure/auditsink"
 	serverHttp "system-portal/internal/shared/infrastructure/http"
 	"system-portal/internal/shared/infrastructure/ldap"
 	"system-portal/internal/shared/infrastructure/xmlrpc"
 	"system-portal/internal/shared/middleware"
 	"system-portal/pkg/jwt"
 	"system-portal/pkg/logger"
+	"system-portal/pkg/oidc"
+	"system-portal/pkg/validator"
 )
 
 func main() {
@@ -67,7 +72,9 @@ func main() {
 
 	// Initialize JWT service
 	var jwtService *jwt.RSAService
-	if cfg.JWT.AccessPrivateKeyPath != "" && cfg.JWT.RefreshPrivateKeyPath != "" {
+	if cfg.JWT.AccessKeysDir != "" && cfg.JWT.RefreshPrivateKeyPath != "" {
+		jwtService = newKeyringJWTService(cfg.JWT)
+	} else if cfg.JWT.AccessPrivateKeyPath != "" && cfg.JWT.RefreshPrivateKeyPath != "" {
 		accessData, err := os.ReadFile(cfg.JWT.AccessPrivateKeyPath)
 		if err != nil {
 			logger.Log.Fatal("failed to read access key file:", err)
@@ -93,14 +100,27 @@ func main() {
 		logger.Log.Warn("generated new RSA keys; store them in config to preserve sessions")
 	}
 
-	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
-	corsMiddleware := middleware.NewCorsMiddleware(cfg.Security.CORS)
-	validationMiddleware := middleware.NewValidationMiddleware()
+	// Sessions are shared by the auth usecase and middleware so revocations
+	// evict the in-process cache immediately
+	sessionRepo := sessionRepoimpl.NewCachedSessionRepository(
+		sessionRepoimpl.NewSessionRepositoryPG(db.DB),
+		cfg.Security.Session.CacheTTL,
+	)
+
+	// Audit entries are forwarded to external collectors; whatever is still
+	// queued at shutdown is dead-lettered for redelivery
+	auditForwarder := newAuditForwarder(cfg.Security.Audit.Sinks, db)
+	if auditForwarder != nil {
+		defer auditForwarder.Close()
+	}
 
 	// Initialize domain handlers and routes
-	auditUC, userRepo, groupRepo := initializeDomainRoutes(cfg, db, jwtService)
+	auditUC, userRepo, groupRepo, apiTokenUsecase := initializeDomainRoutes(cfg, db, jwtService, sessionRepo, auditForwarder)
 
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, sessionRepo, apiTokenUsecase, cfg.Security.Session.IdleTimeout)
+	corsMiddleware := middleware.NewCorsMiddleware(cfg.Security.CORS)
+	validationMiddleware := middleware.NewValidationMiddleware()
 	auditMiddleware := middleware.NewAuditMiddleware(auditUC, userRepo, groupRepo)
 
 	// Create router configuration
@@ -128,41 +148,366 @@ func main() {
 	}
 }
 
-func initializeDomainRoutes(cfg *config.Config, db *database.Postgres, jwtSvc *jwt.RSAService) (portalUsecases.AuditUsecase, portalRepo.UserRepository, portalRepo.GroupRepository) {
+func initializeDomainRoutes(cfg *config.Config, db *database.Postgres, jwtSvc *jwt.RSAService, sessionRepo authRepo.SessionRepository, auditForwarder *portalUsecases.AuditForwarder) (portalUsecases.AuditUsecase, portalRepo.UserRepository, portalRepo.GroupRepository, authUsecases.APITokenUsecase) {
 	// Portal domain using PostgreSQL repositories
 	userRepo := portalRepoImpl.NewUserRepositoryPG(db.DB)
 	groupRepo := portalRepoImpl.NewGroupRepositoryPG(db.DB)
-	auditRepo := portalRepoImpl.NewAuditRepositoryPG(db.DB)
-	permRepo := portalRepoImpl.NewPermissionRepositoryPG(db.DB)
+	var auditRepo portalRepo.AuditRepository = portalRepoImpl.NewAuditRepositoryPG(db.DB)
+	if auditForwarder != nil {
+		auditRepo = auditForwarder.Repository(auditRepo)
+	}
+	permRepo := newPermissionRepository(cfg, db)
+	ldapRepo := portalRepoImpl.NewLDAPConfigRepositoryPG(db.DB, cfg.Security.EncryptionKey)
+	ldapMappingRepo := portalRepoImpl.NewLDAPGroupMappingRepositoryPG(db.DB)
 
 	// Auth domain
-	sessionRepo := sessionRepoimpl.NewSessionRepositoryPG(db.DB)
-	authUsecase := authUsecases.NewAuthUsecase(sessionRepo, userRepo, groupRepo, jwtSvc)
+	mfaRepo := sessionRepoimpl.NewMFARepositoryPG(db.DB, cfg.Security.EncryptionKey)
+	mfaUsecase := authUsecases.NewMFAUsecase(mfaRepo, userRepo, groupRepo, cfg.Security.MFA.Issuer)
+	lockoutUsecase := authUsecases.NewLockoutUsecase(sessionRepoimpl.NewLoginAttemptRepositoryPG(db.DB), auditRepo, lockoutPolicy(cfg.Security.Lockout))
+	authUsecase := authUsecases.NewAuthUsecase(sessionRepo, userRepo, groupRepo, auditRepo, ldapRepo, ldapMappingRepo, mfaRepo, mfaUsecase, lockoutUsecase, jwtSvc, cfg.Security.MFA.ChallengeTTL)
+	sessionUsecase := authUsecases.NewSessionUsecase(sessionRepo)
 	authHandler := authHandlers.NewAuthHandler(authUsecase)
-	authRoutes.Initialize(authHandler)
+	sessionHandler := authHandlers.NewSessionHandler(sessionUsecase)
+	mfaHandler := authHandlers.NewMFAHandler(mfaUsecase)
+	oidcUsecase := authUsecases.NewOIDCUsecase(newOIDCProvider(cfg.Security.OIDC), sessionRepoimpl.NewOIDCStateRepositoryPG(db.DB), authUsecase, userRepo, groupRepo, auditRepo, oidcSettings(cfg.Security.OIDC))
+	oidcHandler := authHandlers.NewOIDCHandler(oidcUsecase, cfg.Security.OIDC.PostLoginRedirectURL)
+	apiTokenUsecase := authUsecases.NewAPITokenUsecase(sessionRepoimpl.NewAPITokenRepositoryPG(db.DB), userRepo, groupRepo, permRepo, cfg.Security.APITokens.MaxLifetime)
+	apiTokenHandler := authHandlers.NewAPITokenHandler(apiTokenUsecase)
+	profileUsecase := authUsecases.NewProfileUsecase(userRepo, groupRepo, permRepo, auditRepo, sessionRepo, lockoutUsecase, passwordPolicy(cfg.Validation.Password))
+	profileHandler := authHandlers.NewProfileHandler(profileUsecase)
+	authRoutes.Initialize(authHandler, sessionHandler, mfaHandler, oidcHandler, apiTokenHandler, authHandlers.NewJWKSHandler(jwtSvc), profileHandler)
 
 	userUC := portalUsecases.NewUserUsecase(userRepo, groupRepo)
 	groupUC := portalUsecases.NewGroupUsecase(groupRepo, permRepo)
 	permUC := portalUsecases.NewPermissionUsecase(permRepo)
 	auditUC := portalUsecases.NewAuditUsecase(auditRepo)
 
-	userHandler := portalHandlers.NewUserHandler(userUC)
+	userHandler := portalHandlers.NewUserHandler(userUC, sessionUsecase, lockoutUsecase)
 	groupHandler := portalHandlers.NewGroupHandler(groupUC)
 	permHandler := portalHandlers.NewPermissionHandler(permUC)
-	auditHandler := portalHandlers.NewAuditHandler(auditUC)
-	dashboardHandler := portalHandlers.NewDashboardHandler(userRepo, auditRepo)
+	checkpointKey, err := portalUsecases.ParseCheckpointKey(cfg.Security.Audit.CheckpointKey)
+	if err != nil {
+		logger.Log.Fatalf("invalid audit configuration: %v", err)
+	}
+	trustedKeys, err := portalUsecases.ParseCheckpointPublicKeys(cfg.Security.Audit.TrustedCheckpointKeys)
+	if err != nil {
+		logger.Log.Fatalf("invalid audit configuration: %v", err)
+	}
+	auditChainUC := portalUsecases.NewAuditChainUsecase(auditRepo, portalRepoImpl.NewAuditCheckpointRepositoryPG(db.DB), checkpointKey, trustedKeys)
+	if checkpointKey != nil {
+		startAuditCheckpointer(auditChainUC, cfg.Security.Audit.CheckpointInterval)
+	} else {
+		logger.Log.Warn("audit checkpoint key not configured; chain checkpoints are disabled")
+	}
+	if rc := cfg.Security.Audit.Retention; rc.Enabled {
+		retentionUC := portalUsecases.NewRetentionUsecase(portalRepoImpl.NewAuditArchiveRepositoryPG(db.DB), auditRepo, archive.NewDirSink(rc.ArchiveDir), retentionPolicy(rc))
+		startRetentionJob(retentionUC, rc.Interval)
+	}
+	auditHandler := portalHandlers.NewAuditHandler(auditUC, auditChainUC)
+	dashboardUC := portalUsecases.NewDashboardUsecase(userRepo, auditRepo)
+	dashboardHandler := portalHandlers.NewDashboardHandler(dashboardUC)
+
+	// Destructive operations selected in security.approval wait for a second user
+	changeRequestRepo := portalRepoImpl.NewChangeRequestRepositoryPG(db.DB, cfg.Security.EncryptionKey)
+	approvalUC := portalUsecases.NewApprovalUsecase(changeRequestRepo, permRepo, auditRepo, portalUsecases.ApprovalPolicy{
+		Permissions: cfg.Security.Approval.Permissions,
+		TTL:         cfg.Security.Approval.TTL,
+	})
+	approvalHandler := portalHandlers.NewApprovalHandler(approvalUC)
+
+	// Time-bound elevation, honoured by the permission middleware until expiry
+	elevationRepo := portalRepoImpl.NewElevationRepositoryPG(db.DB)
+	elevationUC := portalUsecases.NewElevationUsecase(elevationRepo, permRepo, groupRepo, auditRepo, elevationPolicy(cfg.Security.Elevation))
+	elevationHandler := portalHandlers.NewElevationHandler(elevationUC)
+	startElevationSweeper(elevationUC, cfg.Security.Elevation.SweepInterval)
 
 	ovRepo := portalRepoImpl.NewOpenVPNConfigRepositoryPG(db.DB, cfg.Security.EncryptionKey)
-	ldapRepo := portalRepoImpl.NewLDAPConfigRepositoryPG(db.DB, cfg.Security.EncryptionKey)
-	configUC := portalUsecases.NewConfigUsecase(ovRepo, ldapRepo)
-	reloadOpenVPN := configureOpenVPN(db, permRepo, groupRepo, cfg.Security.EncryptionKey)
-	configHandler := portalHandlers.NewConfigHandler(configUC, reloadOpenVPN)
-	portalRoutes.Initialize(userHandler, groupHandler, permHandler, auditHandler, dashboardHandler, configHandler)
+	configUC := portalUsecases.NewConfigUsecase(ovRepo, ldapRepo, ldapMappingRepo, groupRepo)
+	reloadOpenVPN := configureOpenVPN(db, permRepo, groupRepo, elevationRepo, cfg.Security.EncryptionKey, approvalUC, approvalHandler, dashboardUC)
+	configHandler := portalHandlers.NewConfigHandler(configUC, reloadOpenVPN, approvalHandler)
+	configHandler.RegisterChanges(approvalUC)
+	portalRoutes.Initialize(userHandler, groupHandler, permHandler, auditHandler, dashboardHandler, configHandler, approvalHandler, elevationHandler, middleware.NewPermissionMiddleware(permRepo, groupRepo, elevationRepo))
 
 	// Initialize OpenVPN routes based on existing configs
 	reloadOpenVPN()
 
-	return auditUC, userRepo, groupRepo
+	return auditUC, userRepo, groupRepo, apiTokenUsecase
+}
+
+// newKeyringJWTService signs access tokens with the rotating keyring in
+// jwt.accessKeysDir and keeps re-reading it so rotations made with
+// cmd/jwt-keys take effect without a restart.
+func newKeyringJWTService(c config.JWTConfig) *jwt.RSAService {
+	ring, err := jwt.LoadKeyring(c.AccessKeysDir)
+	if err != nil {
+		logger.Log.Fatal("failed to load access key ring:", err)
+	}
+	refreshData, err := os.ReadFile(c.RefreshPrivateKeyPath)
+	if err != nil {
+		logger.Log.Fatal("failed to read refresh key file:", err)
+	}
+	svc, err := jwt.NewRSAServiceWithKeyring(ring, string(refreshData), c.AccessTokenExpireDuration, c.RefreshTokenExpireDuration)
+	if err != nil {
+		logger.Log.Fatal("failed to load RSA keys:", err)
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"kid":  ring.Active,
+		"keys": len(ring.Keys),
+	}).Info("access token key ring loaded")
+
+	if c.KeyReloadInterval > 0 {
+		go func() {
+			for range time.Tick(c.KeyReloadInterval) {
+				ring, err := jwt.LoadKeyring(c.AccessKeysDir)
+				if err != nil {
+					logger.Log.WithError(err).Warn("failed to reload access key ring")
+					continue
+				}
+				previous := svc.AccessKeyID()
+				if err := svc.UseKeyring(ring); err != nil {
+					logger.Log.WithError(err).Warn("failed to apply access key ring")
+					continue
+				}
+				if ring.Active != previous {
+					logger.Log.WithField("kid", ring.Active).Info("access token signing key rotated")
+				}
+			}
+		}()
+	}
+	return svc
+}
+
+// newPermissionRepository wraps the permission repository with the group
+// grant cache checked on every request, listening for changes made by other
+// replicas when enabled.
+func newPermissionRepository(cfg *config.Config, db *database.Postgres) *portalRepoImpl.CachedPermissionRepository {
+	c := cfg.Security.PermissionCache
+	repo := portalRepoImpl.NewCachedPermissionRepository(portalRepoImpl.NewPermissionRepositoryPG(db.DB), db.DB, c.TTL)
+	if c.TTL > 0 && c.Listen {
+		if err := repo.Listen(cfg.Database.DSN()); err != nil {
+			logger.Log.WithError(err).Warn("failed to listen for permission changes, relying on cache ttl")
+		}
+	}
+	return repo
+}
+
+func retentionPolicy(c config.AuditRetentionConfig) portalUsecases.RetentionPolicy {
+	return portalUsecases.RetentionPolicy{
+		AuditDefault:       c.AuditLogs,
+		AuditResourceTypes: c.ResourceTypes,
+		Sessions:           c.Sessions,
+		RestoreHold:        c.RestoreHold,
+		BatchSize:          c.BatchSize,
+	}
+}
+
+func elevationPolicy(c config.ElevationConfig) portalUsecases.ElevationPolicy {
+	p := portalUsecases.ElevationPolicy{MaxDuration: c.MaxDuration, PendingTTL: c.PendingTTL}
+	for _, r := range c.AutoApprove {
+		p.AutoApprove = append(p.AutoApprove, portalUsecases.ElevationRule{
+			Permission:      r.Permission,
+			Group:           r.Group,
+			VPNGroupScope:   r.VPNGroupScope,
+			RequesterGroups: r.RequesterGroups,
+			MaxDuration:     r.MaxDuration,
+		})
+	}
+	return p
+}
+
+// startElevationSweeper periodically expires ended elevation grants so their
+// end is audited. Grants stop working at expiry even between sweeps.
+func startElevationSweeper(uc portalUsecases.ElevationUsecase, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			n, err := uc.Sweep(context.Background())
+			if err != nil {
+				logger.Log.WithError(err).Warn("failed to sweep elevation grants")
+				continue
+			}
+			if n > 0 {
+				logger.Log.WithField("count", n).Info("elevation grants expired")
+			}
+		}
+	}()
+}
+
+// startAuditCheckpointer periodically signs the head of the audit chain.
+func startAuditCheckpointer(uc portalUsecases.AuditChainUsecase, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			cp, err := uc.Checkpoint(context.Background())
+			if err != nil {
+				logger.Log.WithError(err).Warn("failed to checkpoint audit chain")
+				continue
+			}
+			if cp != nil {
+				logger.Log.WithField("seq", cp.Seq).Info("audit chain checkpointed")
+			}
+		}
+	}()
+}
+
+// newAuditForwarder builds the configured audit sinks, or returns nil when
+// none are configured.
+func newAuditForwarder(c config.AuditSinksConfig, db *database.Postgres) *portalUsecases.AuditForwarder {
+	var sinks []portalUsecases.AuditSink
+	for _, sc := range c.Syslog {
+		s, err := auditsink.NewSyslog(auditsink.SyslogConfig{
+			Name:               sc.Name,
+			Network:            sc.Network,
+			Address:            sc.Address,
+			Facility:           sc.Facility,
+			Format:             sinkFormat(sc.Format),
+			CAFile:             sc.CAFile,
+			InsecureSkipVerify: sc.InsecureSkipVerify,
+		})
+		if err != nil {
+			logger.Log.Fatalf("invalid audit sink: %v", err)
+		}
+		sinks = append(sinks, s)
+	}
+	for _, wc := range c.Webhooks {
+		w, err := auditsink.NewWebhook(auditsink.WebhookConfig{
+			Name:    wc.Name,
+			URL:     wc.URL,
+			Secret:  wc.Secret,
+			Format:  sinkFormat(wc.Format),
+			Headers: wc.Headers,
+		})
+		if err != nil {
+			logger.Log.Fatalf("invalid audit sink: %v", err)
+		}
+		sinks = append(sinks, w)
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	f, err := portalUsecases.NewAuditForwarder(sinks, portalRepoImpl.NewAuditDeadLetterRepositoryPG(db.DB), portalUsecases.AuditForwardPolicy{
+		QueueSize:      c.QueueSize,
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+		Timeout:        c.Timeout,
+	})
+	if err != nil {
+		logger.Log.Fatalf("invalid audit sink: %v", err)
+	}
+	startAuditRedelivery(f, c.RedeliverInterval)
+	logger.Log.WithField("sinks", len(sinks)).Info("audit forwarding enabled")
+	return f
+}
+
+func sinkFormat(f string) string {
+	if f == "" {
+		return portalUsecases.AuditFormatJSONL
+	}
+	return f
+}
+
+// startAuditRedelivery periodically retries dead-lettered audit entries.
+func startAuditRedelivery(f *portalUsecases.AuditForwarder, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			n, err := f.Redeliver(context.Background(), 500)
+			if err != nil {
+				logger.Log.WithError(err).Warn("failed to redeliver audit entries")
+				continue
+			}
+			if n > 0 {
+				logger.Log.WithField("count", n).Info("dead-lettered audit entries delivered")
+			}
+		}
+	}()
+}
+
+// startRetentionJob periodically archives and deletes expired audit entries
+// and sessions.
+func startRetentionJob(uc portalUsecases.RetentionUsecase, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			res, err := uc.Run(context.Background())
+			if err != nil {
+				logger.Log.WithError(err).Warn("failed to apply audit retention")
+			}
+			if res != nil && len(res.Archives) > 0 {
+				logger.Log.WithFields(map[string]interface{}{
+					"auditLogs": res.AuditLogs,
+					"sessions":  res.UserSessions,
+					"archives":  len(res.Archives),
+				}).Info("expired rows archived")
+			}
+		}
+	}()
+}
+
+// newOIDCProvider returns the configured OpenID provider, or nil when single
+// sign-on is disabled.
+func newOIDCProvider(c config.OIDCConfig) *oidc.Provider {
+	if !c.Enabled {
+		return nil
+	}
+	logger.Log.WithField("issuer", c.IssuerURL).Info("oidc single sign-on enabled")
+	return oidc.NewProvider(oidc.Config{
+		IssuerURL:    c.IssuerURL,
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       c.Scopes,
+	})
+}
+
+func oidcSettings(c config.OIDCConfig) authUsecases.OIDCSettings {
+	s := authUsecases.OIDCSettings{
+		UsernameClaim: c.UsernameClaim,
+		GroupsClaim:   c.GroupsClaim,
+		StateTTL:      c.StateTTL,
+		TrustIdPMFA:   c.TrustIdPMFA,
+		MFAMethods:    c.MFAMethods,
+		MFAACRValues:  c.MFAACRValues,
+	}
+	for _, m := range c.GroupMappings {
+		s.GroupMappings = append(s.GroupMappings, authUsecases.OIDCGroupMapping{Claim: m.Claim, Group: m.Group})
+	}
+	return s
+}
+
+// lockoutPolicy converts the lockout config; when disabled all thresholds
+// are zero so no failures are tracked.
+func lockoutPolicy(c config.LockoutConfig) authUsecases.LockoutPolicy {
+	if !c.Enabled {
+		return authUsecases.LockoutPolicy{}
+	}
+	return authUsecases.LockoutPolicy{
+		MaxUserFailures: c.MaxUserFailures,
+		MaxIPFailures:   c.MaxIPFailures,
+		Window:          c.Window,
+		LockoutDuration: c.Duration,
+		BaseDelay:       c.BaseDelay,
+		MaxDelay:        c.MaxDelay,
+	}
+}
+
+func passwordPolicy(c config.PasswordPolicyConfig) validator.PasswordPolicy {
+	return validator.PasswordPolicy{
+		MinLength:           c.MinLength,
+		RequireUppercase:    c.RequireUppercase,
+		RequireLowercase:    c.RequireLowercase,
+		RequireNumbers:      c.RequireNumbers,
+		RequireSpecialChars: c.RequireSpecialChars,
+	}
 }
 
 // waitForPostgres pings the database until it responds or retries are exhausted.
@@ -203,7 +548,7 @@ func checkConnections(db *database.Postgres, ldapClient *ldap.Client, xmlClient
 	return nil
 }
 
-func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepository, groupRepo portalRepo.GroupRepository, encKey string) func() {
+func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepository, groupRepo portalRepo.GroupRepository, elevationRepo portalRepo.ElevationRepository, encKey string, approvals portalUsecases.ApprovalUsecase, gate openvpnHandlers.ApprovalGate, dashboard portalUsecases.DashboardUsecase) func() {
 	return func() {
 		ovRepo := portalRepoImpl.NewOpenVPNConfigRepositoryPG(db.DB, encKey)
 		ldapRepo := portalRepoImpl.NewLDAPConfigRepositoryPG(db.DB, encKey)
@@ -211,6 +556,11 @@ func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepos
 		ldapCfg, _ := ldapRepo.Get(context.Background())
 		if ovCfg == nil {
 			openvpnRoutes.Disable()
+			// Pending requests cannot run without a connection
+			for _, op := range []string{openvpnHandlers.OpDeleteUser, openvpnHandlers.OpDeleteGroup, openvpnHandlers.OpBulkUserActions, openvpnHandlers.OpBulkGroupActions} {
+				approvals.Register(op, nil)
+			}
+			dashboard.SetVPN(nil)
 			return
 		}
 		xmlrpcClient := xmlrpc.NewClient(xmlrpc.Config{
@@ -236,8 +586,9 @@ func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepos
 			logger.Log.WithError(err).Warn("connectivity check failed")
 		}
 
-		userRepoOV := openvpnRepo.NewUserRepository(xmlrpcClient)
-		groupRepoOV := openvpnRepo.NewGroupRepository(xmlrpcClient)
+		historyRepoOV := openvpnRepo.NewChangeHistoryRepository(db.DB)
+		userRepoOV := openvpnRepo.NewHistoryUserRepository(openvpnRepo.NewUserRepository(xmlrpcClient), historyRepoOV)
+		groupRepoOV := openvpnRepo.NewHistoryGroupRepository(openvpnRepo.NewGroupRepository(xmlrpcClient), historyRepoOV)
 		disconnectRepo := openvpnRepo.NewDisconnectRepository(xmlrpcClient)
 		vpnStatusRepo := openvpnRepo.NewVPNStatusRepository(xmlrpcClient)
 		configRepoOV := openvpnRepo.NewConfigRepository(xmlrpcClient)
@@ -248,14 +599,23 @@ func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepos
 		disconnectUC := openvpnUsecases.NewDisconnectUsecase(userRepoOV, disconnectRepo, vpnStatusRepo)
 		configUCOV := openvpnUsecases.NewConfigUsecase(configRepoOV)
 		vpnStatusUC := openvpnUsecases.NewVPNStatusUsecase(vpnStatusRepo)
+		historyUC := openvpnUsecases.NewChangeHistoryUsecase(historyRepoOV)
 
-		userHandlerOV := openvpnHandlers.NewUserHandler(userUCOV, xmlrpcClient)
-		groupHandlerOV := openvpnHandlers.NewGroupHandler(groupUCOV, configUCOV, xmlrpcClient)
-		bulkHandlerOV := openvpnHandlers.NewBulkHandler(bulkUCOV, xmlrpcClient)
+		userHandlerOV := openvpnHandlers.NewUserHandler(userUCOV, xmlrpcClient, gate)
+		groupHandlerOV := openvpnHandlers.NewGroupHandler(groupUCOV, configUCOV, xmlrpcClient, gate)
+		bulkHandlerOV := openvpnHandlers.NewBulkHandler(bulkUCOV, xmlrpcClient, gate)
 		configHandlerOV := openvpnHandlers.NewConfigHandler(configUCOV)
 		vpnStatusHandlerOV := openvpnHandlers.NewVPNStatusHandler(vpnStatusUC)
 		disconnectHandlerOV := openvpnHandlers.NewDisconnectHandler(disconnectUC)
-		permMiddleware := middleware.NewPermissionMiddleware(permRepo, groupRepo)
+		historyHandlerOV := openvpnHandlers.NewChangeHistoryHandler(historyUC)
+		permMiddleware := middleware.NewPermissionMiddleware(permRepo, groupRepo, elevationRepo)
+
+		// Approved requests run against the connection configured now
+		approvals.Register(openvpnHandlers.OpDeleteUser, userHandlerOV.ApplyDeleteUser)
+		approvals.Register(openvpnHandlers.OpDeleteGroup, groupHandlerOV.ApplyDeleteGroup)
+		approvals.Register(openvpnHandlers.OpBulkUserActions, bulkHandlerOV.ApplyBulkUserActions)
+		approvals.Register(openvpnHandlers.OpBulkGroupActions, bulkHandlerOV.ApplyBulkGroupActions)
+		dashboard.SetVPN(portalUsecases.NewVPNDirectory(userRepoOV, vpnStatusRepo))
 
 		openvpnRoutes.Initialize(
 			userHandlerOV,
@@ -264,6 +624,7 @@ func configureOpenVPN(db *database.Postgres, permRepo portalRepo.PermissionRepos
 			configHandlerOV,
 			vpnStatusHandlerOV,
 			disconnectHandlerOV,
+			historyHandlerOV,
 			permMiddleware,
 		)
 	}
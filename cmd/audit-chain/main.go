@@ -0,0 +1,128 @@
+// Command audit-chain checks and anchors the audit log hash chain:
+//
+//	go run ./cmd/audit-chain verify            # walk the chain, exit 1 if broken
+//	go run ./cmd/audit-chain checkpoint        # sign the current chain head
+//	go run ./cmd/audit-chain export -after 0   # print checkpoints as JSON lines
+//	go run ./cmd/audit-chain keygen            # new security.audit.checkpointKey
+//
+// Exported checkpoints should be stored outside the portal database; a
+// chain that no longer contains them has been rewritten or truncated.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"system-portal/internal/domains/portal/dto"
+	portalRepoImpl "system-portal/internal/domains/portal/repositories/impl"
+	"system-portal/internal/domains/portal/usecases"
+	"system-portal/internal/shared/config"
+	"system-portal/internal/shared/database"
+)
+
+func main() {
+	fs := flag.NewFlagSet("audit-chain", flag.ExitOnError)
+	after := fs.Int64("after", 0, "export checkpoints after this chain position")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: audit-chain verify|checkpoint|export [-after seq]|keygen")
+		fs.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	fs.Parse(args)
+
+	if cmd == "keygen" {
+		keygen()
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("failed to load config: %v", err)
+	}
+	key, err := usecases.ParseCheckpointKey(cfg.Security.Audit.CheckpointKey)
+	if err != nil {
+		fatal("%v", err)
+	}
+	trusted, err := usecases.ParseCheckpointPublicKeys(cfg.Security.Audit.TrustedCheckpointKeys)
+	if err != nil {
+		fatal("%v", err)
+	}
+	pg, err := database.New(cfg.Database)
+	if err != nil {
+		fatal("database connection error: %v", err)
+	}
+	defer pg.Close()
+	chain := usecases.NewAuditChainUsecase(portalRepoImpl.NewAuditRepositoryPG(pg.DB), portalRepoImpl.NewAuditCheckpointRepositoryPG(pg.DB), key, trusted)
+	ctx := context.Background()
+
+	switch cmd {
+	case "verify":
+		report, err := chain.Verify(ctx)
+		if err != nil {
+			fatal("%v", err)
+		}
+		fmt.Printf("checked %d entries (%d archived, %d before the chain), %d checkpoints\n",
+			report.Checked, report.Archived, report.Unchained, report.CheckpointsChecked)
+		if !report.Valid {
+			fmt.Printf("BROKEN at seq %d (%s): %s\n", report.BrokenSeq, report.BrokenID, report.Reason)
+			os.Exit(1)
+		}
+		fmt.Printf("ok, head seq %d hash %s\n", report.HeadSeq, report.HeadHash)
+	case "checkpoint":
+		cp, err := chain.Checkpoint(ctx)
+		if err != nil {
+			fatal("%v", err)
+		}
+		if cp == nil {
+			fmt.Println("chain head already checkpointed")
+			return
+		}
+		fmt.Printf("checkpointed seq %d hash %s key %s\n", cp.Seq, cp.Hash, cp.KeyID)
+	case "export":
+		enc := json.NewEncoder(os.Stdout)
+		for {
+			items, err := chain.ListCheckpoints(ctx, *after, 1000)
+			if err != nil {
+				fatal("%v", err)
+			}
+			for _, cp := range items {
+				if err := enc.Encode(dto.NewAuditCheckpointResponse(cp)); err != nil {
+					fatal("%v", err)
+				}
+				*after = cp.Seq
+			}
+			if len(items) < 1000 {
+				return
+			}
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func keygen() {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fatal("%v", err)
+	}
+	fmt.Println("checkpointKey:", base64.StdEncoding.EncodeToString(priv.Seed()))
+	fmt.Println("public key:   ", base64.StdEncoding.EncodeToString(pub))
+	fmt.Println("key id:       ", usecases.CheckpointKeyID(pub))
+	fmt.Println("after rotating, add the old public key to security.audit.trustedCheckpointKeys")
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "audit-chain: "+format+"\n", args...)
+	os.Exit(1)
+}
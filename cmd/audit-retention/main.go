@@ -0,0 +1,110 @@
+// Command audit-retention applies audit retention and restores archives:
+//
+//	go run ./cmd/audit-retention run                     # archive and delete expired rows now
+//	go run ./cmd/audit-retention list [-source audit_logs]
+//	go run ./cmd/audit-retention restore <name>          # re-import an audit archive
+//
+// Restored entries keep their chain position and are kept for
+// security.audit.retention.restoreHold before retention archives them again.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	portalRepoImpl "system-portal/internal/domains/portal/repositories/impl"
+	"system-portal/internal/domains/portal/usecases"
+	"system-portal/internal/shared/config"
+	"system-portal/internal/shared/database"
+	"system-portal/internal/shared/infrastructure/archive"
+)
+
+func main() {
+	fs := flag.NewFlagSet("audit-retention", flag.ExitOnError)
+	source := fs.String("source", "", "list only archives of this table")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: audit-retention run|list [-source table]|restore <name>")
+		fs.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("failed to load config: %v", err)
+	}
+	pg, err := database.New(cfg.Database)
+	if err != nil {
+		fatal("database connection error: %v", err)
+	}
+	defer pg.Close()
+	rc := cfg.Security.Audit.Retention
+	uc := usecases.NewRetentionUsecase(
+		portalRepoImpl.NewAuditArchiveRepositoryPG(pg.DB),
+		portalRepoImpl.NewAuditRepositoryPG(pg.DB),
+		archive.NewDirSink(rc.ArchiveDir),
+		usecases.RetentionPolicy{
+			AuditDefault:       rc.AuditLogs,
+			AuditResourceTypes: rc.ResourceTypes,
+			Sessions:           rc.Sessions,
+			RestoreHold:        rc.RestoreHold,
+			BatchSize:          rc.BatchSize,
+		})
+	ctx := context.Background()
+
+	switch cmd {
+	case "run":
+		res, err := uc.Run(ctx)
+		if res != nil && res.Skipped {
+			fmt.Println("another retention run is in progress")
+			return
+		}
+		if res != nil {
+			for _, a := range res.Archives {
+				fmt.Printf("archived %d %s rows to %s\n", a.Rows, a.Source, a.Name)
+			}
+			fmt.Printf("%d audit entries, %d sessions archived\n", res.AuditLogs, res.UserSessions)
+		}
+		if err != nil {
+			fatal("%v", err)
+		}
+	case "list":
+		items, err := uc.ListArchives(ctx, *source)
+		if err != nil {
+			fatal("%v", err)
+		}
+		for _, a := range items {
+			restored := ""
+			if a.RestoredAt != nil {
+				restored = " restored " + a.RestoredAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%d rows\t%s .. %s%s\n", a.Name, a.Source, a.Rows,
+				a.FromTime.Format(time.RFC3339), a.ToTime.Format(time.RFC3339), restored)
+		}
+	case "restore":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		n, err := uc.Restore(ctx, fs.Arg(0))
+		if err != nil {
+			fatal("%v", err)
+		}
+		fmt.Printf("restored %d audit entries from %s\n", n, fs.Arg(0))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "audit-retention: "+format+"\n", args...)
+	os.Exit(1)
+}
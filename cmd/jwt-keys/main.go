@@ -0,0 +1,126 @@
+// Command jwt-keys manages the rotating access token keyring configured in
+// jwt.accessKeysDir. A zero-downtime rotation stages a key first so services
+// caching /.well-known/jwks.json learn it before tokens are signed with it:
+//
+//	go run ./cmd/jwt-keys stage
+//	# wait for JWKS caches (5m) and the portal's keyReloadInterval
+//	go run ./cmd/jwt-keys activate <kid>
+//
+// "rotate" does both at once. Retired keys stay published until tokens
+// signed with them have expired and are pruned on the next stage, rotate or
+// activate.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"system-portal/internal/shared/config"
+	"system-portal/pkg/jwt"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("failed to load config: %v", err)
+	}
+	fs := flag.NewFlagSet("jwt-keys", flag.ExitOnError)
+	dir := fs.String("dir", cfg.JWT.AccessKeysDir, "keyring directory")
+	retain := fs.Duration("retain", cfg.JWT.AccessTokenExpireDuration+cfg.JWT.KeyReloadInterval,
+		"how long retired keys stay published (must exceed the access token lifetime)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jwt-keys [-dir path] [-retain duration] list|stage|activate <kid>|rotate")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	// Flags may also follow the command, as in "activate -dir path <kid>"
+	cmd := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	if *dir == "" {
+		fatal("no keyring directory, set jwt.accessKeysDir or pass -dir")
+	}
+
+	ring, err := jwt.OpenKeyring(*dir)
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	switch cmd {
+	case "list":
+		list(ring)
+		return
+	case "stage":
+		kid, err := ring.Generate()
+		if err != nil {
+			fatal("%v", err)
+		}
+		// The first key of a new keyring has to sign right away
+		if ring.Active == "" {
+			if err := ring.Activate(kid); err != nil {
+				fatal("%v", err)
+			}
+		}
+		fmt.Println("staged", kid)
+	case "activate":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err := ring.Activate(fs.Arg(0)); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println("activated", fs.Arg(0))
+	case "rotate":
+		kid, err := ring.Generate()
+		if err != nil {
+			fatal("%v", err)
+		}
+		if err := ring.Activate(kid); err != nil {
+			fatal("%v", err)
+		}
+		fmt.Println("activated", kid)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	pruned, err := ring.Prune(*retain)
+	if err != nil {
+		fatal("failed to prune keys: %v", err)
+	}
+	for _, kid := range pruned {
+		fmt.Println("pruned", kid)
+	}
+	if err := ring.Save(); err != nil {
+		fatal("%v", err)
+	}
+	list(ring)
+}
+
+func list(ring *jwt.Keyring) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tSTATE\tCREATED\tRETIRED")
+	for _, k := range ring.Keys {
+		state, retired := "staged", ""
+		switch {
+		case k.KID == ring.Active:
+			state = "active"
+		case k.RetiredAt != nil:
+			state, retired = "retired", k.RetiredAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.KID, state, k.CreatedAt.Format(time.RFC3339), retired)
+	}
+	w.Flush()
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "jwt-keys: "+format+"\n", args...)
+	os.Exit(1)
+}
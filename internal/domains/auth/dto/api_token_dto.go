@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// CreateAPITokenRequest describes a new personal API token. Permissions are
+// "resource.action" names, e.g. "openvpn.view_users". When ExpiresAt is
+// omitted the configured maximum lifetime applies.
+type CreateAPITokenRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Permissions []string   `json:"permissions" binding:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+// APITokenResponse describes a token without its secret.
+type APITokenResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreateAPITokenResponse includes the token secret, which is only shown once.
+type CreateAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
+
+// NewAPITokenResponse converts a token entity.
+func NewAPITokenResponse(t *entities.APIToken) APITokenResponse {
+	perms := t.Permissions
+	if perms == nil {
+		perms = []string{}
+	}
+	return APITokenResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Prefix:      t.Prefix,
+		Permissions: perms,
+		ExpiresAt:   t.ExpiresAt,
+		LastUsedAt:  t.LastUsedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+}
@@ -6,10 +6,12 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// TokenResponse represents returned JWT tokens.
+// TokenResponse represents returned JWT tokens. RecoveryCodes is only set
+// when the login also completed a required MFA enrollment.
 type TokenResponse struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	AccessToken   string   `json:"accessToken"`
+	RefreshToken  string   `json:"refreshToken"`
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
 }
 
 // RefreshRequest contains a refresh token for token renewal.
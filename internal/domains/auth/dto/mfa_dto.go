@@ -0,0 +1,45 @@
+package dto
+
+// MFAChallengeResponse is returned by /auth/login when a second factor is
+// needed. EnrollmentRequired means the user must enroll before logging in.
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfaRequired"`
+	MFAToken           string `json:"mfaToken"`
+	EnrollmentRequired bool   `json:"enrollmentRequired"`
+}
+
+// MFALoginRequest completes a login with a TOTP or recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFATokenRequest identifies a pending login challenge.
+type MFATokenRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+}
+
+// MFACodeRequest carries a TOTP or recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAEnrollmentResponse contains the secret to add to an authenticator app.
+// ProvisioningURI is the otpauth:// URI to render as a QR code.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// MFAStatusResponse describes the current user's MFA state.
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes; they are only
+// shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "github.com/google/uuid"
+
+// ProfileGroup describes a group of the logged-in user.
+type ProfileGroup struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+}
+
+// ProfileResponse is the logged-in user's own profile. Group is the primary
+// group and Groups every group the user belongs to. Permissions lists the
+// effective "resource.action" names across those groups; for API tokens this
+// is the token's scope.
+type ProfileResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	Username    string         `json:"username"`
+	Email       string         `json:"email"`
+	FullName    string         `json:"fullName"`
+	AuthSource  string         `json:"authSource"`
+	Group       *ProfileGroup  `json:"group,omitempty"`
+	Groups      []ProfileGroup `json:"groups"`
+	Permissions []string       `json:"permissions"`
+}
+
+// ChangePasswordRequest changes the logged-in user's own password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+// ChangePasswordResponse reports how many other sessions were signed out.
+type ChangePasswordResponse struct {
+	RevokedSessions int `json:"revokedSessions"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// SessionResponse describes a login session without its token hashes.
+type SessionResponse struct {
+	ID           uuid.UUID `json:"id"`
+	IPAddress    string    `json:"ipAddress"`
+	UserAgent    string    `json:"userAgent"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActivity time.Time `json:"lastActivity"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	Current      bool      `json:"current"`
+}
+
+// NewSessionResponses converts sessions, flagging the one matching currentID.
+func NewSessionResponses(sessions []*entities.Session, currentID uuid.UUID) []SessionResponse {
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, SessionResponse{
+			ID:           s.ID,
+			IPAddress:    s.IPAddress,
+			UserAgent:    s.UserAgent,
+			CreatedAt:    s.CreatedAt,
+			LastActivity: s.LastActivity,
+			ExpiresAt:    s.RefreshExpiresAt,
+			Current:      currentID != uuid.Nil && s.ID == currentID,
+		})
+	}
+	return resp
+}
+
+// RevokeSessionsResponse reports how many sessions were revoked.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
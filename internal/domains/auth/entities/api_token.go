@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIToken is a long-lived personal access token used by automation. Only
+// the hash of the secret is stored. Permissions holds the "resource.action"
+// pairs the token is limited to.
+//
+// Username, Group, Groups and UserActive describe the owner and are only
+// filled in when the token is looked up by hash. Group is the owner's primary
+// group and Groups every group they belong to.
+type APIToken struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Name        string
+	TokenHash   string
+	Prefix      string
+	Permissions []string
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+
+	Username   string
+	Group      string
+	Groups     []string
+	UserActive bool
+}
+
+// Valid reports whether the token can still be used at t.
+func (t *APIToken) Valid(at time.Time) bool {
+	if t.RevokedAt != nil || !t.UserActive {
+		return false
+	}
+	return t.ExpiresAt == nil || at.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// Login attempt scopes.
+const (
+	LoginScopeUser = "user"
+	LoginScopeIP   = "ip"
+)
+
+// LoginAttempt counts recent failed logins for a username or source IP.
+type LoginAttempt struct {
+	Scope         string
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	NextAttemptAt *time.Time
+	LockedUntil   *time.Time
+}
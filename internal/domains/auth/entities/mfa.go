@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAEnrollment holds a user's TOTP secret. Enabled is false until the user
+// has confirmed the secret with a valid code.
+type MFAEnrollment struct {
+	UserID    uuid.UUID
+	Secret    string
+	Enabled   bool
+	LastStep  int64
+	EnabledAt *time.Time
+	CreatedAt time.Time
+}
+
+// MFAChallenge is issued after a successful password check and must be
+// completed with a second factor before tokens are issued.
+type MFAChallenge struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCLoginState tracks an authorization request sent to the OpenID provider
+// until its callback arrives. Only the hash of the state parameter is stored.
+type OIDCLoginState struct {
+	ID           uuid.UUID
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
@@ -7,14 +7,25 @@ import (
 )
 
 // Session represents a user login session.
+//
+// Every refresh creates a new session in the same family and marks the
+// previous one as rotated; RotatedAt is nil for the current session.
 type Session struct {
 	ID               uuid.UUID
 	UserID           uuid.UUID
+	FamilyID         uuid.UUID
 	TokenHash        string
 	RefreshTokenHash string
 	ExpiresAt        time.Time
 	RefreshExpiresAt time.Time
 	IsActive         bool
+	RotatedAt        *time.Time
 	IPAddress        string
+	UserAgent        string
+	LastActivity     time.Time
 	CreatedAt        time.Time
+	// GroupsVersion is the owner's current membership version when the
+	// session is looked up, and the version the tokens were issued for when
+	// it is created
+	GroupsVersion int
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+
+	"system-portal/internal/domains/auth/dto"
+	"system-portal/internal/domains/auth/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APITokenHandler lets the logged-in user manage their personal API tokens.
+type APITokenHandler struct {
+	usecase usecases.APITokenUsecase
+}
+
+// NewAPITokenHandler creates a new handler instance.
+func NewAPITokenHandler(u usecases.APITokenUsecase) *APITokenHandler {
+	return &APITokenHandler{usecase: u}
+}
+
+// ListTokens godoc
+// @Summary List my API tokens
+// @Description List active personal API tokens of the current user
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]dto.APITokenResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	tokens, err := h.usecase.List(c.Request.Context(), userID)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to list api tokens")
+		http.RespondWithInternalError(c, "failed to list api tokens")
+		return
+	}
+	resp := make([]dto.APITokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		resp = append(resp, dto.NewAPITokenResponse(t))
+	}
+	http.RespondWithSuccess(c, 200, resp)
+}
+
+// CreateToken godoc
+// @Summary Create an API token
+// @Description Create a personal API token limited to a subset of the user's permissions. The token is only returned once.
+// @Tags Authentication
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPITokenRequest true "Token name, permissions and expiry"
+// @Success 201 {object} response.SuccessResponse{data=dto.CreateAPITokenResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /auth/tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	var req dto.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	t, raw, err := h.usecase.Create(c.Request.Context(), userID, usecases.CreateAPITokenInput{
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		ExpiresAt:   req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrAPITokenScopeMissing),
+			errors.Is(err, usecases.ErrUnknownPermission),
+			errors.Is(err, usecases.ErrInvalidTokenExpiry):
+			http.RespondWithBadRequest(c, err.Error())
+		case errors.Is(err, usecases.ErrPermissionNotGranted):
+			http.RespondWithForbidden(c, err.Error())
+		default:
+			logger.Log.WithError(err).Error("failed to create api token")
+			http.RespondWithInternalError(c, "failed to create api token")
+		}
+		return
+	}
+	http.RespondWithSuccess(c, 201, dto.CreateAPITokenResponse{APITokenResponse: dto.NewAPITokenResponse(t), Token: raw})
+}
+
+// RevokeToken godoc
+// @Summary Revoke an API token
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /auth/tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	if err := h.usecase.Revoke(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, usecases.ErrAPITokenNotFound) {
+			http.RespondWithNotFound(c, "api token not found")
+			return
+		}
+		logger.Log.WithError(err).Error("failed to revoke api token")
+		http.RespondWithInternalError(c, "failed to revoke api token")
+		return
+	}
+	http.RespondWithMessage(c, 200, "api token revoked")
+}
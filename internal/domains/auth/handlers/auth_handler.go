@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"system-portal/internal/domains/auth/dto"
 	"system-portal/internal/domains/auth/usecases"
 	http "system-portal/internal/shared/response"
@@ -20,7 +22,7 @@ func NewAuthHandler(u usecases.AuthUsecase) *AuthHandler { return &AuthHandler{u
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate a user and issue JWT tokens
+// @Description Authenticate a user and issue JWT tokens, or an MFA challenge (dto.MFAChallengeResponse) when a second factor is required
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -28,6 +30,7 @@ func NewAuthHandler(u usecases.AuthUsecase) *AuthHandler { return &AuthHandler{u
 // @Success 200 {object} dto.TokenResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -36,18 +39,99 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		http.RespondWithBadRequest(c, "invalid request")
 		return
 	}
-	access, refresh, userID, role, err := h.usecase.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	res, err := h.usecase.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
+		c.Set("username", req.Username)
+		var blocked *usecases.LoginBlockedError
+		if errors.As(err, &blocked) {
+			http.RespondWithTooManyRequests(c, blocked.Error(), blocked.RetryAfter)
+			return
+		}
 		logger.Log.WithError(err).WithField("username", req.Username).Error("login failed")
 		http.RespondWithUnauthorized(c, "login failed")
 		return
 	}
-	logger.Log.WithField("username", req.Username).Info("user logged in")
 	c.Set("username", req.Username)
-	c.Set("userID", userID)
-	c.Set("role", role)
+	c.Set("userID", res.UserID)
+	c.Set("role", res.Role)
 	c.Set("ip", c.ClientIP())
-	http.RespondWithSuccess(c, 200, dto.TokenResponse{AccessToken: access, RefreshToken: refresh})
+	if res.MFAToken != "" {
+		logger.Log.WithField("username", req.Username).Info("second factor required")
+		http.RespondWithSuccess(c, 200, dto.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           res.MFAToken,
+			EnrollmentRequired: res.MFAEnrollmentRequired,
+		})
+		return
+	}
+	logger.Log.WithField("username", req.Username).Info("user logged in")
+	http.RespondWithSuccess(c, 200, dto.TokenResponse{AccessToken: res.AccessToken, RefreshToken: res.RefreshToken})
+}
+
+// LoginMFA godoc
+// @Summary Complete login with second factor
+// @Description Exchange the MFA challenge token and a TOTP or recovery code for JWT tokens
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MFALoginRequest true "Challenge token and code"
+// @Success 200 {object} dto.TokenResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /auth/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.WithError(err).Error("failed to bind mfa login request")
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	res, err := h.usecase.CompleteMFALogin(c.Request.Context(), req.MFAToken, req.Code, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		var blocked *usecases.LoginBlockedError
+		if errors.As(err, &blocked) {
+			http.RespondWithTooManyRequests(c, blocked.Error(), blocked.RetryAfter)
+			return
+		}
+		logger.Log.WithError(err).Warn("mfa login failed")
+		http.RespondWithUnauthorized(c, "login failed")
+		return
+	}
+	c.Set("userID", res.UserID)
+	c.Set("role", res.Role)
+	logger.Log.WithField("userID", res.UserID).Info("user logged in with mfa")
+	http.RespondWithSuccess(c, 200, dto.TokenResponse{
+		AccessToken:   res.AccessToken,
+		RefreshToken:  res.RefreshToken,
+		RecoveryCodes: res.RecoveryCodes,
+	})
+}
+
+// LoginMFAEnroll godoc
+// @Summary Enroll MFA during login
+// @Description Start TOTP enrollment for a user whose group requires MFA, using the login challenge token
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MFATokenRequest true "Challenge token"
+// @Success 200 {object} response.SuccessResponse{data=dto.MFAEnrollmentResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/login/mfa/enroll [post]
+func (h *AuthHandler) LoginMFAEnroll(c *gin.Context) {
+	var req dto.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	e, err := h.usecase.BeginMFALoginEnrollment(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		logger.Log.WithError(err).Warn("mfa login enrollment failed")
+		http.RespondWithUnauthorized(c, "enrollment failed")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.MFAEnrollmentResponse{Secret: e.Secret, ProvisioningURI: e.ProvisioningURI})
 }
 
 // RefreshToken godoc
@@ -68,7 +152,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		http.RespondWithBadRequest(c, "invalid request")
 		return
 	}
-	access, refresh, err := h.usecase.Refresh(c.Request.Context(), req.RefreshToken)
+	access, refresh, err := h.usecase.Refresh(c.Request.Context(), req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		logger.Log.WithError(err).Error("refresh token failed")
 		http.RespondWithUnauthorized(c, "refresh failed")
@@ -95,6 +179,11 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 		http.RespondWithBadRequest(c, "missing token")
 		return
 	}
+	// API tokens were already checked by the auth middleware
+	if _, ok := c.Get("apiTokenID"); ok {
+		http.RespondWithMessage(c, 200, "token valid")
+		return
+	}
 	if err := h.usecase.Validate(c.Request.Context(), token[7:]); err != nil {
 		logger.Log.WithError(err).Warn("invalid token")
 		http.RespondWithUnauthorized(c, "invalid token")
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"system-portal/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with so
+// other services can verify portal tokens.
+type JWKSHandler struct {
+	jwt *jwt.RSAService
+}
+
+// NewJWKSHandler creates a new handler instance.
+func NewJWKSHandler(j *jwt.RSAService) *JWKSHandler { return &JWKSHandler{jwt: j} }
+
+// JWKS godoc
+// @Summary Access token verification keys
+// @Description JSON Web Key Set with the active and recently rotated access token keys. Tokens name their key in the kid header.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} jwt.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	// Verifiers may cache the set; rotations stage keys well before use
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, h.jwt.JWKS())
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+
+	"system-portal/internal/domains/auth/dto"
+	"system-portal/internal/domains/auth/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler lets the logged-in user manage their TOTP second factor.
+type MFAHandler struct {
+	usecase usecases.MFAUsecase
+}
+
+// NewMFAHandler creates a new handler instance.
+func NewMFAHandler(u usecases.MFAUsecase) *MFAHandler { return &MFAHandler{usecase: u} }
+
+// GetStatus godoc
+// @Summary MFA status
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=dto.MFAStatusResponse}
+// @Router /auth/mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	st, err := h.usecase.Status(c.Request.Context(), userID)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to get mfa status")
+		http.RespondWithInternalError(c, "failed to get mfa status")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.MFAStatusResponse{
+		Enabled:                st.Enabled,
+		Required:               st.Required,
+		RecoveryCodesRemaining: st.RecoveryCodesRemaining,
+	})
+}
+
+// BeginEnrollment godoc
+// @Summary Start MFA enrollment
+// @Description Generate a TOTP secret and provisioning URI; MFA is enabled once confirmed
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=dto.MFAEnrollmentResponse}
+// @Failure 409 {object} response.ErrorResponse
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	e, err := h.usecase.BeginEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.MFAEnrollmentResponse{Secret: e.Secret, ProvisioningURI: e.ProvisioningURI})
+}
+
+// ConfirmEnrollment godoc
+// @Summary Confirm MFA enrollment
+// @Description Verify a TOTP code for the pending secret, enable MFA and return recovery codes
+// @Tags Authentication
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.SuccessResponse{data=dto.RecoveryCodesResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /auth/mfa/enroll/verify [post]
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	codes, err := h.usecase.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate MFA recovery codes
+// @Description Replace all recovery codes; requires a current TOTP or recovery code
+// @Tags Authentication
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.SuccessResponse{data=dto.RecoveryCodesResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	codes, err := h.usecase.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable MFA
+// @Description Remove the TOTP second factor; not allowed when the user's group requires MFA
+// @Tags Authentication
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	if err := h.usecase.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+	http.RespondWithMessage(c, 200, "mfa disabled")
+}
+
+func (h *MFAHandler) respondWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidMFACode), errors.Is(err, usecases.ErrMFANotEnrolled):
+		http.RespondWithBadRequest(c, err.Error())
+	case errors.Is(err, usecases.ErrMFAAlreadyEnabled):
+		http.RespondWithConflict(c, err.Error())
+	case errors.Is(err, usecases.ErrMFARequiredByGroup):
+		http.RespondWithForbidden(c, err.Error())
+	default:
+		logger.Log.WithError(err).Error("mfa operation failed")
+		http.RespondWithInternalError(c, "mfa operation failed")
+	}
+}
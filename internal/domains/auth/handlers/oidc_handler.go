@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+
+	"system-portal/internal/domains/auth/dto"
+	"system-portal/internal/domains/auth/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie binds a login to the browser that started it, so a
+// callback URL from someone else's login is refused.
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/auth/oidc"
+)
+
+// OIDCHandler exposes OpenID Connect single sign-on.
+type OIDCHandler struct {
+	usecase usecases.OIDCUsecase
+	// postLoginRedirect receives the tokens in the URL fragment; when empty
+	// the callback responds with JSON
+	postLoginRedirect string
+}
+
+// NewOIDCHandler creates a new handler instance.
+func NewOIDCHandler(u usecases.OIDCUsecase, postLoginRedirect string) *OIDCHandler {
+	return &OIDCHandler{usecase: u, postLoginRedirect: postLoginRedirect}
+}
+
+// Login godoc
+// @Summary Start single sign-on
+// @Description Redirect the browser to the OpenID provider (authorization code flow with PKCE). The state is also set in an HttpOnly cookie the callback checks
+// @Tags Authentication
+// @Success 302
+// @Failure 404 {object} response.ErrorResponse
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, state, err := h.usecase.BeginLogin(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, usecases.ErrOIDCDisabled) {
+			http.RespondWithNotFound(c, err.Error())
+			return
+		}
+		logger.Log.WithError(err).Error("failed to start oidc login")
+		http.RespondWithInternalError(c, "identity provider unavailable")
+		return
+	}
+	// Lax still sends the cookie on the IdP's top-level redirect back
+	c.SetSameSite(nethttp.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, 0, oidcStateCookiePath, "", true, true)
+	c.Redirect(nethttp.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Complete single sign-on
+// @Description Redirect target of the OpenID provider. Issues portal tokens, either as JSON or in the fragment of the configured post-login redirect URL. When the user's groups require MFA and the IdP is not trusted for it, an MFA challenge token is returned instead, to be completed with /auth/login/mfa
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} dto.TokenResponse
+// @Success 200 {object} dto.MFAChallengeResponse
+// @Success 302
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	bound, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(nethttp.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", true, true)
+	if idpErr := c.Query("error"); idpErr != "" {
+		logger.Log.WithFields(map[string]interface{}{
+			"error":       idpErr,
+			"description": c.Query("error_description"),
+		}).Warn("identity provider returned an error")
+		h.fail(c, nethttp.StatusUnauthorized, idpErr)
+		return
+	}
+	state := c.Query("state")
+	if bound == "" || subtle.ConstantTimeCompare([]byte(bound), []byte(state)) != 1 {
+		logger.Log.Warn("oidc callback state does not match this browser")
+		h.fail(c, nethttp.StatusUnauthorized, "login failed")
+		return
+	}
+	res, err := h.usecase.CompleteLogin(c.Request.Context(), c.Query("code"), state, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		var blocked *usecases.LoginBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			if h.postLoginRedirect == "" {
+				http.RespondWithTooManyRequests(c, blocked.Error(), blocked.RetryAfter)
+				return
+			}
+			h.fail(c, nethttp.StatusTooManyRequests, blocked.Error())
+		case errors.Is(err, usecases.ErrOIDCDisabled):
+			h.fail(c, nethttp.StatusNotFound, err.Error())
+		case errors.Is(err, usecases.ErrOIDCAccessDenied):
+			h.fail(c, nethttp.StatusForbidden, err.Error())
+		default:
+			logger.Log.WithError(err).Warn("oidc login failed")
+			h.fail(c, nethttp.StatusUnauthorized, "login failed")
+		}
+		return
+	}
+	c.Set("userID", res.UserID)
+	c.Set("role", res.Role)
+	// The second factor is completed with /auth/login/mfa as after a password
+	if res.MFAToken != "" {
+		if h.postLoginRedirect == "" {
+			http.RespondWithSuccess(c, 200, dto.MFAChallengeResponse{
+				MFARequired:        true,
+				MFAToken:           res.MFAToken,
+				EnrollmentRequired: res.MFAEnrollmentRequired,
+			})
+			return
+		}
+		fragment := url.Values{}
+		fragment.Set("mfaToken", res.MFAToken)
+		fragment.Set("enrollmentRequired", strconv.FormatBool(res.MFAEnrollmentRequired))
+		c.Redirect(nethttp.StatusFound, h.postLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+	if h.postLoginRedirect == "" {
+		http.RespondWithSuccess(c, 200, dto.TokenResponse{AccessToken: res.AccessToken, RefreshToken: res.RefreshToken})
+		return
+	}
+	// the fragment is never sent to servers, keeping tokens out of access logs
+	fragment := url.Values{}
+	fragment.Set("accessToken", res.AccessToken)
+	fragment.Set("refreshToken", res.RefreshToken)
+	c.Redirect(nethttp.StatusFound, h.postLoginRedirect+"#"+fragment.Encode())
+}
+
+func (h *OIDCHandler) fail(c *gin.Context, status int, message string) {
+	if h.postLoginRedirect != "" {
+		fragment := url.Values{}
+		fragment.Set("error", message)
+		c.Redirect(nethttp.StatusFound, h.postLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+	switch status {
+	case nethttp.StatusNotFound:
+		http.RespondWithNotFound(c, message)
+	case nethttp.StatusForbidden:
+		http.RespondWithForbidden(c, message)
+	default:
+		http.RespondWithUnauthorized(c, message)
+	}
+}
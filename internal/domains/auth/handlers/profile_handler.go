@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+
+	"system-portal/internal/domains/auth/dto"
+	"system-portal/internal/domains/auth/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ProfileHandler serves the logged-in user's own profile.
+type ProfileHandler struct {
+	usecase usecases.ProfileUsecase
+}
+
+// NewProfileHandler creates a new handler instance.
+func NewProfileHandler(u usecases.ProfileUsecase) *ProfileHandler { return &ProfileHandler{usecase: u} }
+
+// GetProfile godoc
+// @Summary My profile
+// @Description Profile, group and effective permissions of the current user
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=dto.ProfileResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/me [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	// API tokens may read the profile, so the session is not required here
+	userID, ok := c.Get("userID")
+	id, _ := userID.(uuid.UUID)
+	if !ok || id == uuid.Nil {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	p, err := h.usecase.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, usecases.ErrProfileNotFound) {
+			http.RespondWithUnauthorized(c, "user not found")
+			return
+		}
+		logger.Log.WithError(err).Error("failed to load profile")
+		http.RespondWithInternalError(c, "failed to load profile")
+		return
+	}
+	resp := newProfileResponse(p)
+	if scope, ok := c.Get("apiTokenPermissions"); ok {
+		resp.Permissions, _ = scope.([]string)
+	}
+	http.RespondWithSuccess(c, 200, resp)
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Change the current user's password. Requires the current password, enforces the password policy and signs out all other sessions.
+// @Tags Authentication
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.SuccessResponse{data=dto.ChangePasswordResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
+// @Router /auth/me/password [put]
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userID, sessionID, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	revoked, err := h.usecase.ChangePassword(c.Request.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword, c.ClientIP())
+	if err != nil {
+		var blocked *usecases.LoginBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			http.RespondWithTooManyRequests(c, blocked.Error(), blocked.RetryAfter)
+		case errors.Is(err, usecases.ErrWrongPassword),
+			errors.Is(err, usecases.ErrPasswordUnchanged),
+			errors.Is(err, usecases.ErrPasswordTooWeak):
+			http.RespondWithBadRequest(c, err.Error())
+		case errors.Is(err, usecases.ErrExternalPassword):
+			http.RespondWithForbidden(c, err.Error())
+		default:
+			logger.Log.WithError(err).Error("failed to change password")
+			http.RespondWithInternalError(c, "failed to change password")
+		}
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.ChangePasswordResponse{RevokedSessions: revoked})
+}
+
+func newProfileResponse(p *usecases.Profile) dto.ProfileResponse {
+	u := p.User
+	resp := dto.ProfileResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		FullName:    u.FullName,
+		AuthSource:  u.AuthSource,
+		Groups:      make([]dto.ProfileGroup, 0, len(p.Groups)),
+		Permissions: p.Permissions,
+	}
+	if g := p.Group; g != nil {
+		resp.Group = &dto.ProfileGroup{ID: g.ID, Name: g.Name, DisplayName: g.DisplayName}
+	}
+	for _, g := range p.Groups {
+		resp.Groups = append(resp.Groups, dto.ProfileGroup{ID: g.ID, Name: g.Name, DisplayName: g.DisplayName})
+	}
+	return resp
+}
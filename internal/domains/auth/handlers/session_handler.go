@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+
+	"system-portal/internal/domains/auth/dto"
+	"system-portal/internal/domains/auth/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SessionHandler lets the logged-in user manage their own sessions.
+type SessionHandler struct {
+	usecase usecases.SessionUsecase
+}
+
+// NewSessionHandler creates a new handler instance.
+func NewSessionHandler(u usecases.SessionUsecase) *SessionHandler { return &SessionHandler{usecase: u} }
+
+// ListSessions godoc
+// @Summary List my sessions
+// @Description List active sessions of the current user
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]dto.SessionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, sessionID, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	sessions, err := h.usecase.ListUserSessions(c.Request.Context(), userID)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to list sessions")
+		http.RespondWithInternalError(c, "failed to list sessions")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.NewSessionResponses(sessions, sessionID))
+}
+
+// RevokeSession godoc
+// @Summary Revoke one of my sessions
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	if err := h.usecase.RevokeUserSession(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, usecases.ErrSessionNotFound) {
+			http.RespondWithNotFound(c, "session not found")
+			return
+		}
+		logger.Log.WithError(err).Error("failed to revoke session")
+		http.RespondWithInternalError(c, "failed to revoke session")
+		return
+	}
+	http.RespondWithMessage(c, 200, "session revoked")
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke all my other sessions
+// @Description Revoke every session of the current user except the one making the request
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=dto.RevokeSessionsResponse}
+// @Router /auth/sessions [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, sessionID, ok := currentSession(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	n, err := h.usecase.RevokeUserSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to revoke sessions")
+		http.RespondWithInternalError(c, "failed to revoke sessions")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.RevokeSessionsResponse{Revoked: n})
+}
+
+// currentSession returns the user and session resolved by the auth middleware.
+// Requests authenticated with an API token have no session, so they cannot
+// manage sessions, MFA or other tokens.
+func currentSession(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	if _, ok := c.Get("apiTokenID"); ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := c.Get("userID")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	uid, ok := userID.(uuid.UUID)
+	if !ok || uid == uuid.Nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	var sid uuid.UUID
+	if v, ok := c.Get("sessionID"); ok {
+		sid, _ = v.(uuid.UUID)
+	}
+	return uid, sid, true
+}
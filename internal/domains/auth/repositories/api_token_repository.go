@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// APITokenRepository stores personal API tokens and their permission scopes.
+type APITokenRepository interface {
+	// Create stores the token together with the given permission IDs.
+	Create(ctx context.Context, t *entities.APIToken, permissionIDs []uuid.UUID) error
+	GetByTokenHash(ctx context.Context, hash string) (*entities.APIToken, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.APIToken, error)
+	// Revoke revokes a token owned by userID and reports whether it existed.
+	Revoke(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
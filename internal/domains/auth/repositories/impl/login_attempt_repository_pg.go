@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+)
+
+type pgLoginAttemptRepo struct{ db *sql.DB }
+
+func NewLoginAttemptRepositoryPG(db *sql.DB) repositories.LoginAttemptRepository {
+	return &pgLoginAttemptRepo{db: db}
+}
+
+const loginAttemptColumns = `scope, key, failures, last_failure_at, next_attempt_at, locked_until`
+
+func (r *pgLoginAttemptRepo) Get(ctx context.Context, scope, key string) (*entities.LoginAttempt, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+loginAttemptColumns+` FROM login_attempts WHERE scope=$1 AND key=$2`, scope, key)
+	a, err := scanLoginAttempt(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+func (r *pgLoginAttemptRepo) RecordFailure(ctx context.Context, scope, key string, at, windowStart time.Time) (*entities.LoginAttempt, error) {
+	row := r.db.QueryRowContext(ctx,
+		`INSERT INTO login_attempts (scope, key, failures, last_failure_at) VALUES ($1,$2,1,$3)
+         ON CONFLICT (scope, key) DO UPDATE SET
+             failures = CASE WHEN login_attempts.last_failure_at < $4 THEN 1 ELSE login_attempts.failures + 1 END,
+             last_failure_at = EXCLUDED.last_failure_at
+         RETURNING `+loginAttemptColumns,
+		scope, key, at, windowStart)
+	return scanLoginAttempt(row)
+}
+
+func (r *pgLoginAttemptRepo) Block(ctx context.Context, scope, key string, nextAttemptAt time.Time, lockedUntil *time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE login_attempts SET next_attempt_at=$3, locked_until=COALESCE($4, locked_until) WHERE scope=$1 AND key=$2`,
+		scope, key, nextAttemptAt, lockedUntil)
+	return err
+}
+
+func (r *pgLoginAttemptRepo) Clear(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE scope=$1 AND key=$2`, scope, key)
+	return err
+}
+
+func (r *pgLoginAttemptRepo) DeleteStale(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`, before)
+	return err
+}
+
+func scanLoginAttempt(row rowScanner) (*entities.LoginAttempt, error) {
+	var a entities.LoginAttempt
+	var next, locked sql.NullTime
+	if err := row.Scan(&a.Scope, &a.Key, &a.Failures, &a.LastFailureAt, &next, &locked); err != nil {
+		return nil, err
+	}
+	if next.Valid {
+		a.NextAttemptAt = &next.Time
+	}
+	if locked.Valid {
+		a.LockedUntil = &locked.Time
+	}
+	return &a, nil
+}
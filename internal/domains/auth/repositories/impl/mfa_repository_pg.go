@@ -0,0 +1,176 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
+)
+
+type pgMFARepo struct {
+	db  *sql.DB
+	key string
+}
+
+// NewMFARepositoryPG stores TOTP secrets encrypted with key.
+func NewMFARepositoryPG(db *sql.DB, key string) repositories.MFARepository {
+	return &pgMFARepo{db: db, key: key}
+}
+
+func (r *pgMFARepo) GetEnrollment(ctx context.Context, userID uuid.UUID) (*entities.MFAEnrollment, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT user_id, secret, COALESCE(enabled, false), COALESCE(last_step, 0), enabled_at, created_at
+         FROM user_mfa WHERE user_id=$1`, userID)
+	var e entities.MFAEnrollment
+	var encSecret string
+	var enabledAt sql.NullTime
+	err := row.Scan(&e.UserID, &encSecret, &e.Enabled, &e.LastStep, &enabledAt, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("get mfa enrollment failed")
+		return nil, err
+	}
+	secret, err := utils.DecryptString(encSecret, r.key)
+	if err != nil {
+		logger.Log.WithError(err).Error("decrypt mfa secret failed")
+		return nil, err
+	}
+	e.Secret = secret
+	if enabledAt.Valid {
+		e.EnabledAt = &enabledAt.Time
+	}
+	return &e, nil
+}
+
+func (r *pgMFARepo) SaveEnrollment(ctx context.Context, e *entities.MFAEnrollment) error {
+	secret, err := utils.EncryptString(e.Secret, r.key)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO user_mfa (user_id, secret, enabled, last_step, enabled_at, created_at)
+         VALUES ($1,$2,$3,$4,$5,$6)
+         ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, enabled=EXCLUDED.enabled,
+             last_step=EXCLUDED.last_step, enabled_at=EXCLUDED.enabled_at`,
+		e.UserID, secret, e.Enabled, e.LastStep, e.EnabledAt, e.CreatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("save mfa enrollment failed")
+	}
+	return err
+}
+
+func (r *pgMFARepo) DeleteEnrollment(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id=$1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *pgMFARepo) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_mfa SET last_step=$2 WHERE user_id=$1 AND COALESCE(last_step, 0) < $2`, userID, step)
+	if err != nil {
+		logger.Log.WithError(err).Error("consume mfa step failed")
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+func (r *pgMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1,$2,$3,$4)`,
+			uuid.New(), userID, h, time.Now()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *pgMFARepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE user_recovery_codes SET used_at=NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`, userID, hash)
+	if err != nil {
+		logger.Log.WithError(err).Error("use recovery code failed")
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *pgMFARepo) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(1) FROM user_recovery_codes WHERE user_id=$1 AND used_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *pgMFARepo) CreateChallenge(ctx context.Context, c *entities.MFAChallenge) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO mfa_challenges (id, user_id, token_hash, attempts, expires_at, created_at)
+         VALUES ($1,$2,$3,$4,$5,$6)`,
+		c.ID, c.UserID, c.TokenHash, c.Attempts, c.ExpiresAt, c.CreatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("create mfa challenge failed")
+	}
+	return err
+}
+
+func (r *pgMFARepo) GetChallengeByTokenHash(ctx context.Context, hash string) (*entities.MFAChallenge, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, token_hash, COALESCE(attempts, 0), expires_at, created_at
+         FROM mfa_challenges WHERE token_hash=$1`, hash)
+	var c entities.MFAChallenge
+	err := row.Scan(&c.ID, &c.UserID, &c.TokenHash, &c.Attempts, &c.ExpiresAt, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("get mfa challenge failed")
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *pgMFARepo) IncrementChallengeAttempts(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE mfa_challenges SET attempts=attempts+1 WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgMFARepo) DeleteChallenge(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgMFARepo) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM mfa_challenges WHERE expires_at < $1`, before)
+	return err
+}
@@ -0,0 +1,42 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+)
+
+type pgOIDCStateRepo struct{ db *sql.DB }
+
+func NewOIDCStateRepositoryPG(db *sql.DB) repositories.OIDCStateRepository {
+	return &pgOIDCStateRepo{db: db}
+}
+
+func (r *pgOIDCStateRepo) Create(ctx context.Context, s *entities.OIDCLoginState) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO oidc_login_states (id, state_hash, nonce, code_verifier, expires_at, created_at) VALUES ($1,$2,$3,$4,$5,$6)`,
+		s.ID, s.StateHash, s.Nonce, s.CodeVerifier, s.ExpiresAt, s.CreatedAt)
+	return err
+}
+
+func (r *pgOIDCStateRepo) Consume(ctx context.Context, stateHash string) (*entities.OIDCLoginState, error) {
+	row := r.db.QueryRowContext(ctx,
+		`DELETE FROM oidc_login_states WHERE state_hash=$1
+         RETURNING id, state_hash, nonce, code_verifier, expires_at, created_at`, stateHash)
+	var s entities.OIDCLoginState
+	if err := row.Scan(&s.ID, &s.StateHash, &s.Nonce, &s.CodeVerifier, &s.ExpiresAt, &s.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *pgOIDCStateRepo) DeleteExpired(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < $1`, before)
+	return err
+}
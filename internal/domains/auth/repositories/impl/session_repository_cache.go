@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+)
+
+// maxCachedSessions bounds the cache before expired entries are swept.
+const maxCachedSessions = 4096
+
+type cachedSession struct {
+	session   entities.Session
+	loadedAt  time.Time
+	touchedAt time.Time
+}
+
+// cachedSessionRepo keeps recently resolved sessions in memory so the auth
+// middleware does not query Postgres on every request. Revocations made
+// through this repository evict the cached entry immediately; changes made
+// by other replicas become visible once the entry expires.
+type cachedSessionRepo struct {
+	next repositories.SessionRepository
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cachedSession
+}
+
+// NewCachedSessionRepository wraps next with a short-lived in-process cache.
+// A non-positive ttl disables caching.
+func NewCachedSessionRepository(next repositories.SessionRepository, ttl time.Duration) repositories.SessionRepository {
+	if ttl <= 0 {
+		return next
+	}
+	return &cachedSessionRepo{next: next, ttl: ttl, entries: make(map[string]*cachedSession)}
+}
+
+func (r *cachedSessionRepo) Create(ctx context.Context, s *entities.Session) error {
+	return r.next.Create(ctx, s)
+}
+
+func (r *cachedSessionRepo) GetByTokenHash(ctx context.Context, hash string) (*entities.Session, error) {
+	now := time.Now()
+	r.mu.Lock()
+	if e, ok := r.entries[hash]; ok && now.Sub(e.loadedAt) < r.ttl {
+		s := e.session
+		r.mu.Unlock()
+		return &s, nil
+	}
+	r.mu.Unlock()
+
+	s, err := r.next.GetByTokenHash(ctx, hash)
+	if err != nil || s == nil {
+		return s, err
+	}
+
+	r.mu.Lock()
+	if len(r.entries) >= maxCachedSessions {
+		r.sweepLocked(now)
+	}
+	r.entries[hash] = &cachedSession{session: *s, loadedAt: now, touchedAt: s.LastActivity}
+	r.mu.Unlock()
+	return s, nil
+}
+
+// GetByRefreshTokenHash always reads through; refresh is rare and must see
+// the latest rotation state.
+func (r *cachedSessionRepo) GetByRefreshTokenHash(ctx context.Context, hash string) (*entities.Session, error) {
+	return r.next.GetByRefreshTokenHash(ctx, hash)
+}
+
+func (r *cachedSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *cachedSessionRepo) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	return r.next.ListActiveByUser(ctx, userID)
+}
+
+func (r *cachedSessionRepo) Deactivate(ctx context.Context, id uuid.UUID) error {
+	if err := r.next.Deactivate(ctx, id); err != nil {
+		return err
+	}
+	r.evict(func(s *entities.Session) bool { return s.ID == id })
+	return nil
+}
+
+func (r *cachedSessionRepo) DeactivateFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := r.next.DeactivateFamily(ctx, familyID); err != nil {
+		return err
+	}
+	r.evict(func(s *entities.Session) bool { return s.FamilyID == familyID })
+	return nil
+}
+
+func (r *cachedSessionRepo) DeactivateByUser(ctx context.Context, userID, exceptID uuid.UUID) (int, error) {
+	n, err := r.next.DeactivateByUser(ctx, userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	r.evict(func(s *entities.Session) bool { return s.UserID == userID && s.ID != exceptID })
+	return n, nil
+}
+
+func (r *cachedSessionRepo) Rotate(ctx context.Context, oldID uuid.UUID, next *entities.Session) error {
+	if err := r.next.Rotate(ctx, oldID, next); err != nil {
+		return err
+	}
+	r.evict(func(s *entities.Session) bool { return s.ID == oldID })
+	return nil
+}
+
+// TouchActivity records activity in the cache and only writes through to the
+// database once per ttl for each session.
+func (r *cachedSessionRepo) TouchActivity(ctx context.Context, id uuid.UUID, at time.Time) error {
+	write := true
+	r.mu.Lock()
+	for _, e := range r.entries {
+		if e.session.ID != id {
+			continue
+		}
+		e.session.LastActivity = at
+		if at.Sub(e.touchedAt) < r.ttl {
+			write = false
+		} else {
+			e.touchedAt = at
+		}
+	}
+	r.mu.Unlock()
+	if !write {
+		return nil
+	}
+	return r.next.TouchActivity(ctx, id, at)
+}
+
+func (r *cachedSessionRepo) evict(match func(s *entities.Session) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, e := range r.entries {
+		if match(&e.session) {
+			delete(r.entries, hash)
+		}
+	}
+}
+
+func (r *cachedSessionRepo) sweepLocked(now time.Time) {
+	for hash, e := range r.entries {
+		if now.Sub(e.loadedAt) >= r.ttl {
+			delete(r.entries, hash)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package impl
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"system-portal/internal/domains/auth/entities"
@@ -10,18 +11,39 @@ import (
 	"system-portal/pkg/logger"
 )
 
+// sessionColumns selects a session joined with its user; is_active is false
+// when the owning user has been deactivated.
+const sessionColumns = `s.id, s.user_id, COALESCE(s.family_id, s.id), s.token_hash, COALESCE(s.refresh_token_hash, ''),
+                s.expires_at, s.refresh_expires_at, s.is_active AND COALESCE(u.is_active, false), s.rotated_at,
+                COALESCE(host(s.ip_address), ''), COALESCE(s.user_agent, ''), COALESCE(s.last_activity, s.created_at), s.created_at,
+                COALESCE(u.groups_version, 1)
+         FROM user_sessions s LEFT JOIN users u ON u.id = s.user_id`
+
 type pgSessionRepo struct{ db *sql.DB }
 
 func NewSessionRepositoryPG(db *sql.DB) repositories.SessionRepository {
 	return &pgSessionRepo{db: db}
 }
 
-func (r *pgSessionRepo) Create(ctx context.Context, s *entities.Session) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO user_sessions (id, user_id, token_hash, refresh_token_hash, expires_at, refresh_expires_at, is_active, ip_address, created_at)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
-		s.ID, s.UserID, s.TokenHash, s.RefreshTokenHash, s.ExpiresAt, s.RefreshExpiresAt, s.IsActive, s.IPAddress, s.CreatedAt,
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertSession(ctx context.Context, db execer, s *entities.Session) error {
+	if s.FamilyID == uuid.Nil {
+		s.FamilyID = s.ID
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO user_sessions (id, user_id, family_id, token_hash, refresh_token_hash, expires_at, refresh_expires_at, is_active, ip_address, user_agent, last_activity, created_at)
+         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+		s.ID, s.UserID, s.FamilyID, s.TokenHash, s.RefreshTokenHash, s.ExpiresAt, s.RefreshExpiresAt, s.IsActive,
+		nullString(s.IPAddress), nullString(s.UserAgent), s.CreatedAt, s.CreatedAt,
 	)
+	return err
+}
+
+func (r *pgSessionRepo) Create(ctx context.Context, s *entities.Session) error {
+	err := insertSession(ctx, r.db, s)
 	if err != nil {
 		logger.Log.WithError(err).Error("create session failed")
 	}
@@ -29,19 +51,49 @@ func (r *pgSessionRepo) Create(ctx context.Context, s *entities.Session) error {
 }
 
 func (r *pgSessionRepo) GetByTokenHash(ctx context.Context, hash string) (*entities.Session, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, token_hash, refresh_token_hash, expires_at, refresh_expires_at, is_active, ip_address, created_at
-         FROM user_sessions WHERE token_hash=$1`, hash)
-	var s entities.Session
-	err := row.Scan(&s.ID, &s.UserID, &s.TokenHash, &s.RefreshTokenHash, &s.ExpiresAt, &s.RefreshExpiresAt, &s.IsActive, &s.IPAddress, &s.CreatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	s, err := scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` WHERE s.token_hash=$1`, hash))
 	if err != nil {
 		logger.Log.WithError(err).Error("get session failed")
+	}
+	return s, err
+}
+
+func (r *pgSessionRepo) GetByRefreshTokenHash(ctx context.Context, hash string) (*entities.Session, error) {
+	s, err := scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` WHERE s.refresh_token_hash=$1`, hash))
+	if err != nil {
+		logger.Log.WithError(err).Error("get session by refresh token failed")
+	}
+	return s, err
+}
+
+func (r *pgSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	s, err := scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` WHERE s.id=$1`, id))
+	if err != nil {
+		logger.Log.WithError(err).Error("get session by id failed")
+	}
+	return s, err
+}
+
+// ListActiveByUser returns sessions that can still be used or refreshed,
+// most recently active first.
+func (r *pgSessionRepo) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sessionColumns+`
+         WHERE s.user_id=$1 AND s.is_active AND s.refresh_expires_at > NOW()
+         ORDER BY COALESCE(s.last_activity, s.created_at) DESC`, userID)
+	if err != nil {
+		logger.Log.WithError(err).Error("list sessions failed")
 		return nil, err
 	}
-	return &s, nil
+	defer rows.Close()
+	var sessions []*entities.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
 }
 
 func (r *pgSessionRepo) Deactivate(ctx context.Context, id uuid.UUID) error {
@@ -51,3 +103,91 @@ func (r *pgSessionRepo) Deactivate(ctx context.Context, id uuid.UUID) error {
 	}
 	return err
 }
+
+func (r *pgSessionRepo) DeactivateFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_sessions SET is_active=false WHERE family_id=$1 AND is_active`, familyID)
+	if err != nil {
+		logger.Log.WithError(err).Error("deactivate session family failed")
+	}
+	return err
+}
+
+func (r *pgSessionRepo) DeactivateByUser(ctx context.Context, userID, exceptID uuid.UUID) (int, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE user_sessions SET is_active=false WHERE user_id=$1 AND id<>$2 AND is_active`, userID, exceptID)
+	if err != nil {
+		logger.Log.WithError(err).Error("deactivate user sessions failed")
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Rotate marks the old session as rotated and stores its successor in one
+// transaction. Only one caller can rotate a given session.
+func (r *pgSessionRepo) Rotate(ctx context.Context, oldID uuid.UUID, next *entities.Session) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx,
+		`UPDATE user_sessions SET is_active=false, rotated_at=$2 WHERE id=$1 AND is_active AND rotated_at IS NULL`,
+		oldID, next.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.WithError(err).Error("rotate session failed")
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		// Only a rotated session means its refresh token was used twice
+		var rotated bool
+		err := tx.QueryRowContext(ctx, `SELECT rotated_at IS NOT NULL FROM user_sessions WHERE id=$1`, oldID).Scan(&rotated)
+		tx.Rollback()
+		if err == nil && rotated {
+			return repositories.ErrSessionRotated
+		}
+		return repositories.ErrSessionInactive
+	}
+	if err := insertSession(ctx, tx, next); err != nil {
+		tx.Rollback()
+		logger.Log.WithError(err).Error("create rotated session failed")
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *pgSessionRepo) TouchActivity(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_sessions SET last_activity=$2 WHERE id=$1 AND is_active`, id, at)
+	if err != nil {
+		logger.Log.WithError(err).Error("touch session activity failed")
+	}
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*entities.Session, error) {
+	var s entities.Session
+	var rotatedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.UserID, &s.FamilyID, &s.TokenHash, &s.RefreshTokenHash, &s.ExpiresAt, &s.RefreshExpiresAt,
+		&s.IsActive, &rotatedAt, &s.IPAddress, &s.UserAgent, &s.LastActivity, &s.CreatedAt, &s.GroupsVersion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rotatedAt.Valid {
+		s.RotatedAt = &rotatedAt.Time
+	}
+	return &s, nil
+}
+
+// nullString maps empty strings to NULL for nullable columns such as INET.
+func nullString(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"system-portal/internal/domains/auth/entities"
+)
+
+// LoginAttemptRepository tracks failed logins per username and per IP.
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, scope, key string) (*entities.LoginAttempt, error)
+	// RecordFailure increments the failure counter, restarting it when the
+	// previous failure is older than windowStart, and returns the new state.
+	RecordFailure(ctx context.Context, scope, key string, at, windowStart time.Time) (*entities.LoginAttempt, error)
+	// Block sets the earliest time of the next attempt and, when lockedUntil
+	// is not nil, a lockout.
+	Block(ctx context.Context, scope, key string, nextAttemptAt time.Time, lockedUntil *time.Time) error
+	Clear(ctx context.Context, scope, key string) error
+	DeleteStale(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// MFARepository stores TOTP enrollments, recovery codes and login challenges.
+type MFARepository interface {
+	GetEnrollment(ctx context.Context, userID uuid.UUID) (*entities.MFAEnrollment, error)
+	SaveEnrollment(ctx context.Context, e *entities.MFAEnrollment) error
+	DeleteEnrollment(ctx context.Context, userID uuid.UUID) error
+	// ConsumeStep records the last accepted time step, failing when a step
+	// at or after it was already used.
+	ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+
+	CreateChallenge(ctx context.Context, c *entities.MFAChallenge) error
+	GetChallengeByTokenHash(ctx context.Context, hash string) (*entities.MFAChallenge, error)
+	IncrementChallengeAttempts(ctx context.Context, id uuid.UUID) error
+	DeleteChallenge(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"system-portal/internal/domains/auth/entities"
+)
+
+// OIDCStateRepository stores pending OpenID Connect logins.
+type OIDCStateRepository interface {
+	Create(ctx context.Context, s *entities.OIDCLoginState) error
+	// Consume deletes and returns the state so a callback can only be
+	// redeemed once. It returns nil when no state matches.
+	Consume(ctx context.Context, stateHash string) (*entities.OIDCLoginState, error)
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
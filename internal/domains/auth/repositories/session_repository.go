@@ -2,14 +2,33 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"system-portal/internal/domains/auth/entities"
 )
 
+// ErrSessionRotated is returned by Rotate when the session was already
+// rotated by a concurrent request.
+var ErrSessionRotated = errors.New("session already rotated")
+
+// ErrSessionInactive is returned by Rotate when the session was ended
+// without being rotated, such as by a logout racing the refresh.
+var ErrSessionInactive = errors.New("session no longer active")
+
 // SessionRepository stores active sessions.
 type SessionRepository interface {
 	Create(ctx context.Context, s *entities.Session) error
 	GetByTokenHash(ctx context.Context, hash string) (*entities.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*entities.Session, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Session, error)
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error)
 	Deactivate(ctx context.Context, id uuid.UUID) error
+	DeactivateFamily(ctx context.Context, familyID uuid.UUID) error
+	// DeactivateByUser revokes all active sessions of a user except exceptID
+	// (pass uuid.Nil to revoke all) and returns how many were revoked.
+	DeactivateByUser(ctx context.Context, userID, exceptID uuid.UUID) (int, error)
+	Rotate(ctx context.Context, oldID uuid.UUID, next *entities.Session) error
+	TouchActivity(ctx context.Context, id uuid.UUID, at time.Time) error
 }
@@ -9,20 +9,41 @@ import (
 
 // Dependencies injected from main
 var (
-	authHandler *handlers.AuthHandler
+	authHandler    *handlers.AuthHandler
+	sessionHandler *handlers.SessionHandler
+	mfaHandler     *handlers.MFAHandler
+	oidcHandler    *handlers.OIDCHandler
+	tokenHandler   *handlers.APITokenHandler
+	jwksHandler    *handlers.JWKSHandler
+	profileHandler *handlers.ProfileHandler
 )
 
 // Initialize sets up the handler dependencies
-func Initialize(ah *handlers.AuthHandler) {
+func Initialize(ah *handlers.AuthHandler, sh *handlers.SessionHandler, mh *handlers.MFAHandler, oh *handlers.OIDCHandler, th *handlers.APITokenHandler, jh *handlers.JWKSHandler, ph *handlers.ProfileHandler) {
 	authHandler = ah
+	sessionHandler = sh
+	mfaHandler = mh
+	oidcHandler = oh
+	tokenHandler = th
+	jwksHandler = jh
+	profileHandler = ph
 }
 
 // RegisterPublicRoutes registers auth routes that don't require authentication
 func RegisterPublicRoutes(router *gin.Engine) {
+	// Keys other services use to verify portal access tokens
+	router.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
 	auth := router.Group("/auth")
 	{
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/mfa", authHandler.LoginMFA)
+		auth.POST("/login/mfa/enroll", authHandler.LoginMFAEnroll)
 		auth.POST("/refresh", authHandler.RefreshToken)
+
+		// Single sign-on
+		auth.GET("/oidc/login", oidcHandler.Login)
+		auth.GET("/oidc/callback", oidcHandler.Callback)
 	}
 }
 
@@ -32,5 +53,26 @@ func RegisterProtectedRoutes(router *gin.RouterGroup) {
 	{
 		auth.GET("/validate", authHandler.ValidateToken)
 		auth.POST("/logout", authHandler.Logout)
+
+		// Own profile
+		auth.GET("/me", profileHandler.GetProfile)
+		auth.PUT("/me/password", profileHandler.ChangePassword)
+
+		// Own session management
+		auth.GET("/sessions", sessionHandler.ListSessions)
+		auth.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+		auth.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+		// Second factor management
+		auth.GET("/mfa", mfaHandler.GetStatus)
+		auth.POST("/mfa/enroll", mfaHandler.BeginEnrollment)
+		auth.POST("/mfa/enroll/verify", mfaHandler.ConfirmEnrollment)
+		auth.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		auth.POST("/mfa/disable", mfaHandler.Disable)
+
+		// Personal API tokens
+		auth.GET("/tokens", tokenHandler.ListTokens)
+		auth.POST("/tokens", tokenHandler.CreateToken)
+		auth.DELETE("/tokens/:id", tokenHandler.RevokeToken)
 	}
 }
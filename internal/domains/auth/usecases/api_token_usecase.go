@@ -0,0 +1,34 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// APITokenPrefix marks personal API tokens so the auth middleware can tell
+// them apart from JWTs.
+const APITokenPrefix = "spt_"
+
+// CreateAPITokenInput describes a new token. Permissions are
+// "resource.action" names from the permissions table. A nil ExpiresAt uses
+// the maximum lifetime.
+type CreateAPITokenInput struct {
+	Name        string
+	Permissions []string
+	ExpiresAt   *time.Time
+}
+
+// APITokenUsecase manages personal API tokens and authenticates requests
+// made with them.
+type APITokenUsecase interface {
+	List(ctx context.Context, userID uuid.UUID) ([]*entities.APIToken, error)
+	// Create returns the stored token and its secret, which is only shown once.
+	Create(ctx context.Context, userID uuid.UUID, in CreateAPITokenInput) (*entities.APIToken, string, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	// Authenticate resolves a raw token, returning nil when it is unknown,
+	// revoked, expired or its owner is inactive.
+	Authenticate(ctx context.Context, raw string) (*entities.APIToken, error)
+}
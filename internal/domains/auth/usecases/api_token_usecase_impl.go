@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
+)
+
+var (
+	ErrAPITokenNotFound     = errors.New("api token not found")
+	ErrAPITokenScopeMissing = errors.New("api token needs at least one permission")
+	ErrUnknownPermission    = errors.New("unknown permission")
+	ErrPermissionNotGranted = errors.New("permission not granted to user")
+	ErrInvalidTokenExpiry   = errors.New("invalid token expiry")
+)
+
+type apiTokenUsecaseImpl struct {
+	tokens      repositories.APITokenRepository
+	users       portalrepos.UserRepository
+	groups      portalrepos.GroupRepository
+	perms       portalrepos.PermissionRepository
+	maxLifetime time.Duration
+}
+
+// NewAPITokenUsecase creates the usecase. Tokens may not outlive
+// maxLifetime; a non-positive value allows tokens that never expire.
+func NewAPITokenUsecase(tokenRepo repositories.APITokenRepository, userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, permRepo portalrepos.PermissionRepository, maxLifetime time.Duration) APITokenUsecase {
+	return &apiTokenUsecaseImpl{tokens: tokenRepo, users: userRepo, groups: groupRepo, perms: permRepo, maxLifetime: maxLifetime}
+}
+
+func (u *apiTokenUsecaseImpl) List(ctx context.Context, userID uuid.UUID) ([]*entities.APIToken, error) {
+	return u.tokens.ListByUser(ctx, userID)
+}
+
+// Create checks that every requested permission exists and is granted to
+// one of the user's groups, so a token can never do more than its owner.
+func (u *apiTokenUsecaseImpl) Create(ctx context.Context, userID uuid.UUID, in CreateAPITokenInput) (*entities.APIToken, string, error) {
+	if len(in.Permissions) == 0 {
+		return nil, "", ErrAPITokenScopeMissing
+	}
+	usr, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if usr == nil {
+		return nil, "", ErrPermissionNotGranted
+	}
+	groups, err := u.groups.ListByUser(ctx, usr.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(groups) == 0 {
+		return nil, "", ErrPermissionNotGranted
+	}
+
+	var permIDs []uuid.UUID
+	var names []string
+	for _, name := range in.Permissions {
+		name = strings.TrimSpace(name)
+		parts := strings.SplitN(name, ".", 2)
+		if len(parts) != 2 {
+			return nil, "", fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+		p, err := u.perms.GetByResourceAction(ctx, parts[0], parts[1])
+		if err != nil {
+			return nil, "", err
+		}
+		if p == nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+		granted := false
+		for _, grp := range groups {
+			if granted, err = u.perms.HasGroupPermission(ctx, grp.Name, p.Resource, p.Action); err != nil {
+				return nil, "", err
+			}
+			if granted {
+				break
+			}
+		}
+		if !granted {
+			return nil, "", fmt.Errorf("%w: %s", ErrPermissionNotGranted, name)
+		}
+		permIDs = append(permIDs, p.ID)
+		names = append(names, p.Resource+"."+p.Action)
+	}
+
+	now := time.Now()
+	expiresAt, err := u.expiry(now, in.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := newAPITokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	t := &entities.APIToken{
+		ID:          uuid.New(),
+		UserID:      usr.ID,
+		Name:        strings.TrimSpace(in.Name),
+		TokenHash:   utils.HashString(raw),
+		Prefix:      raw[:len(APITokenPrefix)+6],
+		Permissions: names,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+	}
+	if err := u.tokens.Create(ctx, t, permIDs); err != nil {
+		return nil, "", err
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":  usr.ID,
+		"tokenID": t.ID,
+		"name":    t.Name,
+	}).Info("api token created")
+	return t, raw, nil
+}
+
+func (u *apiTokenUsecaseImpl) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	ok, err := u.tokens.Revoke(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAPITokenNotFound
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":  userID,
+		"tokenID": id,
+	}).Info("api token revoked")
+	return nil
+}
+
+func (u *apiTokenUsecaseImpl) Authenticate(ctx context.Context, raw string) (*entities.APIToken, error) {
+	if !strings.HasPrefix(raw, APITokenPrefix) {
+		return nil, nil
+	}
+	t, err := u.tokens.GetByTokenHash(ctx, utils.HashString(raw))
+	if err != nil || t == nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !t.Valid(now) {
+		return nil, nil
+	}
+	if err := u.tokens.TouchLastUsed(ctx, t.ID, now); err != nil {
+		logger.Log.WithError(err).Warn("failed to update api token usage")
+	}
+	return t, nil
+}
+
+// expiry applies the maximum lifetime to the requested expiry.
+func (u *apiTokenUsecaseImpl) expiry(now time.Time, requested *time.Time) (*time.Time, error) {
+	if requested == nil {
+		if u.maxLifetime <= 0 {
+			return nil, nil
+		}
+		max := now.Add(u.maxLifetime)
+		return &max, nil
+	}
+	if !requested.After(now) {
+		return nil, ErrInvalidTokenExpiry
+	}
+	if u.maxLifetime > 0 && requested.After(now.Add(u.maxLifetime)) {
+		return nil, fmt.Errorf("%w: must be within %s", ErrInvalidTokenExpiry, u.maxLifetime)
+	}
+	return requested, nil
+}
+
+func newAPITokenSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api token: %w", err)
+	}
+	return APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -4,12 +4,32 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	portalentities "system-portal/internal/domains/portal/entities"
 )
 
+// LoginResult is returned when a login step succeeds. When MFAToken is set
+// no tokens were issued yet and the second factor must be completed.
+type LoginResult struct {
+	AccessToken           string
+	RefreshToken          string
+	UserID                uuid.UUID
+	Role                  string
+	Groups                []string
+	MFAToken              string
+	MFAEnrollmentRequired bool
+	RecoveryCodes         []string
+}
+
 // AuthUsecase defines authentication business logic.
 type AuthUsecase interface {
-	Login(ctx context.Context, username, password, ip string) (string, string, uuid.UUID, string, error)
-	Refresh(ctx context.Context, refreshToken string) (string, string, error)
+	Login(ctx context.Context, username, password, ip, userAgent string) (*LoginResult, error)
+	CompleteMFALogin(ctx context.Context, mfaToken, code, ip, userAgent string) (*LoginResult, error)
+	// CompleteExternalLogin finishes a login whose password was checked by
+	// an identity provider. Lockout and MFA apply as for Login, unless
+	// mfaDone says the provider already verified a second factor.
+	CompleteExternalLogin(ctx context.Context, usr *portalentities.PortalUser, mfaDone bool, ip, userAgent string) (*LoginResult, error)
+	BeginMFALoginEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollment, error)
+	Refresh(ctx context.Context, refreshToken, ip, userAgent string) (string, string, error)
 	Validate(ctx context.Context, token string) error
 	Logout(ctx context.Context, token string) error
 }
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"time"
 
@@ -9,41 +11,228 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"system-portal/internal/domains/auth/entities"
 	"system-portal/internal/domains/auth/repositories"
+	portalentities "system-portal/internal/domains/portal/entities"
 	portalrepos "system-portal/internal/domains/portal/repositories"
 	"system-portal/pkg/jwt"
 	"system-portal/pkg/logger"
 	"system-portal/pkg/utils"
 )
 
+// maxMFAAttempts bounds how many codes can be tried against one challenge.
+const maxMFAAttempts = 5
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+)
+
 type authUsecaseImpl struct {
-	sessions repositories.SessionRepository
-	users    portalrepos.UserRepository
-	groups   portalrepos.GroupRepository
-	jwt      *jwt.RSAService
+	sessionIssuer
+	userAuditor
+	users        portalrepos.UserRepository
+	ldapConfigs  portalrepos.LDAPConfigRepository
+	ldapMappings portalrepos.LDAPGroupMappingRepository
+	mfaRepo      repositories.MFARepository
+	mfa          MFAUsecase
+	lockout      LockoutUsecase
+	challengeTTL time.Duration
 }
 
-func NewAuthUsecase(sessionRepo repositories.SessionRepository, userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, jwtSvc *jwt.RSAService) AuthUsecase {
-	return &authUsecaseImpl{sessions: sessionRepo, users: userRepo, groups: groupRepo, jwt: jwtSvc}
+func NewAuthUsecase(sessionRepo repositories.SessionRepository, userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, auditRepo portalrepos.AuditRepository, ldapRepo portalrepos.LDAPConfigRepository, mappingRepo portalrepos.LDAPGroupMappingRepository, mfaRepo repositories.MFARepository, mfaUC MFAUsecase, lockout LockoutUsecase, jwtSvc *jwt.RSAService, challengeTTL time.Duration) AuthUsecase {
+	return &authUsecaseImpl{
+		sessionIssuer: sessionIssuer{sessions: sessionRepo, memberships: groupRepo, jwt: jwtSvc},
+		userAuditor:   userAuditor{audit: auditRepo, groups: groupRepo},
+		users:         userRepo,
+		ldapConfigs:   ldapRepo,
+		ldapMappings:  mappingRepo,
+		mfaRepo:       mfaRepo,
+		mfa:           mfaUC,
+		lockout:       lockout,
+		challengeTTL:  challengeTTL,
+	}
 }
 
-func (u *authUsecaseImpl) Login(ctx context.Context, username, password, ip string) (string, string, uuid.UUID, string, error) {
+// Login checks the password locally or against LDAP. Users with MFA enabled,
+// or in any group that requires it, receive an MFA challenge token instead of JWTs.
+func (u *authUsecaseImpl) Login(ctx context.Context, username, password, ip, userAgent string) (*LoginResult, error) {
 	logger.Log.WithField("username", username).Info("login attempt")
+	if u.lockout != nil {
+		if err := u.lockout.Check(ctx, username, ip); err != nil {
+			logger.Log.WithError(err).WithField("username", username).Warn("login blocked")
+			return nil, err
+		}
+	}
+	usr, err := u.authenticate(ctx, username, password, ip)
+	if err != nil {
+		if u.lockout != nil && errors.Is(err, ErrInvalidCredentials) {
+			u.lockout.RecordFailure(ctx, username, ip)
+		}
+		return nil, err
+	}
+	return u.finishLogin(ctx, usr, false, ip, userAgent)
+}
+
+func (u *authUsecaseImpl) CompleteExternalLogin(ctx context.Context, usr *portalentities.PortalUser, mfaDone bool, ip, userAgent string) (*LoginResult, error) {
+	if u.lockout != nil {
+		if err := u.lockout.Check(ctx, usr.Username, ip); err != nil {
+			logger.Log.WithError(err).WithField("username", usr.Username).Warn("login blocked")
+			return nil, err
+		}
+	}
+	return u.finishLogin(ctx, usr, mfaDone, ip, userAgent)
+}
+
+// finishLogin issues an MFA challenge when the user is enrolled or a group
+// requires it, and otherwise starts the session.
+func (u *authUsecaseImpl) finishLogin(ctx context.Context, usr *portalentities.PortalUser, mfaDone bool, ip, userAgent string) (*LoginResult, error) {
+	m := u.membershipOf(ctx, usr)
+
+	if u.mfaRepo != nil && !mfaDone {
+		enrollment, err := u.mfaRepo.GetEnrollment(ctx, usr.ID)
+		if err != nil {
+			logger.Log.WithError(err).Error("failed to fetch mfa enrollment")
+			return nil, err
+		}
+		enabled := enrollment != nil && enrollment.Enabled
+		if enabled || m.mfaRequired {
+			token, err := u.newChallenge(ctx, usr.ID)
+			if err != nil {
+				return nil, err
+			}
+			logger.Log.WithField("username", usr.Username).Info("mfa challenge issued")
+			return &LoginResult{UserID: usr.ID, Role: m.role, Groups: m.groups, MFAToken: token, MFAEnrollmentRequired: !enabled}, nil
+		}
+	}
+
+	if u.lockout != nil {
+		u.lockout.RecordSuccess(ctx, usr.Username)
+	}
+	return u.startSession(ctx, usr, m, ip, userAgent)
+}
+
+// CompleteMFALogin finishes a login with a TOTP or recovery code. When the
+// user's group requires MFA and they are not enrolled yet, the code confirms
+// the pending enrollment and the new recovery codes are returned.
+func (u *authUsecaseImpl) CompleteMFALogin(ctx context.Context, mfaToken, code, ip, userAgent string) (*LoginResult, error) {
+	ch, err := u.activeChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	usr, err := u.users.GetByID(ctx, ch.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil || !usr.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	if u.lockout != nil {
+		if err := u.lockout.Check(ctx, usr.Username, ip); err != nil {
+			logger.Log.WithError(err).WithField("username", usr.Username).Warn("mfa login blocked")
+			return nil, err
+		}
+	}
+
+	enrollment, err := u.mfaRepo.GetEnrollment(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+	var recoveryCodes []string
+	ok := false
+	if enrollment != nil && enrollment.Enabled {
+		ok, err = u.mfa.VerifyCode(ctx, usr.ID, code)
+	} else {
+		recoveryCodes, err = u.mfa.ConfirmEnrollment(ctx, usr.ID, code)
+		ok = err == nil
+		if errors.Is(err, ErrInvalidMFACode) || errors.Is(err, ErrMFANotEnrolled) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := u.mfaRepo.IncrementChallengeAttempts(ctx, ch.ID); err != nil {
+			logger.Log.WithError(err).Warn("failed to record mfa attempt")
+		}
+		if u.lockout != nil {
+			u.lockout.RecordFailure(ctx, usr.Username, ip)
+		}
+		logger.Log.WithField("username", usr.Username).Warn("mfa code rejected")
+		return nil, ErrInvalidMFACode
+	}
+	if u.lockout != nil {
+		u.lockout.RecordSuccess(ctx, usr.Username)
+	}
+	if err := u.mfaRepo.DeleteChallenge(ctx, ch.ID); err != nil {
+		logger.Log.WithError(err).Warn("failed to delete mfa challenge")
+	}
+
+	res, err := u.startSession(ctx, usr, u.membershipOf(ctx, usr), ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	res.RecoveryCodes = recoveryCodes
+	return res, nil
+}
+
+// BeginMFALoginEnrollment lets a user whose group requires MFA enroll with
+// the challenge token from Login before they have a session.
+func (u *authUsecaseImpl) BeginMFALoginEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollment, error) {
+	ch, err := u.activeChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return u.mfa.BeginEnrollment(ctx, ch.UserID)
+}
+
+// authenticate checks the password against the user's source. Local accounts
+// that have a password are always checked locally, so they keep working as
+// break-glass accounts when the directory is unavailable; everyone else is
+// authenticated against LDAP.
+func (u *authUsecaseImpl) authenticate(ctx context.Context, username, password, ip string) (*portalentities.PortalUser, error) {
 	usr, err := u.users.GetByUsername(ctx, username)
 	if err != nil {
 		logger.Log.WithError(err).Error("failed to fetch user")
-		return "", "", uuid.Nil, "", errors.New("invalid credentials")
-	}
-	if usr == nil {
-		logger.Log.WithField("username", username).Warn("user not found")
-		return "", "", uuid.Nil, "", errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
-	if !usr.IsActive {
+	if usr != nil && !usr.IsActive {
 		logger.Log.WithField("username", username).Warn("user inactive")
-		return "", "", uuid.Nil, "", errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
+	if usr != nil && isLocal(usr) && usr.Password != "" {
+		return u.authenticateLocal(ctx, usr, password)
+	}
+	if usr != nil && !canLink(usr, portalentities.AuthSourceLDAP) {
+		logger.Log.WithFields(map[string]interface{}{
+			"username":   username,
+			"authSource": usr.AuthSource,
+		}).Warn("password login not allowed for user")
+		return nil, ErrInvalidCredentials
+	}
+	return u.authenticateLDAP(ctx, usr, username, password, ip)
+}
+
+func isLocal(usr *portalentities.PortalUser) bool {
+	return usr.AuthSource == "" || usr.AuthSource == portalentities.AuthSourceLocal
+}
+
+// canLink reports whether an existing user may be bound to an identity from
+// an external source. Local accounts with a password are break-glass
+// accounts and are never taken over, and users bound to one external source
+// are not moved to another.
+func canLink(usr *portalentities.PortalUser, source string) bool {
+	if isLocal(usr) {
+		return usr.Password == ""
+	}
+	return usr.AuthSource == source
+}
+
+func (u *authUsecaseImpl) authenticateLocal(ctx context.Context, usr *portalentities.PortalUser, password string) (*portalentities.PortalUser, error) {
 	if err := bcrypt.CompareHashAndPassword([]byte(usr.Password), []byte(password)); err != nil {
-		logger.Log.WithField("username", username).Warn("password mismatch")
-		return "", "", uuid.Nil, "", errors.New("invalid credentials")
+		logger.Log.WithField("username", usr.Username).Warn("password mismatch")
+		return nil, ErrInvalidCredentials
 	}
 
 	if cost, err := bcrypt.Cost([]byte(usr.Password)); err == nil && cost > bcrypt.DefaultCost {
@@ -52,41 +241,61 @@ func (u *authUsecaseImpl) Login(ctx context.Context, username, password, ip stri
 			if err := u.users.Update(ctx, usr); err != nil {
 				logger.Log.WithError(err).Warn("failed to update password hash")
 			} else {
-				logger.Log.WithField("username", username).Debug("password rehashed with lower cost")
+				logger.Log.WithField("username", usr.Username).Debug("password rehashed with lower cost")
 			}
 		}
 	}
+	return usr, nil
+}
 
-	role := ""
-	if g, err := u.groups.GetByID(ctx, usr.GroupID); err == nil && g != nil {
-		role = g.Name
-	} else if err != nil {
-		logger.Log.WithError(err).Warn("failed to fetch user group")
-	}
-	access, _ := u.jwt.GenerateAccessToken(username, role)
-	refresh, _ := u.jwt.GenerateRefreshToken(username, role)
-	accessHash := utils.HashString(access)
-	refreshHash := utils.HashString(refresh)
-	s := &entities.Session{
-		ID:               uuid.New(),
-		UserID:           usr.ID,
-		TokenHash:        accessHash,
-		RefreshTokenHash: refreshHash,
-		ExpiresAt:        time.Now().Add(time.Hour),
-		RefreshExpiresAt: time.Now().Add(24 * time.Hour),
-		IsActive:         true,
-		CreatedAt:        time.Now(),
-		IPAddress:        ip,
-	}
-	if err := u.sessions.Create(ctx, s); err != nil {
-		logger.Log.WithError(err).Error("failed to create session")
-	} else {
-		logger.Log.WithField("username", username).Info("session created")
+func (u *authUsecaseImpl) newChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	if err := u.mfaRepo.DeleteExpiredChallenges(ctx, time.Now()); err != nil {
+		logger.Log.WithError(err).Warn("failed to purge expired mfa challenges")
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	now := time.Now()
+	err := u.mfaRepo.CreateChallenge(ctx, &entities.MFAChallenge{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: utils.HashString(token),
+		ExpiresAt: now.Add(u.challengeTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *authUsecaseImpl) activeChallenge(ctx context.Context, mfaToken string) (*entities.MFAChallenge, error) {
+	if u.mfaRepo == nil || mfaToken == "" {
+		return nil, ErrInvalidMFAChallenge
+	}
+	ch, err := u.mfaRepo.GetChallengeByTokenHash(ctx, utils.HashString(mfaToken))
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || time.Now().After(ch.ExpiresAt) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	if ch.Attempts >= maxMFAAttempts {
+		if err := u.mfaRepo.DeleteChallenge(ctx, ch.ID); err != nil {
+			logger.Log.WithError(err).Warn("failed to delete mfa challenge")
+		}
+		return nil, ErrInvalidMFAChallenge
 	}
-	return access, refresh, usr.ID, role, nil
+	return ch, nil
 }
 
-func (u *authUsecaseImpl) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// is single use: its session is rotated into a new one in the same family.
+// Presenting a token whose session was already rotated indicates the token
+// leaked, so every session in the family is revoked.
+func (u *authUsecaseImpl) Refresh(ctx context.Context, refreshToken, ip, userAgent string) (string, string, error) {
 	claims, err := u.jwt.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		logger.Log.WithError(err).Warn("refresh token validation failed")
@@ -94,44 +303,83 @@ func (u *authUsecaseImpl) Refresh(ctx context.Context, refreshToken string) (str
 	}
 	logger.Log.WithField("username", claims.Username).Info("refresh token validated")
 
-	usr, err := u.users.GetByUsername(ctx, claims.Username)
+	sess, err := u.sessions.GetByRefreshTokenHash(ctx, utils.HashString(refreshToken))
+	if err != nil {
+		return "", "", err
+	}
+	if sess == nil {
+		logger.Log.WithField("username", claims.Username).Warn("refresh token has no session")
+		return "", "", ErrInvalidRefreshToken
+	}
+	if sess.RotatedAt != nil {
+		u.revokeFamily(ctx, sess, claims.Username, ip)
+		return "", "", ErrRefreshTokenReused
+	}
+	if !sess.IsActive || time.Now().After(sess.RefreshExpiresAt) {
+		logger.Log.WithField("sessionID", sess.ID).Warn("refresh token session inactive or expired")
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	usr, err := u.users.GetByID(ctx, sess.UserID)
 	if err != nil {
 		logger.Log.WithError(err).Error("failed to fetch user for refresh")
 		return "", "", err
 	}
-	if usr == nil || !usr.IsActive {
+	if usr == nil || !usr.IsActive || usr.Username != claims.Username {
 		logger.Log.WithField("username", claims.Username).Warn("user not found or inactive")
-		return "", "", errors.New("invalid credentials")
-	}
-
-	role := ""
-	if g, err := u.groups.GetByID(ctx, usr.GroupID); err == nil && g != nil {
-		role = g.Name
-	} else if err != nil {
-		logger.Log.WithError(err).Warn("failed to fetch user group")
-	}
-	access, _ := u.jwt.GenerateAccessToken(claims.Username, role)
-	refreshNew, _ := u.jwt.GenerateRefreshToken(claims.Username, role)
-	accessHash := utils.HashString(access)
-	refreshHash := utils.HashString(refreshNew)
-	s := &entities.Session{
-		ID:               uuid.New(),
-		UserID:           usr.ID,
-		TokenHash:        accessHash,
-		RefreshTokenHash: refreshHash,
-		ExpiresAt:        time.Now().Add(time.Hour),
-		RefreshExpiresAt: time.Now().Add(24 * time.Hour),
-		IsActive:         true,
-		CreatedAt:        time.Now(),
-	}
-	if err := u.sessions.Create(ctx, s); err != nil {
-		logger.Log.WithError(err).Error("failed to create session on refresh")
-	} else {
-		logger.Log.WithField("username", claims.Username).Info("session refreshed")
+		return "", "", ErrInvalidCredentials
 	}
+
+	next, access, refreshNew, err := u.newSession(usr, u.membershipOf(ctx, usr), sess.FamilyID, ip, userAgent)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to issue tokens")
+		return "", "", err
+	}
+	if err := u.sessions.Rotate(ctx, sess.ID, next); err != nil {
+		if errors.Is(err, repositories.ErrSessionRotated) {
+			u.revokeFamily(ctx, sess, claims.Username, ip)
+			return "", "", ErrRefreshTokenReused
+		}
+		if errors.Is(err, repositories.ErrSessionInactive) {
+			logger.Log.WithField("sessionID", sess.ID).Warn("refresh token session ended during refresh")
+			return "", "", ErrInvalidRefreshToken
+		}
+		logger.Log.WithError(err).Error("failed to rotate session on refresh")
+		return "", "", err
+	}
+	logger.Log.WithField("username", claims.Username).Info("session refreshed")
 	return access, refreshNew, nil
 }
 
+// revokeFamily deactivates every session descended from the same login and
+// records a security audit event.
+func (u *authUsecaseImpl) revokeFamily(ctx context.Context, sess *entities.Session, username, ip string) {
+	logger.Log.WithFields(map[string]interface{}{
+		"username": username,
+		"familyID": sess.FamilyID,
+	}).Warn("refresh token reuse detected; revoking session family")
+	if err := u.sessions.DeactivateFamily(ctx, sess.FamilyID); err != nil {
+		logger.Log.WithError(err).Error("failed to revoke session family")
+	}
+	if u.audit == nil {
+		return
+	}
+	entry := &portalentities.AuditLog{
+		ID:           uuid.New(),
+		UserID:       sess.UserID,
+		Username:     username,
+		Action:       "auth.refresh_token_reuse",
+		ResourceType: "auth",
+		ResourceName: "session_family:" + sess.FamilyID.String(),
+		IPAddress:    ip,
+		Success:      false,
+		CreatedAt:    time.Now(),
+	}
+	if err := u.audit.Add(ctx, entry); err != nil {
+		logger.Log.WithError(err).Warn("failed to audit refresh token reuse")
+	}
+}
+
 func (u *authUsecaseImpl) Validate(ctx context.Context, token string) error {
 	_, err := u.jwt.ValidateAccessToken(token)
 	if err != nil {
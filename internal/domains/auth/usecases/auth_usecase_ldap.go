@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	portalentities "system-portal/internal/domains/portal/entities"
+	"system-portal/internal/shared/infrastructure/ldap"
+	"system-portal/pkg/logger"
+)
+
+// authenticateLDAP binds as the user against the directory configured in
+// ldap_configs. On the first successful login a portal user is provisioned,
+// or an existing one without a local password is linked; on every login the
+// user's portal group is re-synced from their AD group membership.
+func (u *authUsecaseImpl) authenticateLDAP(ctx context.Context, usr *portalentities.PortalUser, username, password, ip string) (*portalentities.PortalUser, error) {
+	if u.ldapConfigs == nil {
+		return nil, ErrInvalidCredentials
+	}
+	cfg, err := u.ldapConfigs.Get(ctx)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load ldap config")
+		return nil, ErrInvalidCredentials
+	}
+	if cfg == nil {
+		logger.Log.WithField("username", username).Warn("user not found")
+		return nil, ErrInvalidCredentials
+	}
+
+	client := ldap.NewClient(ldap.Config{Host: cfg.Host, Port: cfg.Port, BindDN: cfg.BindDN, BindPassword: cfg.BindPassword, BaseDN: cfg.BaseDN})
+	entry, err := client.AuthenticateUser(username, password)
+	if err != nil {
+		logger.Log.WithError(err).WithField("username", username).Warn("ldap authentication failed")
+		return nil, ErrInvalidCredentials
+	}
+
+	// sAMAccountName is case-insensitive; use the directory's spelling
+	if usr == nil && entry.Username != username {
+		if usr, err = u.users.GetByUsername(ctx, entry.Username); err != nil {
+			logger.Log.WithError(err).Error("failed to fetch user")
+			return nil, ErrInvalidCredentials
+		}
+		if usr != nil && !usr.IsActive {
+			logger.Log.WithField("username", entry.Username).Warn("user inactive")
+			return nil, ErrInvalidCredentials
+		}
+		if usr != nil && !canLink(usr, portalentities.AuthSourceLDAP) {
+			logger.Log.WithField("username", entry.Username).Warn("refusing to link account to ldap")
+			return nil, ErrInvalidCredentials
+		}
+	}
+
+	groupID, err := u.mappedGroup(ctx, entry.MemberOf)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load ldap group mappings")
+		return nil, ErrInvalidCredentials
+	}
+	if groupID == uuid.Nil {
+		logger.Log.WithField("username", entry.Username).Warn("no portal group mapped for ldap user")
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if usr == nil {
+		if entry.Email == "" {
+			logger.Log.WithField("username", entry.Username).Warn("ldap user has no email; cannot provision")
+			return nil, ErrInvalidCredentials
+		}
+		usr = &portalentities.PortalUser{
+			ID:         uuid.New(),
+			Username:   entry.Username,
+			Email:      entry.Email,
+			FullName:   entry.DisplayName,
+			GroupID:    groupID,
+			IsActive:   true,
+			AuthSource: portalentities.AuthSourceLDAP,
+			ExternalID: entry.DN,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := u.users.Create(ctx, usr); err != nil {
+			logger.Log.WithError(err).Error("failed to provision ldap user")
+			return nil, err
+		}
+		logger.Log.WithField("username", usr.Username).Info("ldap user provisioned")
+		u.auditUser(ctx, usr, "auth.ldap_provision", ip)
+		return usr, nil
+	}
+
+	linked := usr.AuthSource != portalentities.AuthSourceLDAP
+	regrouped := usr.GroupID != groupID
+	usr.AuthSource = portalentities.AuthSourceLDAP
+	usr.ExternalID = entry.DN
+	usr.GroupID = groupID
+	if entry.DisplayName != "" {
+		usr.FullName = entry.DisplayName
+	}
+	if err := u.users.Update(ctx, usr); err != nil {
+		logger.Log.WithError(err).Error("failed to sync ldap user")
+		return nil, err
+	}
+	if linked {
+		logger.Log.WithField("username", usr.Username).Info("portal user linked to ldap")
+		u.auditUser(ctx, usr, "auth.ldap_link", ip)
+	} else if regrouped {
+		logger.Log.WithField("username", usr.Username).Info("portal group updated from ldap")
+		u.auditUser(ctx, usr, "auth.ldap_group_sync", ip)
+	}
+	return usr, nil
+}
+
+// mappedGroup returns the portal group of the highest priority mapping that
+// matches one of the user's memberOf DNs, or uuid.Nil when none match.
+func (u *authUsecaseImpl) mappedGroup(ctx context.Context, memberOf []string) (uuid.UUID, error) {
+	if u.ldapMappings == nil {
+		return uuid.Nil, nil
+	}
+	mappings, err := u.ldapMappings.List(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, m := range mappings {
+		for _, dn := range memberOf {
+			if strings.EqualFold(strings.TrimSpace(dn), m.LDAPGroupDN) {
+				return m.GroupID, nil
+			}
+		}
+	}
+	return uuid.Nil, nil
+}
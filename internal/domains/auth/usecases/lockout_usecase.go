@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"system-portal/internal/domains/auth/entities"
+)
+
+// LockoutPolicy configures brute-force protection. A zero threshold disables
+// tracking for that scope.
+type LockoutPolicy struct {
+	MaxUserFailures int
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+}
+
+// LoginBlockedError is returned while a username or source IP is locked out
+// or has to wait before its next attempt.
+type LoginBlockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return "too many failed login attempts; temporarily locked"
+	}
+	return "too many failed login attempts; retry later"
+}
+
+// LockoutUsecase tracks failed logins per username and per source IP.
+type LockoutUsecase interface {
+	// Check returns a *LoginBlockedError when a login may not be attempted yet.
+	Check(ctx context.Context, username, ip string) error
+	RecordFailure(ctx context.Context, username, ip string)
+	RecordSuccess(ctx context.Context, username string)
+	// Status returns the failure state of a username, or nil when clean.
+	Status(ctx context.Context, username string) (*entities.LoginAttempt, error)
+	// Unlock clears failures and any lockout of a username.
+	Unlock(ctx context.Context, username string) error
+}
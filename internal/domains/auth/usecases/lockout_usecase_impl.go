@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	portalentities "system-portal/internal/domains/portal/entities"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+type lockoutUsecaseImpl struct {
+	repo   repositories.LoginAttemptRepository
+	audit  portalrepos.AuditRepository
+	policy LockoutPolicy
+}
+
+func NewLockoutUsecase(repo repositories.LoginAttemptRepository, auditRepo portalrepos.AuditRepository, policy LockoutPolicy) LockoutUsecase {
+	return &lockoutUsecaseImpl{repo: repo, audit: auditRepo, policy: policy}
+}
+
+// loginKey normalises usernames so case variants share one counter.
+func loginKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func (u *lockoutUsecaseImpl) Check(ctx context.Context, username, ip string) error {
+	now := time.Now()
+	for _, s := range u.scopes(username, ip) {
+		a, err := u.repo.Get(ctx, s.scope, s.key)
+		if err != nil {
+			return err
+		}
+		if a == nil {
+			continue
+		}
+		if a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+			return &LoginBlockedError{RetryAfter: a.LockedUntil.Sub(now), Locked: true}
+		}
+		if a.NextAttemptAt != nil && now.Before(*a.NextAttemptAt) {
+			return &LoginBlockedError{RetryAfter: a.NextAttemptAt.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt, sets the progressive delay and locks
+// the username or IP once its threshold is reached.
+func (u *lockoutUsecaseImpl) RecordFailure(ctx context.Context, username, ip string) {
+	now := time.Now()
+	for _, s := range u.scopes(username, ip) {
+		a, err := u.repo.RecordFailure(ctx, s.scope, s.key, now, now.Add(-u.policy.Window))
+		if err != nil {
+			logger.Log.WithError(err).Warn("failed to record login failure")
+			continue
+		}
+		next := now.Add(u.delay(a.Failures))
+		var lockedUntil *time.Time
+		if a.Failures >= s.max {
+			until := now.Add(u.policy.LockoutDuration)
+			lockedUntil = &until
+			next = until
+		}
+		if err := u.repo.Block(ctx, s.scope, s.key, next, lockedUntil); err != nil {
+			logger.Log.WithError(err).Warn("failed to apply login delay")
+			continue
+		}
+		if lockedUntil != nil {
+			u.auditLockout(ctx, s.scope, username, ip, a.Failures, *lockedUntil)
+		}
+	}
+}
+
+func (u *lockoutUsecaseImpl) RecordSuccess(ctx context.Context, username string) {
+	if u.policy.MaxUserFailures > 0 {
+		if err := u.repo.Clear(ctx, entities.LoginScopeUser, loginKey(username)); err != nil {
+			logger.Log.WithError(err).Warn("failed to clear login failures")
+		}
+	}
+	if err := u.repo.DeleteStale(ctx, time.Now().Add(-u.policy.Window)); err != nil {
+		logger.Log.WithError(err).Warn("failed to purge stale login failures")
+	}
+}
+
+func (u *lockoutUsecaseImpl) Status(ctx context.Context, username string) (*entities.LoginAttempt, error) {
+	return u.repo.Get(ctx, entities.LoginScopeUser, loginKey(username))
+}
+
+func (u *lockoutUsecaseImpl) Unlock(ctx context.Context, username string) error {
+	if err := u.repo.Clear(ctx, entities.LoginScopeUser, loginKey(username)); err != nil {
+		return err
+	}
+	logger.Log.WithField("username", username).Info("account unlocked")
+	return nil
+}
+
+// delay doubles BaseDelay for every failure after the first, up to MaxDelay.
+func (u *lockoutUsecaseImpl) delay(failures int) time.Duration {
+	d := u.policy.BaseDelay
+	for i := 1; i < failures && d < u.policy.MaxDelay; i++ {
+		d *= 2
+	}
+	if u.policy.MaxDelay > 0 && d > u.policy.MaxDelay {
+		d = u.policy.MaxDelay
+	}
+	return d
+}
+
+type lockoutScope struct {
+	scope string
+	key   string
+	max   int
+}
+
+func (u *lockoutUsecaseImpl) scopes(username, ip string) []lockoutScope {
+	var out []lockoutScope
+	if key := loginKey(username); key != "" && u.policy.MaxUserFailures > 0 {
+		out = append(out, lockoutScope{entities.LoginScopeUser, key, u.policy.MaxUserFailures})
+	}
+	if ip != "" && u.policy.MaxIPFailures > 0 {
+		out = append(out, lockoutScope{entities.LoginScopeIP, ip, u.policy.MaxIPFailures})
+	}
+	return out
+}
+
+func (u *lockoutUsecaseImpl) auditLockout(ctx context.Context, scope, username, ip string, failures int, until time.Time) {
+	action, resource := "auth.account_locked", "user:"+loginKey(username)
+	if scope == entities.LoginScopeIP {
+		action, resource = "auth.ip_locked", "ip:"+ip
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"scope":       scope,
+		"username":    username,
+		"ip":          ip,
+		"failures":    failures,
+		"lockedUntil": until,
+	}).Warn("login locked after repeated failures")
+	if u.audit == nil {
+		return
+	}
+	entry := &portalentities.AuditLog{
+		ID:           uuid.New(),
+		Username:     username,
+		Action:       action,
+		ResourceType: "auth",
+		ResourceName: resource,
+		IPAddress:    ip,
+		Success:      false,
+		CreatedAt:    time.Now(),
+	}
+	if err := u.audit.Add(ctx, entry); err != nil {
+		logger.Log.WithError(err).Warn("failed to audit login lockout")
+	}
+}
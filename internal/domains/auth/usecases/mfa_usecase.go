@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MFAStatus describes a user's second factor state.
+type MFAStatus struct {
+	Enabled                bool
+	Required               bool
+	RecoveryCodesRemaining int
+}
+
+// MFAEnrollment is returned when a user starts TOTP enrollment.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAUsecase manages TOTP enrollment and verification for portal users.
+type MFAUsecase interface {
+	Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error)
+	BeginEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+	// VerifyCode accepts either a TOTP code or an unused recovery code.
+	VerifyCode(ctx context.Context, userID uuid.UUID, code string) (bool, error)
+}
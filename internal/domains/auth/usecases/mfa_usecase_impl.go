@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/totp"
+	"system-portal/pkg/utils"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFANotEnrolled     = errors.New("mfa not enrolled")
+	ErrMFAAlreadyEnabled  = errors.New("mfa already enabled")
+	ErrMFARequiredByGroup = errors.New("mfa is required for this group")
+	ErrInvalidMFACode     = errors.New("invalid mfa code")
+)
+
+type mfaUsecaseImpl struct {
+	repo   repositories.MFARepository
+	users  portalrepos.UserRepository
+	groups portalrepos.GroupRepository
+	issuer string
+}
+
+func NewMFAUsecase(repo repositories.MFARepository, userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, issuer string) MFAUsecase {
+	return &mfaUsecaseImpl{repo: repo, users: userRepo, groups: groupRepo, issuer: issuer}
+}
+
+func (u *mfaUsecaseImpl) Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	st := &MFAStatus{}
+	required, err := u.required(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st.Required = required
+	e, err := u.repo.GetEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil && e.Enabled {
+		st.Enabled = true
+		if st.RecoveryCodesRemaining, err = u.repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// BeginEnrollment generates a new secret that stays pending until confirmed.
+func (u *mfaUsecaseImpl) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error) {
+	usr, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil {
+		return nil, errors.New("user not found")
+	}
+	existing, err := u.repo.GetEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.SaveEnrollment(ctx, &entities.MFAEnrollment{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	logger.Log.WithField("username", usr.Username).Info("mfa enrollment started")
+	return &MFAEnrollment{Secret: secret, ProvisioningURI: totp.ProvisioningURI(u.issuer, usr.Username, secret)}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves possession of the
+// secret and returns a fresh set of recovery codes.
+func (u *mfaUsecaseImpl) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	e, err := u.repo.GetEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if e.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	step, ok := totp.Validate(e.Secret, code, time.Now(), e.LastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	now := time.Now()
+	e.Enabled = true
+	e.LastStep = step
+	e.EnabledAt = &now
+	if err := u.repo.SaveEnrollment(ctx, e); err != nil {
+		return nil, err
+	}
+	logger.Log.WithField("userID", userID).Info("mfa enabled")
+	return u.newRecoveryCodes(ctx, userID)
+}
+
+func (u *mfaUsecaseImpl) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ok, err := u.VerifyCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	return u.newRecoveryCodes(ctx, userID)
+}
+
+func (u *mfaUsecaseImpl) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	required, err := u.required(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredByGroup
+	}
+	ok, err := u.VerifyCode(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	logger.Log.WithField("userID", userID).Info("mfa disabled")
+	return u.repo.DeleteEnrollment(ctx, userID)
+}
+
+func (u *mfaUsecaseImpl) VerifyCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	e, err := u.repo.GetEnrollment(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if e == nil || !e.Enabled {
+		return false, ErrMFANotEnrolled
+	}
+	if step, ok := totp.Validate(e.Secret, code, time.Now(), e.LastStep); ok {
+		// a concurrent request may have consumed the same step
+		return u.repo.ConsumeStep(ctx, userID, step)
+	}
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) == 0 {
+		return false, nil
+	}
+	used, err := u.repo.UseRecoveryCode(ctx, userID, utils.HashString(normalized))
+	if used {
+		logger.Log.WithField("userID", userID).Warn("mfa recovery code used")
+	}
+	return used, err
+}
+
+// required reports whether any of the user's groups requires MFA.
+func (u *mfaUsecaseImpl) required(ctx context.Context, userID uuid.UUID) (bool, error) {
+	groups, err := u.groups.ListByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, g := range groups {
+		if g.MFARequired {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *mfaUsecaseImpl) newRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, utils.HashString(raw))
+	}
+	if err := u.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
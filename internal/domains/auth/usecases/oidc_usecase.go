@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOIDCDisabled      = errors.New("oidc login is not configured")
+	ErrInvalidOIDCState  = errors.New("invalid or expired oidc login state")
+	ErrOIDCAccessDenied  = errors.New("no portal access for this account")
+	ErrOIDCMissingClaims = errors.New("id token lacks username or email claim")
+)
+
+// OIDCGroupMapping maps a value of the groups claim to a portal group name.
+type OIDCGroupMapping struct {
+	Claim string
+	Group string
+}
+
+// OIDCSettings controls how ID token claims become portal users.
+type OIDCSettings struct {
+	UsernameClaim string
+	GroupsClaim   string
+	// GroupMappings are checked in order; the first match wins
+	GroupMappings []OIDCGroupMapping
+	StateTTL      time.Duration
+	// TrustIdPMFA skips the local second factor when the ID token shows the
+	// provider verified one: an amr value in MFAMethods or an acr value in
+	// MFAACRValues. Otherwise OIDC logins get the same MFA challenge as
+	// password logins.
+	TrustIdPMFA  bool
+	MFAMethods   []string
+	MFAACRValues []string
+}
+
+// OIDCUsecase implements single sign-on through an OpenID provider. A
+// successful login ends in the same session and token pair as a password
+// login, so the rest of the API is unaware of how the user signed in.
+type OIDCUsecase interface {
+	// BeginLogin returns the authorization URL the browser is redirected to
+	// and the state the callback must come back with. The caller binds the
+	// state to the browser so a callback cannot be replayed in another one.
+	BeginLogin(ctx context.Context) (authURL, state string, err error)
+	// CompleteLogin redeems the callback's code and state for portal tokens.
+	CompleteLogin(ctx context.Context, code, state, ip, userAgent string) (*LoginResult, error)
+}
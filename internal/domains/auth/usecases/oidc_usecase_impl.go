@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	portalentities "system-portal/internal/domains/portal/entities"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/oidc"
+	"system-portal/pkg/utils"
+)
+
+type oidcUsecaseImpl struct {
+	userAuditor
+	logins   AuthUsecase
+	provider *oidc.Provider
+	states   repositories.OIDCStateRepository
+	users    portalrepos.UserRepository
+	settings OIDCSettings
+}
+
+// NewOIDCUsecase creates the single sign-on flow. A nil provider disables it.
+// Logins finish through logins, so lockout and MFA apply as for passwords.
+func NewOIDCUsecase(provider *oidc.Provider, stateRepo repositories.OIDCStateRepository, logins AuthUsecase, userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, auditRepo portalrepos.AuditRepository, settings OIDCSettings) OIDCUsecase {
+	return &oidcUsecaseImpl{
+		userAuditor: userAuditor{audit: auditRepo, groups: groupRepo},
+		logins:      logins,
+		provider:    provider,
+		states:      stateRepo,
+		users:       userRepo,
+		settings:    settings,
+	}
+}
+
+func (u *oidcUsecaseImpl) BeginLogin(ctx context.Context) (string, string, error) {
+	if u.provider == nil {
+		return "", "", ErrOIDCDisabled
+	}
+	if err := u.states.DeleteExpired(ctx, time.Now()); err != nil {
+		logger.Log.WithError(err).Warn("failed to purge expired oidc states")
+	}
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+	authURL, err := u.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to build oidc authorization url")
+		return "", "", err
+	}
+	now := time.Now()
+	err = u.states.Create(ctx, &entities.OIDCLoginState{
+		ID:           uuid.New(),
+		StateHash:    utils.HashString(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(u.settings.StateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+func (u *oidcUsecaseImpl) CompleteLogin(ctx context.Context, code, state, ip, userAgent string) (*LoginResult, error) {
+	if u.provider == nil {
+		return nil, ErrOIDCDisabled
+	}
+	if code == "" || state == "" {
+		return nil, ErrInvalidOIDCState
+	}
+	st, err := u.states.Consume(ctx, utils.HashString(state))
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || time.Now().After(st.ExpiresAt) {
+		logger.Log.Warn("oidc callback with unknown or expired state")
+		return nil, ErrInvalidOIDCState
+	}
+
+	token, err := u.provider.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		logger.Log.WithError(err).Warn("oidc code exchange failed")
+		return nil, ErrInvalidCredentials
+	}
+	claims, err := u.provider.VerifyIDToken(ctx, token.IDToken, st.Nonce)
+	if err != nil {
+		logger.Log.WithError(err).Warn("oidc id token rejected")
+		return nil, ErrInvalidCredentials
+	}
+
+	usr, _, err := u.resolveUser(ctx, claims, ip)
+	if err != nil {
+		return nil, err
+	}
+	mfaDone := u.idpVerifiedMFA(claims)
+	logger.Log.WithField("username", usr.Username).WithField("idpMFA", mfaDone).Info("oidc login")
+	return u.logins.CompleteExternalLogin(ctx, usr, mfaDone, ip, userAgent)
+}
+
+// idpVerifiedMFA reports whether the provider is trusted for the second
+// factor and its amr or acr claim shows one was used.
+func (u *oidcUsecaseImpl) idpVerifiedMFA(claims oidc.Claims) bool {
+	if !u.settings.TrustIdPMFA {
+		return false
+	}
+	for _, amr := range claims.Strings("amr") {
+		if containsFold(u.settings.MFAMethods, amr) {
+			return true
+		}
+	}
+	acr := claims.String("acr")
+	return acr != "" && containsFold(u.settings.MFAACRValues, acr)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveUser finds the portal user for the ID token, provisioning or
+// linking one on first login, and syncs their group from the groups claim.
+func (u *oidcUsecaseImpl) resolveUser(ctx context.Context, claims oidc.Claims, ip string) (*portalentities.PortalUser, *portalentities.PortalGroup, error) {
+	externalID := claims.String("iss") + "|" + claims.String("sub")
+	username := claims.String(u.settings.UsernameClaim)
+	email := claims.String("email")
+	if username == "" {
+		username = email
+	}
+	if username == "" {
+		return nil, nil, ErrOIDCMissingClaims
+	}
+
+	group, err := u.mappedGroup(ctx, claims.Strings(u.settings.GroupsClaim))
+	if err != nil {
+		return nil, nil, err
+	}
+	if group == nil {
+		logger.Log.WithField("username", username).Warn("no portal group mapped for oidc user")
+		return nil, nil, ErrOIDCAccessDenied
+	}
+
+	usr, err := u.users.GetByExternalID(ctx, portalentities.AuthSourceOIDC, externalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if usr == nil {
+		if usr, err = u.users.GetByUsername(ctx, username); err != nil {
+			return nil, nil, err
+		}
+		// a pre-provisioned oidc user may be claimed, but not one already
+		// bound to a different subject
+		if usr != nil && (!canLink(usr, portalentities.AuthSourceOIDC) || usr.ExternalID != "") {
+			logger.Log.WithField("username", username).Warn("refusing to link account to oidc identity")
+			return nil, nil, ErrOIDCAccessDenied
+		}
+	}
+	if usr != nil && !usr.IsActive {
+		logger.Log.WithField("username", usr.Username).Warn("user inactive")
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if usr == nil {
+		if email == "" {
+			return nil, nil, ErrOIDCMissingClaims
+		}
+		usr = &portalentities.PortalUser{
+			ID:         uuid.New(),
+			Username:   username,
+			Email:      email,
+			FullName:   claims.String("name"),
+			GroupID:    group.ID,
+			IsActive:   true,
+			AuthSource: portalentities.AuthSourceOIDC,
+			ExternalID: externalID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := u.users.Create(ctx, usr); err != nil {
+			logger.Log.WithError(err).Error("failed to provision oidc user")
+			return nil, nil, err
+		}
+		logger.Log.WithField("username", usr.Username).Info("oidc user provisioned")
+		u.auditUser(ctx, usr, "auth.oidc_provision", ip)
+		return usr, group, nil
+	}
+
+	linked := usr.ExternalID == ""
+	regrouped := usr.GroupID != group.ID
+	usr.AuthSource = portalentities.AuthSourceOIDC
+	usr.ExternalID = externalID
+	usr.GroupID = group.ID
+	if name := claims.String("name"); name != "" {
+		usr.FullName = name
+	}
+	if err := u.users.Update(ctx, usr); err != nil {
+		logger.Log.WithError(err).Error("failed to sync oidc user")
+		return nil, nil, err
+	}
+	if linked {
+		logger.Log.WithField("username", usr.Username).Info("portal user linked to oidc identity")
+		u.auditUser(ctx, usr, "auth.oidc_link", ip)
+	} else if regrouped {
+		logger.Log.WithField("username", usr.Username).Info("portal group updated from oidc claims")
+		u.auditUser(ctx, usr, "auth.oidc_group_sync", ip)
+	}
+	return usr, group, nil
+}
+
+func (u *oidcUsecaseImpl) mappedGroup(ctx context.Context, values []string) (*portalentities.PortalGroup, error) {
+	for _, m := range u.settings.GroupMappings {
+		for _, v := range values {
+			if strings.EqualFold(v, m.Claim) {
+				g, err := u.groups.GetByName(ctx, m.Group)
+				if err != nil {
+					return nil, err
+				}
+				if g == nil || !g.IsActive {
+					logger.Log.WithField("group", m.Group).Warn("oidc group mapping points to missing or inactive group")
+					continue
+				}
+				return g, nil
+			}
+		}
+	}
+	return nil, nil
+}
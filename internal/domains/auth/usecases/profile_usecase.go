@@ -0,0 +1,26 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	portalentities "system-portal/internal/domains/portal/entities"
+)
+
+// Profile is the logged-in user's own view of their account. Group is the
+// primary group and Groups every group the user belongs to; Permissions are
+// the "resource.action" names granted to any of them.
+type Profile struct {
+	User        *portalentities.PortalUser
+	Group       *portalentities.PortalGroup
+	Groups      []*portalentities.PortalGroup
+	Permissions []string
+}
+
+// ProfileUsecase lets users read their profile and change their password.
+type ProfileUsecase interface {
+	Get(ctx context.Context, userID uuid.UUID) (*Profile, error)
+	// ChangePassword verifies the current password, stores the new one and
+	// revokes every other session of the user, returning how many were revoked.
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, current, next, ip string) (int, error)
+}
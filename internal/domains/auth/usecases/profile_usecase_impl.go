@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"system-portal/internal/domains/auth/repositories"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/validator"
+)
+
+var (
+	ErrProfileNotFound   = errors.New("user not found")
+	ErrExternalPassword  = errors.New("password is managed by the external identity provider")
+	ErrWrongPassword     = errors.New("current password is incorrect")
+	ErrPasswordUnchanged = errors.New("new password must differ from the current one")
+	ErrPasswordTooWeak   = errors.New("password does not meet the password policy")
+)
+
+type profileUsecaseImpl struct {
+	userAuditor
+	users    portalrepos.UserRepository
+	perms    portalrepos.PermissionRepository
+	sessions repositories.SessionRepository
+	lockout  LockoutUsecase
+	policy   validator.PasswordPolicy
+}
+
+func NewProfileUsecase(userRepo portalrepos.UserRepository, groupRepo portalrepos.GroupRepository, permRepo portalrepos.PermissionRepository, auditRepo portalrepos.AuditRepository, sessionRepo repositories.SessionRepository, lockout LockoutUsecase, policy validator.PasswordPolicy) ProfileUsecase {
+	return &profileUsecaseImpl{
+		userAuditor: userAuditor{audit: auditRepo, groups: groupRepo},
+		users:       userRepo,
+		perms:       permRepo,
+		sessions:    sessionRepo,
+		lockout:     lockout,
+		policy:      policy,
+	}
+}
+
+func (u *profileUsecaseImpl) Get(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	usr, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil {
+		return nil, ErrProfileNotFound
+	}
+	p := &Profile{User: usr, Permissions: []string{}}
+	if p.Groups, err = u.groups.ListByUser(ctx, usr.ID); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, g := range p.Groups {
+		if g.ID == usr.GroupID {
+			p.Group = g
+		}
+		perms, err := u.perms.GetByGroup(ctx, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, perm := range perms {
+			name := perm.Resource + "." + perm.Action
+			if !seen[name] {
+				seen[name] = true
+				p.Permissions = append(p.Permissions, name)
+			}
+		}
+	}
+	sort.Strings(p.Permissions)
+	return p, nil
+}
+
+// ChangePassword counts wrong current passwords towards the account lockout
+// so a hijacked session cannot be used to guess the password.
+func (u *profileUsecaseImpl) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, current, next, ip string) (int, error) {
+	usr, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if usr == nil {
+		return 0, ErrProfileNotFound
+	}
+	if !isLocal(usr) || usr.Password == "" {
+		return 0, ErrExternalPassword
+	}
+	if u.lockout != nil {
+		if err := u.lockout.Check(ctx, usr.Username, ip); err != nil {
+			return 0, err
+		}
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(usr.Password), []byte(current)); err != nil {
+		if u.lockout != nil {
+			u.lockout.RecordFailure(ctx, usr.Username, ip)
+		}
+		return 0, ErrWrongPassword
+	}
+	if current == next {
+		return 0, ErrPasswordUnchanged
+	}
+	if err := u.policy.Check(next); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrPasswordTooWeak, err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	usr.Password = string(hash)
+	if err := u.users.Update(ctx, usr); err != nil {
+		return 0, err
+	}
+	if u.lockout != nil {
+		u.lockout.RecordSuccess(ctx, usr.Username)
+	}
+	u.auditUser(ctx, usr, "auth.password_change", ip)
+
+	revoked, err := u.sessions.DeactivateByUser(ctx, usr.ID, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":  usr.ID,
+		"revoked": revoked,
+	}).Info("password changed")
+	return revoked, nil
+}
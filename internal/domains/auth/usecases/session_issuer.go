@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	portalentities "system-portal/internal/domains/portal/entities"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/jwt"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
+)
+
+// sessionIssuer mints token pairs and records the sessions that back them.
+// It is shared by every login flow so they all end in the same session.
+type sessionIssuer struct {
+	sessions    repositories.SessionRepository
+	memberships portalrepos.GroupRepository
+	jwt         *jwt.RSAService
+}
+
+// membership describes the groups a session is issued for. role is the
+// primary group's name.
+type membership struct {
+	role        string
+	groups      []string
+	version     int
+	mfaRequired bool
+}
+
+// membershipOf resolves the user's groups. A failed lookup yields no groups,
+// so the session grants nothing rather than failing the login.
+func (u sessionIssuer) membershipOf(ctx context.Context, usr *portalentities.PortalUser) membership {
+	m := membership{groups: []string{}, version: usr.GroupsVersion}
+	groups, err := u.memberships.ListByUser(ctx, usr.ID)
+	if err != nil {
+		logger.Log.WithError(err).Warn("failed to fetch user groups")
+		return m
+	}
+	for _, g := range groups {
+		if g.ID == usr.GroupID {
+			m.role = g.Name
+		}
+		m.groups = append(m.groups, g.Name)
+		m.mfaRequired = m.mfaRequired || g.MFARequired
+	}
+	return m
+}
+
+func (u sessionIssuer) startSession(ctx context.Context, usr *portalentities.PortalUser, m membership, ip, userAgent string) (*LoginResult, error) {
+	s, access, refresh, err := u.newSession(usr, m, uuid.Nil, ip, userAgent)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to issue tokens")
+		return nil, err
+	}
+	// tokens are useless without a session row since the middleware checks it
+	if err := u.sessions.Create(ctx, s); err != nil {
+		logger.Log.WithError(err).Error("failed to create session")
+		return nil, err
+	}
+	logger.Log.WithField("username", usr.Username).Info("session created")
+	return &LoginResult{AccessToken: access, RefreshToken: refresh, UserID: usr.ID, Role: m.role, Groups: m.groups}, nil
+}
+
+// newSession issues a token pair and the session that tracks it. A nil
+// familyID starts a new family.
+func (u sessionIssuer) newSession(usr *portalentities.PortalUser, m membership, familyID uuid.UUID, ip, userAgent string) (*entities.Session, string, string, error) {
+	access, err := u.jwt.GenerateAccessToken(usr.Username, m.role, m.groups, m.version)
+	if err != nil {
+		return nil, "", "", err
+	}
+	refresh, err := u.jwt.GenerateRefreshToken(usr.Username, m.role)
+	if err != nil {
+		return nil, "", "", err
+	}
+	now := time.Now()
+	s := &entities.Session{
+		ID:               uuid.New(),
+		UserID:           usr.ID,
+		FamilyID:         familyID,
+		TokenHash:        utils.HashString(access),
+		RefreshTokenHash: utils.HashString(refresh),
+		ExpiresAt:        now.Add(u.jwt.AccessTokenTTL()),
+		RefreshExpiresAt: now.Add(u.jwt.RefreshTokenTTL()),
+		IsActive:         true,
+		CreatedAt:        now,
+		LastActivity:     now,
+		IPAddress:        ip,
+		UserAgent:        userAgent,
+		GroupsVersion:    m.version,
+	}
+	if s.FamilyID == uuid.Nil {
+		s.FamilyID = s.ID
+	}
+	return s, access, refresh, nil
+}
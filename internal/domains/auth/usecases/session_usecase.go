@@ -0,0 +1,15 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+)
+
+// SessionUsecase lets users and administrators inspect and revoke sessions.
+type SessionUsecase interface {
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID, exceptID uuid.UUID) (int, error)
+}
@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/auth/entities"
+	"system-portal/internal/domains/auth/repositories"
+	"system-portal/pkg/logger"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type sessionUsecaseImpl struct {
+	sessions repositories.SessionRepository
+}
+
+func NewSessionUsecase(sessionRepo repositories.SessionRepository) SessionUsecase {
+	return &sessionUsecaseImpl{sessions: sessionRepo}
+}
+
+func (u *sessionUsecaseImpl) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	return u.sessions.ListActiveByUser(ctx, userID)
+}
+
+// RevokeUserSession revokes a single session after checking it belongs to
+// the given user, so users cannot revoke each other's sessions by ID.
+func (u *sessionUsecaseImpl) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	sess, err := u.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if sess == nil || sess.UserID != userID {
+		return ErrSessionNotFound
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":    userID,
+		"sessionID": sessionID,
+	}).Info("revoking session")
+	return u.sessions.Deactivate(ctx, sessionID)
+}
+
+func (u *sessionUsecaseImpl) RevokeUserSessions(ctx context.Context, userID, exceptID uuid.UUID) (int, error) {
+	n, err := u.sessions.DeactivateByUser(ctx, userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	logger.Log.WithFields(map[string]interface{}{
+		"userID":  userID,
+		"revoked": n,
+	}).Info("revoked user sessions")
+	return n, nil
+}
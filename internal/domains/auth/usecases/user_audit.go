@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	portalentities "system-portal/internal/domains/portal/entities"
+	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+// userAuditor records account changes made while logging in, such as users
+// provisioned or re-grouped from an external identity source.
+type userAuditor struct {
+	audit  portalrepos.AuditRepository
+	groups portalrepos.GroupRepository
+}
+
+func (a userAuditor) auditUser(ctx context.Context, usr *portalentities.PortalUser, action, ip string) {
+	if a.audit == nil {
+		return
+	}
+	entry := &portalentities.AuditLog{
+		ID:           uuid.New(),
+		UserID:       usr.ID,
+		Username:     usr.Username,
+		Action:       action,
+		ResourceType: "user",
+		ResourceName: usr.Username,
+		IPAddress:    ip,
+		Success:      true,
+		CreatedAt:    time.Now(),
+	}
+	if g, err := a.groups.GetByID(ctx, usr.GroupID); err == nil && g != nil {
+		entry.UserGroup = g.Name
+	}
+	if err := a.audit.Add(ctx, entry); err != nil {
+		logger.Log.WithError(err).WithField("action", action).Warn("failed to audit user change")
+	}
+}
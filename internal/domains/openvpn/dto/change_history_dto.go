@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/openvpn/entities"
+)
+
+// VpnChangeEntryResponse is one change in a user or group timeline. Before
+// is omitted for creations and After for deletions.
+type VpnChangeEntryResponse struct {
+	ID        uuid.UUID              `json:"id"`
+	Operation string                 `json:"operation"`
+	GroupName string                 `json:"groupName"`
+	Changes   []entities.FieldChange `json:"changes"`
+	Before    json.RawMessage        `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage        `json:"after,omitempty" swaggertype:"object"`
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// VpnChangeHistoryResponse is a page of a user or group timeline.
+type VpnChangeHistoryResponse struct {
+	EntityType string                   `json:"entityType"`
+	EntityName string                   `json:"entityName"`
+	Changes    []VpnChangeEntryResponse `json:"changes"`
+	Total      int                      `json:"total"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+}
+
+func NewVpnChangeEntryResponse(e *entities.ChangeEntry) VpnChangeEntryResponse {
+	return VpnChangeEntryResponse{
+		ID:        e.ID,
+		Operation: e.Operation,
+		GroupName: e.GroupName,
+		Changes:   e.Changes,
+		Before:    e.Before,
+		After:     e.After,
+		Actor:     e.Actor,
+		RequestID: e.RequestID,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types of a ChangeEntry.
+const (
+	ChangeEntityUser  = "user"
+	ChangeEntityGroup = "group"
+)
+
+// ChangeEntry is one change to an OpenVPN user or group. Before is nil for
+// a creation and After is nil for a deletion. GroupName is the VPN group the
+// entity belonged to, used to scope timelines.
+type ChangeEntry struct {
+	ID         uuid.UUID
+	EntityType string
+	EntityName string
+	GroupName  string
+	Operation  string
+	Before     json.RawMessage
+	After      json.RawMessage
+	Changes    []FieldChange
+	Actor      string
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// FieldChange is a property whose value differs between two snapshots.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ChangeHistoryFilter selects the timeline of one entity, newest first.
+type ChangeHistoryFilter struct {
+	EntityType string
+	EntityName string
+	// Set by usecases from the caller's permission scope
+	GroupScope GroupScope
+	Page       int
+	Limit      int
+	Offset     int
+}
+
+func (f *ChangeHistoryFilter) SetDefaults() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 || f.Limit > 100 {
+		f.Limit = 20
+	}
+	f.Offset = (f.Page - 1) * f.Limit
+}
+
+// DiffSnapshots lists the top-level JSON properties that differ between two
+// snapshots, sorted by name. Either snapshot may be nil.
+func DiffSnapshots(before, after json.RawMessage) []FieldChange {
+	b, a := snapshotFields(before), snapshotFields(after)
+	names := make(map[string]struct{}, len(b)+len(a))
+	for k := range b {
+		names[k] = struct{}{}
+	}
+	for k := range a {
+		names[k] = struct{}{}
+	}
+	changes := []FieldChange{}
+	for k := range names {
+		if !reflect.DeepEqual(b[k], a[k]) {
+			changes = append(changes, FieldChange{Field: k, Before: b[k], After: a[k]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func snapshotFields(raw json.RawMessage) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &fields)
+	}
+	return fields
+}
+
+// ChangeActor identifies who made a change and the request it came from.
+type ChangeActor struct {
+	Username  string
+	RequestID string
+}
+
+type changeActorKey struct{}
+
+// WithChangeActor returns a context whose changes are attributed to actor.
+func WithChangeActor(ctx context.Context, actor ChangeActor) context.Context {
+	return context.WithValue(ctx, changeActorKey{}, actor)
+}
+
+// ChangeActorFrom returns the actor attached to ctx, if any.
+func ChangeActorFrom(ctx context.Context) ChangeActor {
+	actor, _ := ctx.Value(changeActorKey{}).(ChangeActor)
+	return actor
+}
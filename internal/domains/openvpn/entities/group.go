@@ -16,6 +16,7 @@ type VpnGroupFilter struct {
 	AuthMethod string
 	IsEnabled  *bool
 	Role       string
+	GroupScope GroupScope
 	Limit      int
 	Offset     int
 	Page       int
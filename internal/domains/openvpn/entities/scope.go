@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// DefaultGroupName is the group OpenVPN AS assigns to users without one.
+const DefaultGroupName = "__DEFAULT__"
+
+// GroupScope limits a caller to the OpenVPN groups matching one of its
+// entries. Entries are group names or path.Match patterns such as "eng-*",
+// compared case-insensitively. An empty scope is unrestricted.
+type GroupScope []string
+
+// Unrestricted reports whether the scope allows every group.
+func (s GroupScope) Unrestricted() bool { return len(s) == 0 }
+
+// AllowsGroup reports whether the scope covers the named group. Users
+// without a group belong to DefaultGroupName.
+func (s GroupScope) AllowsGroup(name string) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	if name == "" {
+		name = DefaultGroupName
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range s {
+		if ok, err := path.Match(strings.ToLower(pattern), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+type groupScopeKey struct{}
+
+// WithGroupScope returns a context restricted to scope.
+func WithGroupScope(ctx context.Context, scope GroupScope) context.Context {
+	return context.WithValue(ctx, groupScopeKey{}, scope)
+}
+
+// GroupScopeFrom returns the scope attached to ctx, if any.
+func GroupScopeFrom(ctx context.Context) GroupScope {
+	scope, _ := ctx.Value(groupScopeKey{}).(GroupScope)
+	return scope
+}
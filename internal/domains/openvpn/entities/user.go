@@ -1,6 +1,9 @@
 package entities
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type VpnUser struct {
 	Username       string   `json:"username"`
@@ -43,6 +46,9 @@ type VpnUserFilter struct {
 	SearchText       string `json:"searchText" form:"searchText"`             // Search across username, email
 	IPAddress        string `json:"ipAddress" form:"ipAddress"`
 
+	// Set by usecases from the caller's permission scope, never bound from requests
+	GroupScope GroupScope `json:"-" form:"-"`
+
 	// Sorting & pagination
 	SortBy    string `json:"sortBy" form:"sortBy" binding:"omitempty,oneof=username email authMethod role groupName userExpiration"`
 	SortOrder string `json:"sortOrder" form:"sortOrder" binding:"omitempty,oneof=asc desc"`
@@ -150,6 +156,24 @@ func NewVpnUser(username, email, authMethod, groupName string) *VpnUser {
 	}
 }
 
+// userExpirationFormats are the date layouts OpenVPN AS expirations come in.
+var userExpirationFormats = []string{
+	"02/01/2006", // DD/MM/YYYY (based on DTO examples)
+	"2006-01-02", // YYYY-MM-DD (ISO format)
+	"01/02/2006", // MM/DD/YYYY (US format)
+	"2006/01/02", // YYYY/MM/DD
+}
+
+// ParseUserExpiration parses an expiration date in any supported layout.
+func ParseUserExpiration(s string) (time.Time, error) {
+	for _, format := range userExpirationFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", s)
+}
+
 // Backward compatibility aliases
 type User = VpnUser
 type UserFilter = VpnUserFilter
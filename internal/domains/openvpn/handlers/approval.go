@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	dto "system-portal/internal/domains/openvpn/dto"
+	"system-portal/pkg/logger"
+)
+
+// Operations that may be held for approval. Their executors below apply an
+// approved request's payload the way the corresponding handler would.
+const (
+	OpDeleteUser       = "openvpn.delete_user"
+	OpDeleteGroup      = "openvpn.delete_group"
+	OpBulkUserActions  = "openvpn.bulk_user_actions"
+	OpBulkGroupActions = "openvpn.bulk_group_actions"
+	permDeleteUsers    = "openvpn.delete_users"
+	permEditUsers      = "openvpn.edit_users"
+	permManageGroups   = "openvpn.manage_groups"
+	bulkActionDisable  = "disable"
+)
+
+// maxBulkTargetLen keeps a bulk request's target, which is also written to
+// the audit log, well within its column. The full list is in the payload.
+const maxBulkTargetLen = 100
+
+// bulkTarget summarises names as e.g. "42 users: alice, bob, …".
+func bulkTarget(noun string, names []string) string {
+	target := fmt.Sprintf("%d %s", len(names), noun)
+	for i, name := range names {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		if len(target)+len(sep)+len(name) > maxBulkTargetLen-len(", …") {
+			return target + sep + "…"
+		}
+		target += sep + name
+	}
+	return target
+}
+
+// ApprovalGate holds an operation for a second user's approval. Hold
+// reports whether it did, in which case it has already responded.
+type ApprovalGate interface {
+	Hold(c *gin.Context, perm, operation, target string, payload interface{}) bool
+}
+
+type deleteUserChange struct {
+	Username string `json:"username"`
+}
+
+type deleteGroupChange struct {
+	GroupName string `json:"groupName"`
+}
+
+// ApplyDeleteUser deletes the user of an approved request.
+func (h *UserHandler) ApplyDeleteUser(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var change deleteUserChange
+	if err := json.Unmarshal(payload, &change); err != nil {
+		return nil, err
+	}
+	if err := h.userUsecase.DeleteUser(ctx, change.Username); err != nil {
+		return nil, err
+	}
+	if err := h.xmlrpcClient.RunStart(); err != nil {
+		logger.Log.WithError(err).Error("Failed to restart OpenVPN service after user deletion")
+	}
+	return nil, nil
+}
+
+// ApplyDeleteGroup deletes the group of an approved request.
+func (h *GroupHandler) ApplyDeleteGroup(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var change deleteGroupChange
+	if err := json.Unmarshal(payload, &change); err != nil {
+		return nil, err
+	}
+	return nil, h.groupUsecase.DeleteGroup(ctx, change.GroupName)
+}
+
+// ApplyBulkUserActions runs the bulk user action of an approved request and
+// returns the per-user results.
+func (h *BulkHandler) ApplyBulkUserActions(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req dto.VpnBulkUserActionsRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	response, err := h.bulkUsecase.BulkUserActions(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if response.Success > 0 {
+		if err := h.xmlrpcClient.RunStart(); err != nil {
+			logger.Log.WithError(err).Error("Failed to restart OpenVPN service after bulk user actions")
+		}
+	}
+	return response, nil
+}
+
+// ApplyBulkGroupActions runs the bulk group action of an approved request
+// and returns the per-group results.
+func (h *BulkHandler) ApplyBulkGroupActions(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var req dto.VpnBulkGroupActionsRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	response, err := h.bulkUsecase.BulkGroupActions(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if response.Success > 0 {
+		if err := h.xmlrpcClient.RunStart(); err != nil {
+			logger.Log.WithError(err).Error("Failed to restart OpenVPN service after bulk group actions")
+		}
+	}
+	return response, nil
+}
@@ -17,12 +17,14 @@ import (
 type BulkHandler struct {
 	bulkUsecase  usecases.BulkUsecase
 	xmlrpcClient *xmlrpc.Client
+	approvals    ApprovalGate
 }
 
-func NewBulkHandler(bulkUsecase usecases.BulkUsecase, xmlrpcClient *xmlrpc.Client) *BulkHandler {
+func NewBulkHandler(bulkUsecase usecases.BulkUsecase, xmlrpcClient *xmlrpc.Client, approvals ApprovalGate) *BulkHandler {
 	return &BulkHandler{
 		bulkUsecase:  bulkUsecase,
 		xmlrpcClient: xmlrpcClient,
+		approvals:    approvals,
 	}
 }
 
@@ -112,6 +114,12 @@ func (h *BulkHandler) BulkUserActions(c *gin.Context) {
 		WithField("action", req.Action).
 		Info("Processing bulk user actions")
 
+	// Disabling users in bulk is held for approval when configured
+	if req.Action == bulkActionDisable && h.approvals != nil &&
+		h.approvals.Hold(c, permEditUsers, OpBulkUserActions, bulkTarget("users", req.Usernames), req) {
+		return
+	}
+
 	// Process bulk actions
 	response, err := h.bulkUsecase.BulkUserActions(c.Request.Context(), &req)
 	if err != nil {
@@ -359,6 +367,12 @@ func (h *BulkHandler) BulkGroupActions(c *gin.Context) {
 		WithField("action", req.Action).
 		Info("Processing bulk group actions")
 
+	// Disabling groups in bulk is held for approval when configured
+	if req.Action == bulkActionDisable && h.approvals != nil &&
+		h.approvals.Hold(c, permManageGroups, OpBulkGroupActions, bulkTarget("groups", req.GroupNames), req) {
+		return
+	}
+
 	// Process bulk actions
 	response, err := h.bulkUsecase.BulkGroupActions(c.Request.Context(), &req)
 	if err != nil {
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	nethttp "net/http"
+	"strconv"
+
+	dto "system-portal/internal/domains/openvpn/dto"
+	"system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/openvpn/usecases"
+	"system-portal/internal/shared/errors"
+	http "system-portal/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangeHistoryHandler struct {
+	historyUsecase usecases.ChangeHistoryUsecase
+}
+
+func NewChangeHistoryHandler(historyUsecase usecases.ChangeHistoryUsecase) *ChangeHistoryHandler {
+	return &ChangeHistoryHandler{historyUsecase: historyUsecase}
+}
+
+// GetUserHistory godoc
+// @Summary Get user change history
+// @Description Timeline of changes to a VPN user with before/after snapshots and the changed fields, newest first
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=dto.VpnChangeHistoryResponse}
+// @Router /api/openvpn/users/{username}/history [get]
+func (h *ChangeHistoryHandler) GetUserHistory(c *gin.Context) {
+	h.respond(c, entities.ChangeEntityUser, c.Param("username"), h.historyUsecase.UserTimeline)
+}
+
+// GetGroupHistory godoc
+// @Summary Get group change history
+// @Description Timeline of changes to a VPN group with before/after snapshots and the changed fields, newest first
+// @Tags Groups
+// @Security BearerAuth
+// @Produce json
+// @Param groupName path string true "Group name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=dto.VpnChangeHistoryResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/openvpn/groups/{groupName}/history [get]
+func (h *ChangeHistoryHandler) GetGroupHistory(c *gin.Context) {
+	h.respond(c, entities.ChangeEntityGroup, c.Param("groupName"), h.historyUsecase.GroupTimeline)
+}
+
+type timelineFunc func(ctx context.Context, name string, page, limit int) ([]*entities.ChangeEntry, int, error)
+
+func (h *ChangeHistoryHandler) respond(c *gin.Context, entityType, name string, timeline timelineFunc) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := entities.ChangeHistoryFilter{Page: page, Limit: limit}
+	filter.SetDefaults()
+
+	items, total, err := timeline(c.Request.Context(), name, filter.Page, filter.Limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			http.RespondWithError(c, appErr)
+		} else {
+			http.RespondWithError(c, errors.InternalServerError("Failed to get change history", err))
+		}
+		return
+	}
+	changes := make([]dto.VpnChangeEntryResponse, 0, len(items))
+	for _, e := range items {
+		changes = append(changes, dto.NewVpnChangeEntryResponse(e))
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.VpnChangeHistoryResponse{
+		EntityType: entityType,
+		EntityName: name,
+		Changes:    changes,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+	})
+}
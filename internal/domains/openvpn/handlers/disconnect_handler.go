@@ -142,6 +142,9 @@ func (h *DisconnectHandler) DisconnectUser(c *gin.Context) {
 			if strings.Contains(result.Error, "not found in system") {
 				http.RespondWithError(c, errors.NotFound(result.Error, err))
 				return
+			} else if strings.Contains(result.Error, "permission scope") {
+				http.RespondWithError(c, errors.Forbidden(result.Error, err))
+				return
 			} else if strings.Contains(result.Error, "not currently connected") {
 				http.RespondWithError(c, errors.BadRequest(result.Error, err))
 				return
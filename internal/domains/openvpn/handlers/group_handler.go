@@ -20,13 +20,15 @@ type GroupHandler struct {
 	groupUsecase  usecases.GroupUsecase
 	configUsecase usecases.ConfigUsecase
 	xmlrpcClient  *xmlrpc.Client
+	approvals     ApprovalGate
 }
 
-func NewGroupHandler(groupUsecase usecases.GroupUsecase, configUsecase usecases.ConfigUsecase, xmlrpcClient *xmlrpc.Client) *GroupHandler {
+func NewGroupHandler(groupUsecase usecases.GroupUsecase, configUsecase usecases.ConfigUsecase, xmlrpcClient *xmlrpc.Client, approvals ApprovalGate) *GroupHandler {
 	return &GroupHandler{
 		groupUsecase:  groupUsecase,
 		configUsecase: configUsecase,
 		xmlrpcClient:  xmlrpcClient,
+		approvals:     approvals,
 	}
 }
 
@@ -270,6 +272,10 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	if h.approvals != nil && h.approvals.Hold(c, permManageGroups, OpDeleteGroup, groupName, deleteGroupChange{GroupName: groupName}) {
+		return
+	}
+
 	if err := h.groupUsecase.DeleteGroup(c.Request.Context(), groupName); err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			http.RespondWithError(c, appErr)
@@ -23,12 +23,14 @@ import (
 type UserHandler struct {
 	userUsecase  usecases.UserUsecase
 	xmlrpcClient *xmlrpc.Client
+	approvals    ApprovalGate
 }
 
-func NewUserHandler(userUsecase usecases.UserUsecase, xmlrpcClient *xmlrpc.Client) *UserHandler {
+func NewUserHandler(userUsecase usecases.UserUsecase, xmlrpcClient *xmlrpc.Client, approvals ApprovalGate) *UserHandler {
 	return &UserHandler{
 		userUsecase:  userUsecase,
 		xmlrpcClient: xmlrpcClient,
+		approvals:    approvals,
 	}
 }
 
@@ -241,6 +243,10 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	if h.approvals != nil && h.approvals.Hold(c, permDeleteUsers, OpDeleteUser, username, deleteUserChange{Username: username}) {
+		return
+	}
+
 	if err := h.userUsecase.DeleteUser(c.Request.Context(), username); err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			http.RespondWithError(c, appErr)
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"system-portal/internal/domains/openvpn/entities"
+)
+
+// ChangeHistoryRepository stores the before/after history of VPN users and
+// groups.
+type ChangeHistoryRepository interface {
+	Add(ctx context.Context, entry *entities.ChangeEntry) error
+	List(ctx context.Context, filter *entities.ChangeHistoryFilter) ([]*entities.ChangeEntry, int, error)
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/openvpn/repositories"
+)
+
+type pgChangeHistoryRepo struct{ db *sql.DB }
+
+func NewChangeHistoryRepository(db *sql.DB) repositories.ChangeHistoryRepository {
+	return &pgChangeHistoryRepo{db: db}
+}
+
+func (r *pgChangeHistoryRepo) Add(ctx context.Context, e *entities.ChangeEntry) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO vpn_change_history (
+                       id, entity_type, entity_name, group_name, operation,
+                       before_state, after_state, changes, actor, request_id, created_at)
+               VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NULLIF($9, ''),NULLIF($10, ''),$11)`,
+		e.ID, e.EntityType, e.EntityName, e.GroupName, e.Operation,
+		nullJSON(e.Before), nullJSON(e.After), string(changes), e.Actor, e.RequestID, e.CreatedAt,
+	)
+	return err
+}
+
+func (r *pgChangeHistoryRepo) List(ctx context.Context, f *entities.ChangeHistoryFilter) ([]*entities.ChangeEntry, int, error) {
+	f.SetDefaults()
+	clauses := []string{"entity_type=$1", "entity_name=$2"}
+	args := []interface{}{f.EntityType, f.EntityName}
+	if !f.GroupScope.Unrestricted() {
+		patterns := make([]string, 0, len(f.GroupScope))
+		for _, p := range f.GroupScope {
+			patterns = append(patterns, likePattern(p))
+		}
+		clauses = append(clauses, "LOWER(group_name) LIKE ANY($"+strconv.Itoa(len(args)+1)+")")
+		args = append(args, pq.Array(patterns))
+	}
+	where := " WHERE " + strings.Join(clauses, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM vpn_change_history`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, entity_type, entity_name, group_name, operation, before_state, after_state,
+                        changes, COALESCE(actor, ''), COALESCE(request_id, ''), created_at
+                 FROM vpn_change_history`+where+` ORDER BY created_at DESC`+
+			fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit, f.Offset), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var entries []*entities.ChangeEntry
+	for rows.Next() {
+		var e entities.ChangeEntry
+		var before, after, changes []byte
+		if err := rows.Scan(&e.ID, &e.EntityType, &e.EntityName, &e.GroupName, &e.Operation,
+			&before, &after, &changes, &e.Actor, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if len(before) > 0 {
+			e.Before = json.RawMessage(before)
+		}
+		if len(after) > 0 {
+			e.After = json.RawMessage(after)
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, total, rows.Err()
+}
+
+func nullJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// likePattern turns a lower-cased group scope pattern into a LIKE pattern,
+// mapping the path.Match wildcards * and ? to % and _.
+func likePattern(pattern string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(pattern) {
+		switch r {
+		case '%', '_', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '*':
+			b.WriteRune('%')
+		case '?':
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -235,6 +235,11 @@ func (r *groupRepositoryImpl) applyFilters(groups []*entities.Group, filter *ent
 			continue
 		}
 
+		// Apply permission scope
+		if !filter.GroupScope.AllowsGroup(group.GroupName) {
+			continue
+		}
+
 		filtered = append(filtered, group)
 	}
 
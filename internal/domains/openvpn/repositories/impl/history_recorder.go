@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/openvpn/repositories"
+	"system-portal/pkg/logger"
+)
+
+// heldSnapshotTTL bounds how long a snapshot taken when clearing properties
+// waits for the write that follows it.
+const heldSnapshotTTL = time.Minute
+
+// historyRecorder snapshots an entity around each change and stores the
+// difference. Updates clear properties before writing new ones, so the
+// snapshot taken when clearing is kept as the "before" of the next write of
+// the same request.
+type historyRecorder struct {
+	history    repositories.ChangeHistoryRepository
+	entityType string
+	mu         sync.Mutex
+	pending    map[string]heldSnapshot
+}
+
+// heldSnapshot is a "before" waiting for its write. A clear that is not
+// followed by a write leaves it behind, so it only applies to the request
+// that took it and only for heldSnapshotTTL.
+type heldSnapshot struct {
+	before    json.RawMessage
+	requestID string
+	at        time.Time
+}
+
+func (h *historyRecorder) hold(ctx context.Context, name string, before json.RawMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	for k, held := range h.pending {
+		if now.Sub(held.at) > heldSnapshotTTL {
+			delete(h.pending, k)
+		}
+	}
+	h.pending[strings.ToLower(name)] = heldSnapshot{
+		before:    before,
+		requestID: entities.ChangeActorFrom(ctx).RequestID,
+		at:        now,
+	}
+}
+
+func (h *historyRecorder) take(ctx context.Context, name string) (json.RawMessage, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	held, ok := h.pending[strings.ToLower(name)]
+	delete(h.pending, strings.ToLower(name))
+	if !ok || held.requestID != entities.ChangeActorFrom(ctx).RequestID || time.Since(held.at) > heldSnapshotTTL {
+		return nil, false
+	}
+	return held.before, true
+}
+
+// record stores a change. Failures are logged and never fail the change
+// itself, which has already been applied on the VPN server.
+func (h *historyRecorder) record(ctx context.Context, name, group, operation string, before, after json.RawMessage) {
+	changes := entities.DiffSnapshots(before, after)
+	if operation == "update" && len(changes) == 0 {
+		return
+	}
+	if group == "" {
+		group = entities.DefaultGroupName
+	}
+	actor := entities.ChangeActorFrom(ctx)
+	entry := &entities.ChangeEntry{
+		ID:         uuid.New(),
+		EntityType: h.entityType,
+		EntityName: name,
+		GroupName:  group,
+		Operation:  operation,
+		Before:     before,
+		After:      after,
+		Changes:    changes,
+		Actor:      actor.Username,
+		RequestID:  actor.RequestID,
+		CreatedAt:  time.Now(),
+	}
+	if err := h.history.Add(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Log.WithError(err).WithField(h.entityType, name).Warn("failed to record change history")
+	}
+}
+
+type historyUserRepository struct {
+	repositories.UserRepository
+	rec *historyRecorder
+}
+
+// NewHistoryUserRepository records the before and after state of every
+// change made through users.
+func NewHistoryUserRepository(users repositories.UserRepository, history repositories.ChangeHistoryRepository) repositories.UserRepository {
+	return &historyUserRepository{
+		UserRepository: users,
+		rec:            &historyRecorder{history: history, entityType: entities.ChangeEntityUser, pending: map[string]heldSnapshot{}},
+	}
+}
+
+// snapshot returns the current state of a user without its password, or
+// nil when it cannot be read.
+func (r *historyUserRepository) snapshot(ctx context.Context, username string) (json.RawMessage, string) {
+	u, err := r.UserRepository.GetByUsername(ctx, username)
+	if err != nil || u == nil {
+		return nil, ""
+	}
+	clean := *u
+	clean.Password = ""
+	raw, err := json.Marshal(clean)
+	if err != nil {
+		return nil, ""
+	}
+	return raw, u.GroupName
+}
+
+func (r *historyUserRepository) change(ctx context.Context, username, operation string, apply func() error) error {
+	before, group := r.snapshot(ctx, username)
+	if err := apply(); err != nil {
+		return err
+	}
+	after, afterGroup := r.snapshot(ctx, username)
+	if afterGroup != "" {
+		group = afterGroup
+	}
+	r.rec.record(ctx, username, group, operation, before, after)
+	return nil
+}
+
+func (r *historyUserRepository) Create(ctx context.Context, user *entities.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	after, group := r.snapshot(ctx, user.Username)
+	if group == "" {
+		group = user.GroupName
+	}
+	r.rec.record(ctx, user.Username, group, "create", nil, after)
+	return nil
+}
+
+func (r *historyUserRepository) UserPropDel(ctx context.Context, user *entities.User) error {
+	before, _ := r.snapshot(ctx, user.Username)
+	if err := r.UserRepository.UserPropDel(ctx, user); err != nil {
+		return err
+	}
+	r.rec.hold(ctx, user.Username, before)
+	return nil
+}
+
+func (r *historyUserRepository) Update(ctx context.Context, user *entities.User) error {
+	before, held := r.rec.take(ctx, user.Username)
+	if !held {
+		before, _ = r.snapshot(ctx, user.Username)
+	}
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	after, group := r.snapshot(ctx, user.Username)
+	r.rec.record(ctx, user.Username, group, "update", before, after)
+	return nil
+}
+
+func (r *historyUserRepository) Delete(ctx context.Context, username string) error {
+	before, group := r.snapshot(ctx, username)
+	if err := r.UserRepository.Delete(ctx, username); err != nil {
+		return err
+	}
+	r.rec.record(ctx, username, group, "delete", before, nil)
+	return nil
+}
+
+func (r *historyUserRepository) Enable(ctx context.Context, username string) error {
+	return r.change(ctx, username, "enable", func() error { return r.UserRepository.Enable(ctx, username) })
+}
+
+func (r *historyUserRepository) Disable(ctx context.Context, username string) error {
+	return r.change(ctx, username, "disable", func() error { return r.UserRepository.Disable(ctx, username) })
+}
+
+func (r *historyUserRepository) SetPassword(ctx context.Context, username, password string) error {
+	return r.change(ctx, username, "set_password", func() error { return r.UserRepository.SetPassword(ctx, username, password) })
+}
+
+func (r *historyUserRepository) RegenerateTOTP(ctx context.Context, username string) error {
+	return r.change(ctx, username, "regenerate_totp", func() error { return r.UserRepository.RegenerateTOTP(ctx, username) })
+}
+
+type historyGroupRepository struct {
+	repositories.GroupRepository
+	rec *historyRecorder
+}
+
+// NewHistoryGroupRepository records the before and after state of every
+// change made through groups.
+func NewHistoryGroupRepository(groups repositories.GroupRepository, history repositories.ChangeHistoryRepository) repositories.GroupRepository {
+	return &historyGroupRepository{
+		GroupRepository: groups,
+		rec:             &historyRecorder{history: history, entityType: entities.ChangeEntityGroup, pending: map[string]heldSnapshot{}},
+	}
+}
+
+func (r *historyGroupRepository) snapshot(ctx context.Context, groupName string) json.RawMessage {
+	g, err := r.GroupRepository.GetByName(ctx, groupName)
+	if err != nil || g == nil {
+		return nil
+	}
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+func (r *historyGroupRepository) change(ctx context.Context, groupName, operation string, apply func() error) error {
+	before := r.snapshot(ctx, groupName)
+	if err := apply(); err != nil {
+		return err
+	}
+	r.rec.record(ctx, groupName, groupName, operation, before, r.snapshot(ctx, groupName))
+	return nil
+}
+
+func (r *historyGroupRepository) Create(ctx context.Context, group *entities.Group) error {
+	if err := r.GroupRepository.Create(ctx, group); err != nil {
+		return err
+	}
+	r.rec.record(ctx, group.GroupName, group.GroupName, "create", nil, r.snapshot(ctx, group.GroupName))
+	return nil
+}
+
+func (r *historyGroupRepository) GroupPropDel(ctx context.Context, group *entities.Group) error {
+	before := r.snapshot(ctx, group.GroupName)
+	if err := r.GroupRepository.GroupPropDel(ctx, group); err != nil {
+		return err
+	}
+	r.rec.hold(ctx, group.GroupName, before)
+	return nil
+}
+
+func (r *historyGroupRepository) Update(ctx context.Context, group *entities.Group) error {
+	before, held := r.rec.take(ctx, group.GroupName)
+	if !held {
+		before = r.snapshot(ctx, group.GroupName)
+	}
+	if err := r.GroupRepository.Update(ctx, group); err != nil {
+		return err
+	}
+	r.rec.record(ctx, group.GroupName, group.GroupName, "update", before, r.snapshot(ctx, group.GroupName))
+	return nil
+}
+
+func (r *historyGroupRepository) Delete(ctx context.Context, groupName string) error {
+	before := r.snapshot(ctx, groupName)
+	if err := r.GroupRepository.Delete(ctx, groupName); err != nil {
+		return err
+	}
+	r.rec.record(ctx, groupName, groupName, "delete", before, nil)
+	return nil
+}
+
+func (r *historyGroupRepository) Enable(ctx context.Context, groupName string) error {
+	return r.change(ctx, groupName, "enable", func() error { return r.GroupRepository.Enable(ctx, groupName) })
+}
+
+func (r *historyGroupRepository) Disable(ctx context.Context, groupName string) error {
+	return r.change(ctx, groupName, "disable", func() error { return r.GroupRepository.Disable(ctx, groupName) })
+}
+
+func (r *historyGroupRepository) ClearAccessControl(ctx context.Context, group *entities.Group) error {
+	return r.change(ctx, group.GroupName, "clear_access_control", func() error {
+		return r.GroupRepository.ClearAccessControl(ctx, group)
+	})
+}
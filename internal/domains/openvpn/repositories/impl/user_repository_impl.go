@@ -208,6 +208,10 @@ func (r *userRepositoryImpl) matchesFilter(user *entities.User, filter *entities
 		return false
 	}
 
+	if !filter.GroupScope.AllowsGroup(user.GroupName) {
+		return false
+	}
+
 	// NEW: Status filters
 	if filter.IsEnabled != nil {
 		userEnabled := user.DenyAccess != "true"
@@ -337,21 +341,7 @@ func (r *userRepositoryImpl) matchesFilter(user *entities.User, filter *entities
 
 // NEW: Helper method to parse expiration date
 func (r *userRepositoryImpl) parseExpirationDate(dateStr string) (time.Time, error) {
-	// Try different date formats
-	formats := []string{
-		"02/01/2006", // DD/MM/YYYY (based on DTO examples)
-		"2006-01-02", // YYYY-MM-DD (ISO format)
-		"01/02/2006", // MM/DD/YYYY (US format)
-		"2006/01/02", // YYYY/MM/DD
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t, nil
-		}
-	}
-
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+	return entities.ParseUserExpiration(dateStr)
 }
 
 // NEW: Sorting functionality
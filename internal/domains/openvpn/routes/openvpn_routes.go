@@ -18,6 +18,7 @@ var (
 	configHandler     *handlers.ConfigHandler
 	vpnStatusHandler  *handlers.VPNStatusHandler
 	disconnectHandler *handlers.DisconnectHandler
+	historyHandler    *handlers.ChangeHistoryHandler
 	permMiddleware    *middleware.PermissionMiddleware
 	enabled           bool
 	routerGroup       *gin.RouterGroup
@@ -32,6 +33,7 @@ func Initialize(
 	cfh *handlers.ConfigHandler,
 	vsh *handlers.VPNStatusHandler,
 	dh *handlers.DisconnectHandler,
+	hh *handlers.ChangeHistoryHandler,
 	pmw *middleware.PermissionMiddleware,
 ) {
 	userHandler = uh
@@ -40,6 +42,7 @@ func Initialize(
 	configHandler = cfh
 	vpnStatusHandler = vsh
 	disconnectHandler = dh
+	historyHandler = hh
 	permMiddleware = pmw
 	enabled = true
 	if routerGroup != nil && !routesRegistered {
@@ -94,6 +97,7 @@ func registerUserRoutes(openvpn *gin.RouterGroup) {
 		users.GET("", permMiddleware.RequirePermission("openvpn.view_users"), userHandler.ListUsers)
 		users.GET("/expirations", permMiddleware.RequirePermission("openvpn.view_users"), userHandler.GetUserExpirations)
 		users.GET("/:username", permMiddleware.RequirePermission("openvpn.view_users"), userHandler.GetUser)
+		users.GET("/:username/history", permMiddleware.RequirePermission("openvpn.view_history"), historyHandler.GetUserHistory)
 
 		// Create and edit users (both admin and support)
 		users.POST("", permMiddleware.RequirePermission("openvpn.create_users"), userHandler.CreateUser)
@@ -116,6 +120,7 @@ func registerGroupRoutes(openvpn *gin.RouterGroup) {
 		// View groups (both admin and support)
 		groups.GET("", permMiddleware.RequirePermission("openvpn.view_groups"), groupHandler.ListGroups)
 		groups.GET("/:groupName", permMiddleware.RequirePermission("openvpn.view_groups"), groupHandler.GetGroup)
+		groups.GET("/:groupName/history", permMiddleware.RequirePermission("openvpn.view_history"), historyHandler.GetGroupHistory)
 
 		// Manage groups (admin only)
 		groups.POST("", permMiddleware.RequirePermission("openvpn.manage_groups"), groupHandler.CreateGroup)
@@ -235,6 +235,12 @@ func (u *bulkUsecaseImpl) createUserWorker(ctx context.Context, userChan <-chan
 			resultChan <- result
 			continue
 		}
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(userReq.GroupName) {
+			result.Success = false
+			result.Error = outOfScopeMessage
+			resultChan <- result
+			continue
+		}
 		if userReq.GroupName != "" {
 			existsGroup, err := u.groupRepo.ExistsByName(ctx, userReq.GroupName)
 			if err != nil {
@@ -363,7 +369,7 @@ func (u *bulkUsecaseImpl) BulkUserActions(ctx context.Context, req *openvpndto.B
 		}
 
 		// Check if user exists
-		_, err := u.userRepo.GetByUsername(ctx, username)
+		user, err := u.userRepo.GetByUsername(ctx, username)
 		if err != nil {
 			result.Success = false
 			result.Error = "User not found"
@@ -371,6 +377,13 @@ func (u *bulkUsecaseImpl) BulkUserActions(ctx context.Context, req *openvpndto.B
 			response.Failed++
 			continue
 		}
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(user.GroupName) {
+			result.Success = false
+			result.Error = outOfScopeMessage
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
 
 		// Perform action
 		var actionErr error
@@ -428,7 +441,7 @@ func (u *bulkUsecaseImpl) BulkExtendUsers(ctx context.Context, req *openvpndto.B
 		}
 
 		// Check if user exists
-		_, err := u.userRepo.GetByUsername(ctx, username)
+		user, err := u.userRepo.GetByUsername(ctx, username)
 		if err != nil {
 			result.Success = false
 			result.Error = "User not found"
@@ -436,14 +449,21 @@ func (u *bulkUsecaseImpl) BulkExtendUsers(ctx context.Context, req *openvpndto.B
 			response.Failed++
 			continue
 		}
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(user.GroupName) {
+			result.Success = false
+			result.Error = outOfScopeMessage
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
 
 		// Update user expiration
-		user := &entities.User{
+		update := &entities.User{
 			Username:       username,
 			UserExpiration: req.NewExpiration,
 		}
 
-		if err := u.userRepo.Update(ctx, user); err != nil {
+		if err := u.userRepo.Update(ctx, update); err != nil {
 			result.Success = false
 			result.Error = fmt.Sprintf("Failed to extend user: %v", err)
 			response.Failed++
@@ -501,6 +521,24 @@ func (u *bulkUsecaseImpl) ImportUsers(ctx context.Context, req *openvpndto.Impor
 		ValidationErrors: validationErrors,
 	}
 
+	// Rows for groups outside the caller's scope are reported, not imported
+	scope := entities.GroupScopeFrom(ctx)
+	inScope := userRequests[:0]
+	for _, userReq := range userRequests {
+		if scope.AllowsGroup(userReq.GroupName) {
+			inScope = append(inScope, userReq)
+			continue
+		}
+		response.ValidRecords--
+		response.InvalidRecords++
+		response.ValidationErrors = append(response.ValidationErrors, openvpndto.ImportValidationError{
+			Field:   "group_name",
+			Value:   userReq.Username,
+			Message: fmt.Sprintf("%s: %s", outOfScopeMessage, userReq.GroupName),
+		})
+	}
+	userRequests = inScope
+
 	// If dry run, return validation results only
 	if req.DryRun {
 		response.ProcessedRecords = 0
@@ -570,6 +608,14 @@ func (u *bulkUsecaseImpl) BulkCreateGroups(ctx context.Context, req *openvpndto.
 			continue
 		}
 
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(groupReq.GroupName) {
+			result.Success = false
+			result.Error = outOfScopeMessage
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
+
 		// Check if group already exists
 		exists, err := u.groupRepo.ExistsByName(ctx, groupReq.GroupName)
 		if err != nil {
@@ -675,6 +721,14 @@ func (u *bulkUsecaseImpl) BulkGroupActions(ctx context.Context, req *openvpndto.
 			continue
 		}
 
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(groupName) {
+			result.Success = false
+			result.Error = outOfScopeMessage
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
+
 		// Check if group exists
 		_, err := u.groupRepo.GetByName(ctx, groupName)
 		if err != nil {
@@ -755,6 +809,24 @@ func (u *bulkUsecaseImpl) ImportGroups(ctx context.Context, req *openvpndto.Impo
 		ValidationErrors: validationErrors,
 	}
 
+	// Rows for groups outside the caller's scope are reported, not imported
+	scope := entities.GroupScopeFrom(ctx)
+	inScope := groupRequests[:0]
+	for _, groupReq := range groupRequests {
+		if scope.AllowsGroup(groupReq.GroupName) {
+			inScope = append(inScope, groupReq)
+			continue
+		}
+		response.ValidRecords--
+		response.InvalidRecords++
+		response.ValidationErrors = append(response.ValidationErrors, openvpndto.ImportValidationError{
+			Field:   "group_name",
+			Value:   groupReq.GroupName,
+			Message: outOfScopeMessage,
+		})
+	}
+	groupRequests = inScope
+
 	// If dry run, return validation results only
 	if req.DryRun {
 		response.ProcessedRecords = 0
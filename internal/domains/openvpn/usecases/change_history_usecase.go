@@ -0,0 +1,14 @@
+package usecases
+
+import (
+	"context"
+	"system-portal/internal/domains/openvpn/entities"
+)
+
+// ChangeHistoryUsecase serves the change timelines of VPN users and groups.
+type ChangeHistoryUsecase interface {
+	// UserTimeline lists the changes made to a user, newest first
+	UserTimeline(ctx context.Context, username string, page, limit int) ([]*entities.ChangeEntry, int, error)
+	// GroupTimeline lists the changes made to a group, newest first
+	GroupTimeline(ctx context.Context, groupName string, page, limit int) ([]*entities.ChangeEntry, int, error)
+}
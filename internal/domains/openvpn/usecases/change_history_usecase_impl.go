@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"context"
+	"system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/openvpn/repositories"
+	"system-portal/internal/shared/errors"
+)
+
+type changeHistoryUsecaseImpl struct {
+	history repositories.ChangeHistoryRepository
+}
+
+func NewChangeHistoryUsecase(history repositories.ChangeHistoryRepository) ChangeHistoryUsecase {
+	return &changeHistoryUsecaseImpl{history: history}
+}
+
+func (u *changeHistoryUsecaseImpl) UserTimeline(ctx context.Context, username string, page, limit int) ([]*entities.ChangeEntry, int, error) {
+	return u.timeline(ctx, entities.ChangeEntityUser, username, page, limit)
+}
+
+func (u *changeHistoryUsecaseImpl) GroupTimeline(ctx context.Context, groupName string, page, limit int) ([]*entities.ChangeEntry, int, error) {
+	if err := checkGroupScope(ctx, groupName); err != nil {
+		return nil, 0, err
+	}
+	return u.timeline(ctx, entities.ChangeEntityGroup, groupName, page, limit)
+}
+
+// timeline limits scoped callers to the changes made while the entity was in
+// one of their groups, so a user moved between groups keeps the history of
+// each group private to it.
+func (u *changeHistoryUsecaseImpl) timeline(ctx context.Context, entityType, name string, page, limit int) ([]*entities.ChangeEntry, int, error) {
+	filter := &entities.ChangeHistoryFilter{
+		EntityType: entityType,
+		EntityName: name,
+		GroupScope: entities.GroupScopeFrom(ctx),
+		Page:       page,
+		Limit:      limit,
+	}
+	entries, total, err := u.history.List(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.InternalServerError("Failed to get change history", err)
+	}
+	return entries, total, nil
+}
@@ -74,6 +74,15 @@ func (u *disconnectUsecase) DisconnectUser(ctx context.Context, username, messag
 		}, fmt.Errorf("user not found: %w", err)
 	}
 
+	if !entities.GroupScopeFrom(ctx).AllowsGroup(user.GroupName) {
+		logger.Log.WithField("username", username).Warn("User is outside the caller's group scope")
+		return &DisconnectResult{
+			Success:  false,
+			Username: username,
+			Error:    outOfScopeMessage,
+		}, fmt.Errorf("user %s is out of scope", username)
+	}
+
 	logger.Log.WithField("username", username).WithField("auth_method", user.AuthMethod).Info("User found in system")
 
 	// Business Rule 2: Check if user is currently connected to VPN
@@ -155,7 +164,7 @@ func (u *disconnectUsecase) BulkDisconnectUsers(ctx context.Context, usernames [
 	validUsers := make([]string, 0)
 	for _, username := range usernames {
 		// Check if user exists in system
-		_, err := u.userRepo.GetByUsername(ctx, username)
+		user, err := u.userRepo.GetByUsername(ctx, username)
 		if err != nil {
 			result.SkippedUsers = append(result.SkippedUsers, username)
 			result.ValidationErrors = append(result.ValidationErrors, UserValidationError{
@@ -166,6 +175,16 @@ func (u *disconnectUsecase) BulkDisconnectUsers(ctx context.Context, usernames [
 			continue
 		}
 
+		if !entities.GroupScopeFrom(ctx).AllowsGroup(user.GroupName) {
+			result.SkippedUsers = append(result.SkippedUsers, username)
+			result.ValidationErrors = append(result.ValidationErrors, UserValidationError{
+				Username: username,
+				Error:    outOfScopeMessage,
+			})
+			logger.Log.WithField("username", username).Debug("User outside group scope, skipping")
+			continue
+		}
+
 		// Check if user is connected
 		if _, isConnected := connectedUserMap[strings.ToLower(username)]; !isConnected {
 			result.SkippedUsers = append(result.SkippedUsers, username)
@@ -0,0 +1,20 @@
+package usecases
+
+import (
+	"context"
+
+	"system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/shared/errors"
+)
+
+// outOfScopeMessage is reported when the caller's permission is restricted to
+// VPN groups that do not include the target.
+const outOfScopeMessage = "Group is outside your permission scope"
+
+// checkGroupScope rejects operations on groups the caller may not manage.
+func checkGroupScope(ctx context.Context, groupName string) error {
+	if !entities.GroupScopeFrom(ctx).AllowsGroup(groupName) {
+		return errors.Forbidden(outOfScopeMessage, nil)
+	}
+	return nil
+}
@@ -27,6 +27,10 @@ func NewGroupUsecase(groupRepo repositories.GroupRepository, configRepo reposito
 func (u *groupUsecaseImpl) CreateGroup(ctx context.Context, group *entities.Group) error {
 	logger.Log.WithField("groupName", group.GroupName).Info("Creating group")
 
+	if err := checkGroupScope(ctx, group.GroupName); err != nil {
+		return err
+	}
+
 	// Set default values
 	if group.MFA == "" {
 		group.SetMFA(true) // Default MFA to true
@@ -76,6 +80,10 @@ func (u *groupUsecaseImpl) CreateGroup(ctx context.Context, group *entities.Grou
 func (u *groupUsecaseImpl) GetGroup(ctx context.Context, groupName string) (*entities.Group, error) {
 	logger.Log.WithField("groupName", groupName).Debug("Getting group")
 
+	if err := checkGroupScope(ctx, groupName); err != nil {
+		return nil, err
+	}
+
 	group, err := u.groupRepo.GetByName(ctx, groupName)
 	if err != nil {
 		return nil, err
@@ -87,6 +95,10 @@ func (u *groupUsecaseImpl) GetGroup(ctx context.Context, groupName string) (*ent
 func (u *groupUsecaseImpl) UpdateGroup(ctx context.Context, group *entities.Group) error {
 	logger.Log.WithField("groupName", group.GroupName).Info("Updating group")
 
+	if err := checkGroupScope(ctx, group.GroupName); err != nil {
+		return err
+	}
+
 	// Check if group exists
 	existingGroup, err := u.groupRepo.GetByName(ctx, group.GroupName)
 	if err != nil {
@@ -168,6 +180,10 @@ func (u *groupUsecaseImpl) UpdateGroup(ctx context.Context, group *entities.Grou
 func (u *groupUsecaseImpl) DeleteGroup(ctx context.Context, groupName string) error {
 	logger.Log.WithField("groupName", groupName).Info("Deleting group")
 
+	if err := checkGroupScope(ctx, groupName); err != nil {
+		return err
+	}
+
 	// Check if group exists
 	_, err := u.groupRepo.GetByName(ctx, groupName)
 	if err != nil {
@@ -186,6 +202,7 @@ func (u *groupUsecaseImpl) DeleteGroup(ctx context.Context, groupName string) er
 func (u *groupUsecaseImpl) ListGroups(ctx context.Context, filter *entities.GroupFilter) ([]*entities.Group, error) {
 	logger.Log.Debug("Listing groups")
 
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
 	groups, err := u.groupRepo.List(ctx, filter)
 	if err != nil {
 		return nil, errors.InternalServerError("Failed to list groups", err)
@@ -195,11 +212,14 @@ func (u *groupUsecaseImpl) ListGroups(ctx context.Context, filter *entities.Grou
 }
 
 func (u *groupUsecaseImpl) ListGroupsWithCount(ctx context.Context, filter *entities.GroupFilter) ([]*entities.Group, int, error) {
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
+
 	// Get total count without pagination
 	totalFilter := &entities.GroupFilter{
 		GroupName:  filter.GroupName,
 		AuthMethod: filter.AuthMethod,
 		Role:       filter.Role,
+		GroupScope: filter.GroupScope,
 		// No pagination params for count
 	}
 
@@ -221,11 +241,14 @@ func (u *groupUsecaseImpl) ListGroupsWithCount(ctx context.Context, filter *enti
 func (u *groupUsecaseImpl) ListGroupsWithTotal(ctx context.Context, filter *entities.GroupFilter) ([]*entities.Group, int, error) {
 	logger.Log.WithField("filter", filter).Debug("Listing groups with total count")
 
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
+
 	// First get total count (without pagination)
 	totalFilter := &entities.GroupFilter{
 		GroupName:  filter.GroupName,
 		AuthMethod: filter.AuthMethod,
 		Role:       filter.Role,
+		GroupScope: filter.GroupScope,
 		// Don't include pagination for total count
 		Page:  0,
 		Limit: 0,
@@ -255,6 +278,10 @@ func (u *groupUsecaseImpl) ListGroupsWithTotal(ctx context.Context, filter *enti
 func (u *groupUsecaseImpl) EnableGroup(ctx context.Context, groupName string) error {
 	logger.Log.WithField("groupName", groupName).Info("Enabling group")
 
+	if err := checkGroupScope(ctx, groupName); err != nil {
+		return err
+	}
+
 	// Check if group exists
 	_, err := u.groupRepo.GetByName(ctx, groupName)
 	if err != nil {
@@ -272,6 +299,10 @@ func (u *groupUsecaseImpl) EnableGroup(ctx context.Context, groupName string) er
 func (u *groupUsecaseImpl) DisableGroup(ctx context.Context, groupName string) error {
 	logger.Log.WithField("groupName", groupName).Info("Disabling group")
 
+	if err := checkGroupScope(ctx, groupName); err != nil {
+		return err
+	}
+
 	// Check if group exists
 	_, err := u.groupRepo.GetByName(ctx, groupName)
 	if err != nil {
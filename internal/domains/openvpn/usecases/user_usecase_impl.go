@@ -36,7 +36,11 @@ func (u *userUsecaseImpl) CreateUser(ctx context.Context, user *entities.User) e
 		WithField("authMethod", user.AuthMethod).
 		Info("Creating user")
 
-		// Check if user already exists
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return err
+	}
+
+	// Check if user already exists
 	existingUser, err := u.userRepo.ExistsByUsername(ctx, user.Username)
 	if err != nil {
 		return errors.InternalServerError("Failed to check user existence", err)
@@ -128,6 +132,9 @@ func (u *userUsecaseImpl) GetUser(ctx context.Context, username string) (*entiti
 	if err != nil {
 		return nil, err
 	}
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return nil, err
+	}
 
 	// CRITICAL FIX: For LDAP users, verify they still exist in LDAP
 	if user.IsLDAPAuth() {
@@ -164,6 +171,15 @@ func (u *userUsecaseImpl) UpdateUser(ctx context.Context, user *entities.User) e
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, existingUser.GroupName); err != nil {
+		return err
+	}
+	// Moving a user requires access to the target group as well
+	if user.GroupName != "" {
+		if err := checkGroupScope(ctx, user.GroupName); err != nil {
+			return err
+		}
+	}
 	// CRITICAL FIX: For LDAP users, verify they still exist in LDAP
 	if existingUser.IsLDAPAuth() {
 		if err := u.ldapClient.CheckUserExists(user.Username); err != nil {
@@ -283,8 +299,9 @@ func (u *userUsecaseImpl) GetUserExpirations(ctx context.Context, days int) (*op
 
 	// Get all users
 	users, err := u.userRepo.List(ctx, &entities.UserFilter{
-		Limit:  10000, // Get all users
-		Offset: 0,
+		Limit:      10000, // Get all users
+		Offset:     0,
+		GroupScope: entities.GroupScopeFrom(ctx),
 	})
 	if err != nil {
 		return nil, errors.InternalServerError("Failed to get users", err)
@@ -405,6 +422,8 @@ func (u *userUsecaseImpl) GetExpiringUsers(ctx context.Context, days int) ([]str
 }
 
 func (u *userUsecaseImpl) ListUsersWithCount(ctx context.Context, filter *entities.UserFilter) ([]*entities.User, int, error) {
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
+
 	// Get total count without pagination
 	totalFilter := *filter
 	totalFilter.Page = 0
@@ -429,6 +448,8 @@ func (u *userUsecaseImpl) ListUsersWithCount(ctx context.Context, filter *entiti
 func (u *userUsecaseImpl) ListUsersWithTotal(ctx context.Context, filter *entities.UserFilter) ([]*entities.User, int, error) {
 	logger.Log.WithField("filter", filter).Debug("Listing users with total count")
 
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
+
 	// First get total count (without pagination)
 	totalFilter := *filter
 	totalFilter.Page = 0
@@ -481,6 +502,9 @@ func (u *userUsecaseImpl) DeleteUser(ctx context.Context, username string) error
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, existingUser.GroupName); err != nil {
+		return err
+	}
 
 	// Additional validation for user deletion
 	if err := u.validateUserDeletion(existingUser); err != nil {
@@ -499,6 +523,7 @@ func (u *userUsecaseImpl) DeleteUser(ctx context.Context, username string) error
 func (u *userUsecaseImpl) ListUsers(ctx context.Context, filter *entities.UserFilter) ([]*entities.User, error) {
 	logger.Log.Debug("Listing users")
 
+	filter.GroupScope = entities.GroupScopeFrom(ctx)
 	users, err := u.userRepo.List(ctx, filter)
 	if err != nil {
 		return nil, errors.InternalServerError("Failed to list users", err)
@@ -532,6 +557,9 @@ func (u *userUsecaseImpl) EnableUser(ctx context.Context, username string) error
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return err
+	}
 
 	// CRITICAL FIX: For LDAP users, verify they still exist in LDAP
 	if user.IsLDAPAuth() {
@@ -563,6 +591,9 @@ func (u *userUsecaseImpl) DisableUser(ctx context.Context, username string) erro
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return err
+	}
 
 	// Additional validation for user disabling
 	if err := u.validateUserAction(user, "disable"); err != nil {
@@ -605,6 +636,9 @@ func (u *userUsecaseImpl) ChangePassword(ctx context.Context, username, password
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return err
+	}
 
 	// CRITICAL SECURITY FIX: Check if user is local
 	if !user.IsLocalAuth() {
@@ -636,10 +670,13 @@ func (u *userUsecaseImpl) RegenerateTOTP(ctx context.Context, username string) e
 	}
 
 	// Check if user exists
-	_, err := u.userRepo.GetByUsername(ctx, username)
+	user, err := u.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		return err
 	}
+	if err := checkGroupScope(ctx, user.GroupName); err != nil {
+		return err
+	}
 
 	if err := u.userRepo.RegenerateTOTP(ctx, username); err != nil {
 		return errors.InternalServerError("Failed to regenerate TOTP", err)
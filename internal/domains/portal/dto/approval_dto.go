@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/pkg/utils"
+)
+
+// ChangeRequestResponse is a change request as shown to requesters and
+// reviewers. Secrets in the payload, such as connection passwords, are
+// masked.
+type ChangeRequestResponse struct {
+	ID              uuid.UUID       `json:"id"`
+	Operation       string          `json:"operation"`
+	Permission      string          `json:"permission"`
+	Target          string          `json:"target,omitempty"`
+	Payload         json.RawMessage `json:"payload,omitempty"`
+	Status          string          `json:"status"`
+	RequestedBy     uuid.UUID       `json:"requestedBy"`
+	RequestedByName string          `json:"requestedByName"`
+	RequesterGroup  string          `json:"requesterGroup,omitempty"`
+	RequesterScope  []string        `json:"requesterScope,omitempty"`
+	ReviewedBy      *uuid.UUID      `json:"reviewedBy,omitempty"`
+	ReviewedByName  string          `json:"reviewedByName,omitempty"`
+	ReviewComment   string          `json:"reviewComment,omitempty"`
+	ReviewedAt      *time.Time      `json:"reviewedAt,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	ExpiresAt       time.Time       `json:"expiresAt"`
+	ExecutedAt      *time.Time      `json:"executedAt,omitempty"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// ReviewChangeRequest is the body of an approve or reject call.
+type ReviewChangeRequest struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+func NewChangeRequestResponse(cr *entities.ChangeRequest) ChangeRequestResponse {
+	resp := ChangeRequestResponse{
+		ID:              cr.ID,
+		Operation:       cr.Operation,
+		Permission:      cr.Permission,
+		Target:          cr.Target,
+		Payload:         utils.RedactJSON(cr.Payload),
+		Status:          cr.Status,
+		RequestedBy:     cr.RequestedBy,
+		RequestedByName: cr.RequestedByName,
+		RequesterGroup:  cr.RequesterGroup,
+		RequesterScope:  cr.RequesterScope,
+		ReviewedByName:  cr.ReviewedByName,
+		ReviewComment:   cr.ReviewComment,
+		ReviewedAt:      cr.ReviewedAt,
+		Result:          cr.Result,
+		Error:           cr.Error,
+		ExpiresAt:       cr.ExpiresAt,
+		ExecutedAt:      cr.ExecutedAt,
+		CreatedAt:       cr.CreatedAt,
+	}
+	if cr.ReviewedBy != uuid.Nil {
+		id := cr.ReviewedBy
+		resp.ReviewedBy = &id
+	}
+	return resp
+}
@@ -1,6 +1,11 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
 
 type AuditResponse struct {
 	ID           uuid.UUID `json:"id"`
@@ -10,3 +15,124 @@ type AuditResponse struct {
 	ResourceName string    `json:"resourceName"`
 	Success      bool      `json:"success"`
 }
+
+// AuditCountResponse counts the entries sharing a key.
+type AuditCountResponse struct {
+	Key     string `json:"key"`
+	Total   int64  `json:"total"`
+	Success int64  `json:"success"`
+	Failed  int64  `json:"failed"`
+}
+
+// AuditBucketResponse counts the entries of one time bucket.
+type AuditBucketResponse struct {
+	Start   time.Time `json:"start"`
+	Total   int64     `json:"total"`
+	Success int64     `json:"success"`
+	Failed  int64     `json:"failed"`
+}
+
+// AuditStatsResponse aggregates the audit entries matching a filter.
+type AuditStatsResponse struct {
+	Total          int64                 `json:"total"`
+	Success        int64                 `json:"success"`
+	Failed         int64                 `json:"failed"`
+	Bucket         string                `json:"bucket"`
+	ByAction       []AuditCountResponse  `json:"byAction"`
+	ByResourceType []AuditCountResponse  `json:"byResourceType"`
+	ByUser         []AuditCountResponse  `json:"byUser"`
+	ByGroup        []AuditCountResponse  `json:"byGroup"`
+	Timeline       []AuditBucketResponse `json:"timeline"`
+}
+
+func NewAuditStatsResponse(s *entities.AuditStats, bucket string) AuditStatsResponse {
+	return AuditStatsResponse{
+		Total:          s.Total,
+		Success:        s.Success,
+		Failed:         s.Failed,
+		Bucket:         bucket,
+		ByAction:       NewAuditCountResponses(s.ByAction),
+		ByResourceType: NewAuditCountResponses(s.ByResourceType),
+		ByUser:         NewAuditCountResponses(s.ByUser),
+		ByGroup:        NewAuditCountResponses(s.ByGroup),
+		Timeline:       NewAuditBucketResponses(s.Timeline),
+	}
+}
+
+func NewAuditCountResponses(counts []entities.AuditCount) []AuditCountResponse {
+	out := make([]AuditCountResponse, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, AuditCountResponse{Key: c.Key, Total: c.Total, Success: c.Success, Failed: c.Failed})
+	}
+	return out
+}
+
+func NewAuditBucketResponses(buckets []entities.AuditBucket) []AuditBucketResponse {
+	out := make([]AuditBucketResponse, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, AuditBucketResponse{Start: b.Start, Total: b.Total, Success: b.Success, Failed: b.Failed})
+	}
+	return out
+}
+
+// AuditChainReportResponse is the result of verifying the audit hash chain.
+type AuditChainReportResponse struct {
+	Valid              bool       `json:"valid"`
+	Checked            int64      `json:"checked"`
+	Archived           int64      `json:"archived"`
+	Unchained          int64      `json:"unchained"`
+	CheckpointsChecked int        `json:"checkpointsChecked"`
+	HeadSeq            int64      `json:"headSeq"`
+	HeadHash           string     `json:"headHash,omitempty"`
+	BrokenSeq          int64      `json:"brokenSeq,omitempty"`
+	BrokenID           *uuid.UUID `json:"brokenId,omitempty"`
+	Reason             string     `json:"reason,omitempty"`
+	VerifiedAt         time.Time  `json:"verifiedAt"`
+}
+
+func NewAuditChainReportResponse(r *entities.AuditChainReport) AuditChainReportResponse {
+	resp := AuditChainReportResponse{
+		Valid:              r.Valid,
+		Checked:            r.Checked,
+		Archived:           r.Archived,
+		Unchained:          r.Unchained,
+		CheckpointsChecked: r.CheckpointsChecked,
+		HeadSeq:            r.HeadSeq,
+		HeadHash:           r.HeadHash,
+		BrokenSeq:          r.BrokenSeq,
+		Reason:             r.Reason,
+		VerifiedAt:         r.VerifiedAt,
+	}
+	if r.BrokenID != uuid.Nil {
+		id := r.BrokenID
+		resp.BrokenID = &id
+	}
+	return resp
+}
+
+// AuditCheckpointResponse is a signed checkpoint in a form an external
+// system can verify: Signature is an Ed25519 signature by PublicKey over
+// Message, all base64 but Message.
+type AuditCheckpointResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	KeyID     string    `json:"keyId"`
+	PublicKey string    `json:"publicKey"`
+	Signature string    `json:"signature"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewAuditCheckpointResponse(cp *entities.AuditCheckpoint) AuditCheckpointResponse {
+	return AuditCheckpointResponse{
+		ID:        cp.ID,
+		Seq:       cp.Seq,
+		Hash:      cp.Hash,
+		KeyID:     cp.KeyID,
+		PublicKey: cp.PublicKey,
+		Signature: cp.Signature,
+		Message:   string(cp.Message()),
+		CreatedAt: cp.CreatedAt,
+	}
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/google/uuid"
+
 // OpenVPNConfigRequest represents payload to set OpenVPN connection details
 type OpenVPNConfigRequest struct {
 	Host     string `json:"host" binding:"required"`
@@ -16,3 +18,18 @@ type LDAPConfigRequest struct {
 	BindPassword string `json:"bindPassword" binding:"required"`
 	BaseDN       string `json:"baseDN" binding:"required"`
 }
+
+// LDAPGroupMappingRequest maps an AD group DN to a portal group
+type LDAPGroupMappingRequest struct {
+	LDAPGroupDN string    `json:"ldapGroupDN" binding:"required"`
+	GroupID     uuid.UUID `json:"groupId" binding:"required"`
+	// Priority decides which mapping wins when a user is in several groups; lower wins
+	Priority int `json:"priority"`
+}
+
+type LDAPGroupMappingResponse struct {
+	ID          uuid.UUID `json:"id"`
+	LDAPGroupDN string    `json:"ldapGroupDN"`
+	GroupID     uuid.UUID `json:"groupId"`
+	Priority    int       `json:"priority"`
+}
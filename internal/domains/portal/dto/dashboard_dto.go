@@ -1,5 +1,195 @@
 package dto
 
+import (
+	"time"
+
+	"system-portal/internal/domains/portal/entities"
+)
+
+// DashboardCountResponse is the number of items sharing a key.
+type DashboardCountResponse struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type GroupUserCountResponse struct {
+	Group    string `json:"group"`
+	Active   int    `json:"active"`
+	Inactive int    `json:"inactive"`
+}
+
+// PortalUsersResponse counts portal users overall and per group.
+type PortalUsersResponse struct {
+	Total    int                      `json:"total"`
+	Active   int                      `json:"active"`
+	Inactive int                      `json:"inactive"`
+	ByGroup  []GroupUserCountResponse `json:"byGroup"`
+}
+
+type ExpiringVPNUserResponse struct {
+	Username  string `json:"username"`
+	GroupName string `json:"groupName,omitempty"`
+	ExpiresOn string `json:"expiresOn"`
+	DaysLeft  int    `json:"daysLeft"`
+}
+
+type ConnectedVPNUserResponse struct {
+	Username       string    `json:"username"`
+	RealAddress    string    `json:"realAddress"`
+	VirtualAddress string    `json:"virtualAddress"`
+	Country        string    `json:"country,omitempty"`
+	ConnectedSince time.Time `json:"connectedSince"`
+	BytesReceived  int64     `json:"bytesReceived"`
+	BytesSent      int64     `json:"bytesSent"`
+}
+
+// VPNUsersResponse describes OpenVPN users. When available is false reason
+// explains why and the counts are empty. The expiring and sessions lists are
+// only filled when detailed, for callers who may view OpenVPN users.
+type VPNUsersResponse struct {
+	Available    bool                       `json:"available"`
+	Reason       string                     `json:"reason,omitempty"`
+	Detailed     bool                       `json:"detailed"`
+	Total        int                        `json:"total"`
+	Disabled     int                        `json:"disabled"`
+	Expired      int                        `json:"expired"`
+	ByGroup      []DashboardCountResponse   `json:"byGroup"`
+	ByAuthMethod []DashboardCountResponse   `json:"byAuthMethod"`
+	ByMFA        []DashboardCountResponse   `json:"byMfa"`
+	Expiring7    int                        `json:"expiringIn7Days"`
+	Expiring30   int                        `json:"expiringIn30Days"`
+	Expiring     []ExpiringVPNUserResponse  `json:"expiring"`
+	Connected    int                        `json:"connected"`
+	Sessions     []ConnectedVPNUserResponse `json:"sessions"`
+}
+
 type StatsResponse struct {
-	Users int `json:"users"`
+	// Users is the number of portal users
+	Users       int                 `json:"users"`
+	Portal      PortalUsersResponse `json:"portal"`
+	VPN         VPNUsersResponse    `json:"vpn"`
+	AuditToday  int64               `json:"auditToday"`
+	FailedToday int64               `json:"failedToday"`
+}
+
+// AuditSeriesResponse is the per-bucket activity of one key.
+type AuditSeriesResponse struct {
+	Key      string                `json:"key"`
+	Total    int64                 `json:"total"`
+	Timeline []AuditBucketResponse `json:"timeline"`
+}
+
+// ActivityChartResponse is the audit activity of the last Days days.
+type ActivityChartResponse struct {
+	Days           int                   `json:"days"`
+	From           time.Time             `json:"from"`
+	Total          int64                 `json:"total"`
+	Failed         int64                 `json:"failed"`
+	Timeline       []AuditBucketResponse `json:"timeline"`
+	ByAction       []AuditSeriesResponse `json:"byAction"`
+	ByResourceType []AuditCountResponse  `json:"byResourceType"`
+}
+
+// UserChartResponse breaks portal and OpenVPN users down and lists the most
+// active users and groups of the last Days days.
+type UserChartResponse struct {
+	Days    int                  `json:"days"`
+	From    time.Time            `json:"from"`
+	Portal  PortalUsersResponse  `json:"portal"`
+	VPN     VPNUsersResponse     `json:"vpn"`
+	ByUser  []AuditCountResponse `json:"byUser"`
+	ByGroup []AuditCountResponse `json:"byGroup"`
+}
+
+func NewStatsResponse(s *entities.DashboardStats) StatsResponse {
+	return StatsResponse{
+		Users:       s.Users.Total,
+		Portal:      NewPortalUsersResponse(s.Users),
+		VPN:         NewVPNUsersResponse(s.VPN),
+		AuditToday:  s.AuditToday,
+		FailedToday: s.FailedToday,
+	}
+}
+
+func NewUserChartResponse(ch *entities.UserChart, days int) UserChartResponse {
+	return UserChartResponse{
+		Days:    days,
+		From:    ch.From,
+		Portal:  NewPortalUsersResponse(ch.Portal),
+		VPN:     NewVPNUsersResponse(ch.VPN),
+		ByUser:  NewAuditCountResponses(ch.ActiveUsers),
+		ByGroup: NewAuditCountResponses(ch.ActiveGroups),
+	}
+}
+
+func NewActivityChartResponse(ch *entities.ActivityChart, days int) ActivityChartResponse {
+	series := make([]AuditSeriesResponse, 0, len(ch.ByAction))
+	for _, s := range ch.ByAction {
+		series = append(series, AuditSeriesResponse{Key: s.Key, Total: s.Total, Timeline: NewAuditBucketResponses(s.Buckets)})
+	}
+	return ActivityChartResponse{
+		Days:           days,
+		From:           ch.From,
+		Total:          ch.Total,
+		Failed:         ch.Failed,
+		Timeline:       NewAuditBucketResponses(ch.Timeline),
+		ByAction:       series,
+		ByResourceType: NewAuditCountResponses(ch.ByResourceType),
+	}
+}
+
+func NewPortalUsersResponse(s entities.PortalUserSummary) PortalUsersResponse {
+	groups := make([]GroupUserCountResponse, 0, len(s.ByGroup))
+	for _, g := range s.ByGroup {
+		groups = append(groups, GroupUserCountResponse{Group: g.Group, Active: g.Active, Inactive: g.Inactive})
+	}
+	return PortalUsersResponse{Total: s.Total, Active: s.Active, Inactive: s.Total - s.Active, ByGroup: groups}
+}
+
+func NewVPNUsersResponse(s entities.VPNSummary) VPNUsersResponse {
+	expiring := make([]ExpiringVPNUserResponse, 0, len(s.Expiring))
+	for _, e := range s.Expiring {
+		expiring = append(expiring, ExpiringVPNUserResponse{
+			Username:  e.Username,
+			GroupName: e.GroupName,
+			ExpiresOn: e.ExpiresOn.Format("2006-01-02"),
+			DaysLeft:  e.DaysLeft,
+		})
+	}
+	sessions := make([]ConnectedVPNUserResponse, 0, len(s.Sessions))
+	for _, c := range s.Sessions {
+		sessions = append(sessions, ConnectedVPNUserResponse{
+			Username:       c.Username,
+			RealAddress:    c.RealAddress,
+			VirtualAddress: c.VirtualAddress,
+			Country:        c.Country,
+			ConnectedSince: c.ConnectedSince,
+			BytesReceived:  c.BytesReceived,
+			BytesSent:      c.BytesSent,
+		})
+	}
+	return VPNUsersResponse{
+		Available:    s.Available,
+		Reason:       s.Reason,
+		Detailed:     s.Detailed,
+		Total:        s.Users,
+		Disabled:     s.Disabled,
+		Expired:      s.Expired,
+		ByGroup:      newDashboardCounts(s.ByGroup),
+		ByAuthMethod: newDashboardCounts(s.ByAuthMethod),
+		ByMFA:        newDashboardCounts(s.ByMFA),
+		Expiring7:    s.Expiring7,
+		Expiring30:   s.Expiring30,
+		Expiring:     expiring,
+		Connected:    s.Connected,
+		Sessions:     sessions,
+	}
+}
+
+func newDashboardCounts(counts []entities.DashboardCount) []DashboardCountResponse {
+	out := make([]DashboardCountResponse, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, DashboardCountResponse{Key: c.Key, Count: c.Count})
+	}
+	return out
 }
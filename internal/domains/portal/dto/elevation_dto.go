@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ElevationRequest asks for a permission or a group for a number of minutes.
+type ElevationRequest struct {
+	// Permission such as openvpn.delete_users; leave empty to request a group
+	Permission string `json:"permission"`
+	// VPNGroupScope limits a permission to matching OpenVPN groups
+	VPNGroupScope []string  `json:"vpnGroupScope"`
+	GroupID       uuid.UUID `json:"groupId"`
+	Minutes       int       `json:"minutes" binding:"required,min=1"`
+	Justification string    `json:"justification" binding:"required,min=10,max=500"`
+}
+
+// ElevationReviewRequest is the body of an approve, reject or revoke call.
+type ElevationReviewRequest struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+type ElevationResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"userId"`
+	Username       string     `json:"username"`
+	Permission     string     `json:"permission,omitempty"`
+	VPNGroupScope  []string   `json:"vpnGroupScope,omitempty"`
+	GroupID        *uuid.UUID `json:"groupId,omitempty"`
+	GroupName      string     `json:"groupName,omitempty"`
+	Justification  string     `json:"justification"`
+	Minutes        int        `json:"minutes"`
+	Status         string     `json:"status"`
+	AutoApproved   bool       `json:"autoApproved"`
+	ApprovedByName string     `json:"approvedByName,omitempty"`
+	ReviewComment  string     `json:"reviewComment,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	EndedAt        *time.Time `json:"endedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func NewElevationResponse(g *entities.ElevationGrant) ElevationResponse {
+	resp := ElevationResponse{
+		ID:             g.ID,
+		UserID:         g.UserID,
+		Username:       g.Username,
+		Permission:     g.Permission,
+		VPNGroupScope:  g.VPNGroupScope,
+		GroupName:      g.GroupName,
+		Justification:  g.Justification,
+		Minutes:        g.DurationMinutes,
+		Status:         g.Status,
+		AutoApproved:   g.AutoApproved,
+		ApprovedByName: g.ApprovedByName,
+		ReviewComment:  g.ReviewComment,
+		ReviewedAt:     g.ReviewedAt,
+		ExpiresAt:      g.ExpiresAt,
+		EndedAt:        g.EndedAt,
+		CreatedAt:      g.CreatedAt,
+	}
+	if g.GroupID != uuid.Nil {
+		id := g.GroupID
+		resp.GroupID = &id
+	}
+	return resp
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type PortalUserRequest struct {
 	Username string    `json:"username" binding:"required"`
@@ -8,6 +12,11 @@ type PortalUserRequest struct {
 	FullName string    `json:"fullName"`
 	Password string    `json:"password"`
 	GroupID  uuid.UUID `json:"groupId"`
+	// GroupIDs are further groups the user belongs to besides GroupID
+	GroupIDs []uuid.UUID `json:"groupIds"`
+	// AuthSource is "local" (default), or "ldap"/"oidc" to pre-provision an
+	// externally authenticated user
+	AuthSource string `json:"authSource" binding:"omitempty,oneof=local ldap oidc"`
 }
 
 // PortalUserUpdateRequest is used when updating a user. Username and email are
@@ -18,11 +27,25 @@ type PortalUserUpdateRequest struct {
 	GroupID  uuid.UUID `json:"groupId"`
 }
 
+// PortalUserGroupsRequest replaces a user's group memberships. The primary
+// group is always a member and is reported as the user's role.
+type PortalUserGroupsRequest struct {
+	PrimaryGroupID uuid.UUID   `json:"primaryGroupId" binding:"required"`
+	GroupIDs       []uuid.UUID `json:"groupIds"`
+}
+
+// PortalUserResponse describes a portal user. GroupID is the primary group
+// and GroupIDs every group the user belongs to.
 type PortalUserResponse struct {
-	ID       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	FullName string    `json:"fullName"`
-	GroupID  uuid.UUID `json:"groupId"`
-	IsActive bool      `json:"isActive"`
+	ID         uuid.UUID   `json:"id"`
+	Username   string      `json:"username"`
+	Email      string      `json:"email"`
+	FullName   string      `json:"fullName"`
+	GroupID    uuid.UUID   `json:"groupId"`
+	GroupIDs   []uuid.UUID `json:"groupIds"`
+	IsActive   bool        `json:"isActive"`
+	AuthSource string      `json:"authSource"`
+	// FailedLogins and LockedUntil are only reported for a single user
+	FailedLogins int        `json:"failedLogins,omitempty"`
+	LockedUntil  *time.Time `json:"lockedUntil,omitempty"`
 }
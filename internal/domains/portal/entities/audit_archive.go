@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tables that retention archives.
+const (
+	ArchiveSourceAuditLogs    = "audit_logs"
+	ArchiveSourceUserSessions = "user_sessions"
+)
+
+// AuditArchive describes a compressed JSONL file holding rows removed by
+// retention. SHA256 is the digest of the file as written. MinSeq and MaxSeq
+// are the chain positions of archived audit entries.
+type AuditArchive struct {
+	ID         uuid.UUID
+	Name       string
+	Source     string
+	Rows       int
+	SHA256     string
+	FromTime   time.Time
+	ToTime     time.Time
+	MinSeq     int64
+	MaxSeq     int64
+	CreatedAt  time.Time
+	RestoredAt *time.Time
+}
+
+// ArchivedSession is an expired session row as archived, without its token
+// hashes.
+type ArchivedSession struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Data      json.RawMessage
+}
+
+// RetentionResult summarises one retention run.
+type RetentionResult struct {
+	// Skipped is set when another instance was already running retention
+	Skipped      bool
+	AuditLogs    int
+	UserSessions int
+	Archives     []*AuditArchive
+}
+
+// RetentionCutoffs selects expired audit entries: those created before the
+// cutoff of their resource type, or Default for other types. A nil cutoff
+// keeps entries forever. Restored entries are kept until HoldRestoredUntil
+// has passed their restore time.
+type RetentionCutoffs struct {
+	Default           *time.Time
+	ResourceTypes     map[string]*time.Time
+	HoldRestoredUntil time.Time
+}
+
+// Span widens the archive's time range to include t.
+func (a *AuditArchive) Span(t time.Time) {
+	if a.FromTime.IsZero() || t.Before(a.FromTime) {
+		a.FromTime = t
+	}
+	if t.After(a.ToTime) {
+		a.ToTime = t
+	}
+}
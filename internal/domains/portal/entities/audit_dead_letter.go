@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditDeadLetter is an audit entry a sink did not accept after all retries.
+// Payload holds the entry as JSON so it can be delivered again.
+type AuditDeadLetter struct {
+	ID            uuid.UUID
+	Sink          string
+	AuditID       uuid.UUID
+	Payload       json.RawMessage
+	Error         string
+	Attempts      int
+	CreatedAt     time.Time
+	LastAttemptAt time.Time
+}
@@ -1,6 +1,27 @@
 package entities
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Columns audit entries can be sorted by.
+const (
+	AuditSortCreatedAt    = "created_at"
+	AuditSortUsername     = "username"
+	AuditSortAction       = "action"
+	AuditSortResourceType = "resource_type"
+)
+
+// Sort directions.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+var ErrInvalidAuditCursor = errors.New("invalid or mismatched cursor")
 
 // AuditFilter defines filtering and pagination for audit logs
 //
@@ -8,17 +29,28 @@ import "time"
 // Default values are Page=1 and Limit=20 if not provided.
 // FromTime and ToTime can be nil to omit the bound.
 //
-// Username filters by username, UserGroup by group name, IPAddress by IP.
+// The slice fields match any of their values. Success nil matches both
+// outcomes, ResourceName is a case-insensitive substring and Query a
+// full-text search (websearch syntax) over actor, action, target and request.
+// Sort and Order default to created_at desc; Cursor continues a keyset
+// search after the last entry of the previous page.
 type AuditFilter struct {
-	Username  string
-	UserGroup string
-	IPAddress string
-	Resource  string
-	FromTime  *time.Time
-	ToTime    *time.Time
-	Page      int
-	Limit     int
-	Offset    int
+	Usernames    []string
+	UserGroups   []string
+	IPAddresses  []string
+	Resources    []string
+	Actions      []string
+	Success      *bool
+	ResourceName string
+	Query        string
+	FromTime     *time.Time
+	ToTime       *time.Time
+	Sort         string
+	Order        string
+	Cursor       string
+	Page         int
+	Limit        int
+	Offset       int
 }
 
 // SetDefaults ensures pagination defaults and calculates offset.
@@ -30,4 +62,49 @@ func (f *AuditFilter) SetDefaults() {
 		f.Limit = 20
 	}
 	f.Offset = (f.Page - 1) * f.Limit
+	if f.Sort == "" {
+		f.Sort = AuditSortCreatedAt
+	}
+	if f.Order == "" {
+		f.Order = SortDesc
+	}
+}
+
+// ValidSort reports whether Sort and Order name a supported ordering.
+func (f *AuditFilter) ValidSort() bool {
+	switch f.Sort {
+	case "", AuditSortCreatedAt, AuditSortUsername, AuditSortAction, AuditSortResourceType:
+	default:
+		return false
+	}
+	return f.Order == "" || f.Order == SortAsc || f.Order == SortDesc
+}
+
+// AuditCursor marks the last entry of a search page: its value of the sort
+// column and its chain position, which breaks ties. Sort and Order tie the
+// cursor to the ordering it was issued for.
+type AuditCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	Seq   int64  `json:"q"`
+}
+
+// Encode returns the opaque form handed to clients.
+func (c AuditCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeAuditCursor parses a cursor issued for the filter's ordering.
+func DecodeAuditCursor(s string, f *AuditFilter) (*AuditCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidAuditCursor
+	}
+	var c AuditCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != f.Sort || c.Order != f.Order {
+		return nil, ErrInvalidAuditCursor
+	}
+	return &c, nil
 }
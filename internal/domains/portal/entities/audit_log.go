@@ -1,12 +1,21 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// AuditLog records portal activities.
+// AuditLog records portal activities. Action is a semantic name such as
+// openvpn.user.disable.
+//
+// Entries made for API calls also carry the request context; RequestBody has
+// secrets redacted. These fields are empty for entries recorded elsewhere.
+//
+// Seq orders the hash chain; Hash covers the entry and PrevHash, the Hash of
+// the entry before it. Entries older than the chain have no hash. Archived
+// entries were moved out by retention; only their chain fields remain.
 type AuditLog struct {
 	ID           uuid.UUID
 	UserID       uuid.UUID
@@ -18,4 +27,18 @@ type AuditLog struct {
 	IPAddress    string
 	Success      bool
 	CreatedAt    time.Time
+
+	Method      string
+	Route       string
+	PathParams  map[string]string
+	RequestBody json.RawMessage
+	StatusCode  int
+	DurationMs  int64
+	UserAgent   string
+	RequestID   string
+
+	Seq      int64
+	PrevHash string
+	Hash     string
+	Archived bool
 }
@@ -0,0 +1,77 @@
+package entities
+
+import "time"
+
+// Time buckets of audit statistics.
+const (
+	AuditBucketHour = "hour"
+	AuditBucketDay  = "day"
+	AuditBucketWeek = "week"
+)
+
+// ValidAuditBucket reports whether b is a supported time bucket.
+func ValidAuditBucket(b string) bool {
+	return b == AuditBucketHour || b == AuditBucketDay || b == AuditBucketWeek
+}
+
+// AuditStatsQuery selects the time bucket of the timeline and how many of
+// the most frequent keys each breakdown keeps.
+type AuditStatsQuery struct {
+	Bucket string
+	Top    int
+}
+
+// SetDefaults fills in a daily timeline and the top 10 keys.
+func (q *AuditStatsQuery) SetDefaults() {
+	if q.Bucket == "" {
+		q.Bucket = AuditBucketDay
+	}
+	if q.Top <= 0 {
+		q.Top = 10
+	}
+	if q.Top > 100 {
+		q.Top = 100
+	}
+}
+
+// AuditCount is the number of entries sharing a key, such as an action.
+type AuditCount struct {
+	Key     string
+	Total   int64
+	Success int64
+	Failed  int64
+}
+
+// AuditBucket counts the entries of one time bucket starting at Start (UTC).
+type AuditBucket struct {
+	Start   time.Time
+	Total   int64
+	Success int64
+	Failed  int64
+}
+
+// AuditStats aggregates the entries matching an AuditFilter. Breakdowns are
+// ordered by count and hold at most AuditStatsQuery.Top keys; the timeline
+// covers every bucket between the first and last entry.
+type AuditStats struct {
+	Total          int64
+	Success        int64
+	Failed         int64
+	ByAction       []AuditCount
+	ByResourceType []AuditCount
+	ByUser         []AuditCount
+	ByGroup        []AuditCount
+	Timeline       []AuditBucket
+}
+
+// NextAuditBucket returns the start of the bucket after t.
+func NextAuditBucket(bucket string, t time.Time) time.Time {
+	switch bucket {
+	case AuditBucketHour:
+		return t.Add(time.Hour)
+	case AuditBucketWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Change request states. A request is approved only while its operation
+// runs; it then ends up executed or failed.
+const (
+	ChangeRequestPending   = "pending"
+	ChangeRequestApproved  = "approved"
+	ChangeRequestExecuted  = "executed"
+	ChangeRequestFailed    = "failed"
+	ChangeRequestRejected  = "rejected"
+	ChangeRequestCancelled = "cancelled"
+	ChangeRequestExpired   = "expired"
+)
+
+// ChangeRequest is an operation held until a second portal user approves
+// it. Payload is the operation's full input; Permission is the permission
+// that guards the operation and that the approver must hold as well.
+//
+// RequesterScope is the VPN group scope the requester held, which the
+// operation runs with once approved.
+type ChangeRequest struct {
+	ID              uuid.UUID
+	Operation       string
+	Permission      string
+	Target          string
+	Payload         json.RawMessage
+	Status          string
+	RequestedBy     uuid.UUID
+	RequestedByName string
+	RequesterGroup  string
+	RequesterScope  []string
+	ReviewedBy      uuid.UUID
+	ReviewedByName  string
+	ReviewComment   string
+	ReviewedAt      *time.Time
+	// Result is what the operation returned, such as per-item outcomes of a
+	// bulk action; Error is set when it failed
+	Result     json.RawMessage
+	Error      string
+	ExpiresAt  time.Time
+	ExecutedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ChangeRequestFilter defines optional filters and pagination for listing
+// change requests.
+type ChangeRequestFilter struct {
+	Status      string
+	Operation   string
+	RequestedBy uuid.UUID
+	Page        int
+	Limit       int
+	Offset      int
+}
+
+// SetDefaults ensures pagination defaults and calculates the offset.
+func (f *ChangeRequestFilter) SetDefaults() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	f.Offset = (f.Page - 1) * f.Limit
+}
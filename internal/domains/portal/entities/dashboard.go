@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"time"
+
+	ovEntities "system-portal/internal/domains/openvpn/entities"
+)
+
+// DashboardCount is the number of items sharing a key, such as a group.
+type DashboardCount struct {
+	Key   string
+	Count int
+}
+
+// GroupUserCount counts the active and inactive members of a portal group.
+// Users in several groups count in each.
+type GroupUserCount struct {
+	Group    string
+	Active   int
+	Inactive int
+}
+
+// PortalUserSummary counts portal users overall and per group.
+type PortalUserSummary struct {
+	Total   int
+	Active  int
+	ByGroup []GroupUserCount
+}
+
+// ExpiringVPNUser is an OpenVPN user whose account ends soon.
+type ExpiringVPNUser struct {
+	Username  string
+	GroupName string
+	ExpiresOn time.Time
+	DaysLeft  int
+}
+
+// VPNSummary describes the OpenVPN users and live sessions within the
+// caller's group scope. When OpenVPN is not configured or cannot be reached
+// Available is false, Reason says why and the other fields are empty.
+// Expiring and Sessions name users, so they are only filled when Detailed.
+type VPNSummary struct {
+	Available    bool
+	Reason       string
+	Detailed     bool
+	Users        int
+	Disabled     int
+	Expired      int
+	ByGroup      []DashboardCount
+	ByAuthMethod []DashboardCount
+	ByMFA        []DashboardCount
+	Expiring7    int
+	Expiring30   int
+	Connected    int
+	// Expiring lists users ending within 30 days, soonest first
+	Expiring []ExpiringVPNUser
+	Sessions []*ovEntities.ConnectedUser
+}
+
+// AuditSeriesOther is the key of the series holding everything outside the
+// top keys.
+const AuditSeriesOther = "other"
+
+// AuditSeries is the per-bucket activity of one key, such as an action.
+type AuditSeries struct {
+	Key     string
+	Total   int64
+	Buckets []AuditBucket
+}
+
+// DashboardStats is the dashboard headline.
+type DashboardStats struct {
+	Users       PortalUserSummary
+	VPN         VPNSummary
+	AuditToday  int64
+	FailedToday int64
+}
+
+// UserChart breaks portal and OpenVPN users down for the dashboard, with
+// the users and groups most active in the audit log since From.
+type UserChart struct {
+	From         time.Time
+	Portal       PortalUserSummary
+	VPN          VPNSummary
+	ActiveUsers  []AuditCount
+	ActiveGroups []AuditCount
+}
+
+// ActivityChart is the daily audit activity since From, overall and for the
+// most frequent actions.
+type ActivityChart struct {
+	From           time.Time
+	Total          int64
+	Failed         int64
+	Timeline       []AuditBucket
+	ByAction       []AuditSeries
+	ByResourceType []AuditCount
+}
@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Elevation grant states. A grant is active from approval until it expires
+// or is revoked.
+const (
+	ElevationPending   = "pending"
+	ElevationActive    = "active"
+	ElevationRejected  = "rejected"
+	ElevationCancelled = "cancelled"
+	ElevationRevoked   = "revoked"
+	ElevationExpired   = "expired"
+)
+
+// ElevationGrant temporarily gives a portal user either one permission or
+// the permissions of one group. VPNGroupScope limits a permission grant to
+// matching OpenVPN groups; it is empty for unrestricted grants.
+type ElevationGrant struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	Username        string
+	Permission      string
+	GroupID         uuid.UUID
+	GroupName       string
+	VPNGroupScope   []string
+	Justification   string
+	DurationMinutes int
+	Status          string
+	AutoApproved    bool
+	ApprovedBy      uuid.UUID
+	ApprovedByName  string
+	ReviewComment   string
+	ReviewedAt      *time.Time
+	// ExpiresAt is set when the grant becomes active
+	ExpiresAt *time.Time
+	// EndedAt is when the grant was revoked or expired
+	EndedAt   *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Duration is how long the grant lasts once active.
+func (g *ElevationGrant) Duration() time.Duration {
+	return time.Duration(g.DurationMinutes) * time.Minute
+}
+
+// ElevationFilter defines optional filters and pagination for listing
+// elevation grants.
+type ElevationFilter struct {
+	UserID uuid.UUID
+	Status string
+	Page   int
+	Limit  int
+	Offset int
+}
+
+// SetDefaults ensures pagination defaults and calculates the offset.
+func (f *ElevationFilter) SetDefaults() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = 20
+	}
+	f.Offset = (f.Page - 1) * f.Limit
+}
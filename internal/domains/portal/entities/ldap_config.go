@@ -16,3 +16,13 @@ type LDAPConfig struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
+
+// LDAPGroupMapping maps an AD group (memberOf DN) to a portal group
+type LDAPGroupMapping struct {
+	ID          uuid.UUID
+	LDAPGroupDN string
+	GroupID     uuid.UUID
+	Priority    int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
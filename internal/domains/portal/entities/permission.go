@@ -8,4 +8,18 @@ type Permission struct {
 	Resource    string
 	Action      string
 	Description string
+	// VPNGroupScope limits an openvpn grant to matching VPN group names. It
+	// is only set on permissions loaded for a group, empty means unrestricted.
+	VPNGroupScope []string `json:",omitempty"`
+}
+
+// PermissionCacheStats reports how permission checks are served by the
+// in-memory cache.
+type PermissionCacheStats struct {
+	Enabled       bool    `json:"enabled"`
+	Groups        int     `json:"groups"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRatio      float64 `json:"hit_ratio"`
+	Invalidations uint64  `json:"invalidations"`
 }
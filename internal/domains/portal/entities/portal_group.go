@@ -13,6 +13,7 @@ type PortalGroup struct {
 	DisplayName string
 	Permissions []*Permission
 	IsActive    bool
+	MFARequired bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
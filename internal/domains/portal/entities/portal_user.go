@@ -6,20 +6,38 @@ import (
 	"github.com/google/uuid"
 )
 
+// Authentication sources for portal users.
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+	AuthSourceOIDC  = "oidc"
+)
+
 // User represents a portal user entity.
 type PortalUser struct {
-	ID        uuid.UUID
-	Username  string
-	Email     string
-	FullName  string
-	Password  string
-	GroupID   uuid.UUID
-	IsActive  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       uuid.UUID
+	Username string
+	Email    string
+	FullName string
+	Password string
+	// GroupID is the primary group, reported as the user's role
+	GroupID uuid.UUID
+	// GroupIDs lists every group the user belongs to, the primary one
+	// included. Permissions are the union of their grants.
+	GroupIDs []uuid.UUID
+	// GroupsVersion changes whenever the memberships do
+	GroupsVersion int
+	IsActive      bool
+	AuthSource    string
+	// ExternalID is the directory DN of an LDAP user or the issuer and
+	// subject of an OIDC user
+	ExternalID string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 // UserFilter defines optional filters and pagination for listing users.
+// GroupID matches any member of the group, not only its primary members.
 type UserFilter struct {
 	Username string
 	Email    string
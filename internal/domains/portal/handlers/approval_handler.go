@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	vpnentities "system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/portal/dto"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+)
+
+type ApprovalHandler struct{ uc usecases.ApprovalUsecase }
+
+func NewApprovalHandler(u usecases.ApprovalUsecase) *ApprovalHandler {
+	return &ApprovalHandler{uc: u}
+}
+
+// Hold submits the operation for approval instead of running it when perm
+// requires approval, responding 202 with the pending change request. It
+// reports whether the request was held; callers run the operation themselves
+// otherwise. A nil handler holds nothing.
+func (h *ApprovalHandler) Hold(c *gin.Context, perm, operation, target string, payload interface{}) bool {
+	if h == nil || !h.uc.Required(perm) {
+		return false
+	}
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return true
+	}
+	actor.Scope = vpnentities.GroupScopeFrom(c.Request.Context())
+	cr, err := h.uc.Submit(c.Request.Context(), actor, perm, operation, target, payload)
+	if err != nil {
+		logger.Log.WithError(err).WithField("operation", operation).Error("failed to submit change request")
+		http.RespondWithInternalError(c, "failed to submit change request")
+		return true
+	}
+	http.RespondWithSuccess(c, nethttp.StatusAccepted, dto.NewChangeRequestResponse(cr))
+	return true
+}
+
+// ListChangeRequests godoc
+// @Summary List change requests
+// @Tags Approvals
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param operation query string false "Filter by operation"
+// @Param mine query bool false "Only my requests"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=[]dto.ChangeRequestResponse}
+// @Router /api/portal/approvals [get]
+type changeRequestQuery struct {
+	Status    string `form:"status"`
+	Operation string `form:"operation"`
+	Mine      bool   `form:"mine"`
+	Page      int    `form:"page,default=1"`
+	Limit     int    `form:"limit,default=20"`
+}
+
+func (h *ApprovalHandler) ListChangeRequests(c *gin.Context) {
+	var q changeRequestQuery
+	_ = c.ShouldBindQuery(&q)
+	filter := &entities.ChangeRequestFilter{Status: q.Status, Operation: q.Operation, Page: q.Page, Limit: q.Limit}
+	if q.Mine {
+		actor, _ := approvalActor(c)
+		filter.RequestedBy = actor.UserID
+	}
+	items, total, err := h.uc.List(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to list change requests")
+		http.RespondWithInternalError(c, "failed to list change requests")
+		return
+	}
+	out := make([]dto.ChangeRequestResponse, 0, len(items))
+	for _, cr := range items {
+		out = append(out, dto.NewChangeRequestResponse(cr))
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"requests": out, "total": total, "page": filter.Page, "limit": filter.Limit})
+}
+
+// GetChangeRequest godoc
+// @Summary Get change request
+// @Tags Approvals
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Success 200 {object} response.SuccessResponse{data=dto.ChangeRequestResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/portal/approvals/{id} [get]
+func (h *ApprovalHandler) GetChangeRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	cr, err := h.uc.Get(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewChangeRequestResponse(cr))
+}
+
+// ApproveChangeRequest godoc
+// @Summary Approve change request
+// @Description Approve a pending change request and run its operation. The approver must be another user holding the operation's permission.
+// @Tags Approvals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body dto.ReviewChangeRequest false "Review comment"
+// @Success 200 {object} response.SuccessResponse{data=dto.ChangeRequestResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/approvals/{id}/approve [post]
+func (h *ApprovalHandler) ApproveChangeRequest(c *gin.Context) {
+	h.review(c, h.uc.Approve)
+}
+
+// RejectChangeRequest godoc
+// @Summary Reject change request
+// @Tags Approvals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Param request body dto.ReviewChangeRequest false "Review comment"
+// @Success 200 {object} response.SuccessResponse{data=dto.ChangeRequestResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/approvals/{id}/reject [post]
+func (h *ApprovalHandler) RejectChangeRequest(c *gin.Context) {
+	h.review(c, h.uc.Reject)
+}
+
+type reviewFunc func(ctx context.Context, id uuid.UUID, reviewer usecases.ApprovalActor, comment string) (*entities.ChangeRequest, error)
+
+func (h *ApprovalHandler) review(c *gin.Context, fn reviewFunc) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	var req dto.ReviewChangeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			http.RespondWithBadRequest(c, "invalid request")
+			return
+		}
+	}
+	// Reviews are a human decision, so API tokens cannot make them
+	if _, isToken := c.Get("apiTokenID"); isToken {
+		http.RespondWithForbidden(c, "change requests must be reviewed in an interactive session")
+		return
+	}
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	cr, err := fn(c.Request.Context(), id, actor, req.Comment)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewChangeRequestResponse(cr))
+}
+
+// CancelChangeRequest godoc
+// @Summary Cancel my change request
+// @Tags Approvals
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Change request ID"
+// @Success 200 {object} response.SuccessResponse{data=dto.ChangeRequestResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/approvals/{id}/cancel [post]
+func (h *ApprovalHandler) CancelChangeRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	cr, err := h.uc.Cancel(c.Request.Context(), id, actor)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewChangeRequestResponse(cr))
+}
+
+func (h *ApprovalHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrChangeRequestNotFound):
+		http.RespondWithNotFound(c, err.Error())
+	case errors.Is(err, usecases.ErrSelfReview),
+		errors.Is(err, usecases.ErrReviewerNotAuthorized),
+		errors.Is(err, usecases.ErrNotRequester):
+		http.RespondWithForbidden(c, err.Error())
+	case errors.Is(err, usecases.ErrChangeRequestClosed),
+		errors.Is(err, usecases.ErrChangeRequestExpired),
+		errors.Is(err, usecases.ErrOperationUnavailable):
+		http.RespondWithConflict(c, err.Error())
+	default:
+		logger.Log.WithError(err).Error("change request operation failed")
+		http.RespondWithInternalError(c, "change request operation failed")
+	}
+}
+
+// approvalActor identifies the caller from the values set by AuthMiddleware.
+func approvalActor(c *gin.Context) (usecases.ApprovalActor, bool) {
+	v, _ := c.Get("userID")
+	id, _ := v.(uuid.UUID)
+	if id == uuid.Nil {
+		return usecases.ApprovalActor{}, false
+	}
+	actor := usecases.ApprovalActor{
+		UserID:   id,
+		Username: c.GetString("username"),
+		Group:    c.GetString("role"),
+		IP:       c.ClientIP(),
+	}
+	if g, ok := c.Get("groups"); ok {
+		actor.Groups, _ = g.([]string)
+	}
+	if len(actor.Groups) == 0 && actor.Group != "" {
+		actor.Groups = []string{actor.Group}
+	}
+	return actor, true
+}
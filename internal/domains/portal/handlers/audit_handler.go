@@ -1,138 +1,351 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/csv"
+	"compress/gzip"
+	"errors"
+	"io"
 	nethttp "net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"system-portal/internal/domains/portal/dto"
 	"system-portal/internal/domains/portal/entities"
 	"system-portal/internal/domains/portal/usecases"
 	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
-type AuditHandler struct{ uc usecases.AuditUsecase }
+type AuditHandler struct {
+	uc    usecases.AuditUsecase
+	chain usecases.AuditChainUsecase
+}
 
-func NewAuditHandler(u usecases.AuditUsecase) *AuditHandler { return &AuditHandler{uc: u} }
+func NewAuditHandler(u usecases.AuditUsecase, chain usecases.AuditChainUsecase) *AuditHandler {
+	return &AuditHandler{uc: u, chain: chain}
+}
 
 // GetAuditLogs godoc
 // @Summary List audit logs
+// @Description Page through matching entries by page number. For deep result sets use /audit/search.
 // @Tags Audit
 // @Security BearerAuth
 // @Produce json
-// @Param username query string false "Filter by username"
-// @Param group query string false "Filter by group"
-// @Param ip query string false "Filter by IP address"
+// @Param username query []string false "Filter by username (repeat or comma-separate for several)"
+// @Param group query []string false "Filter by group"
+// @Param ip query []string false "Filter by IP address"
+// @Param resource query []string false "Filter by resource type"
+// @Param action query []string false "Filter by action"
+// @Param success query bool false "Filter by outcome"
+// @Param resourceName query string false "Resource name contains"
+// @Param q query string false "Full-text search"
 // @Param from query string false "Start date" Format(date)
 // @Param to query string false "End date" Format(date)
+// @Param sort query string false "created_at, username, action or resource_type" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} response.SuccessResponse{data=[]entities.AuditLog}
 // @Router /api/portal/audit/logs [get]
 type auditQuery struct {
-	Username string     `form:"username"`
-	Group    string     `form:"group"`
-	IP       string     `form:"ip"`
-	Resource string     `form:"resource"`
-	From     *time.Time `form:"from" time_format:"2006-01-02"`
-	To       *time.Time `form:"to" time_format:"2006-01-02"`
-	Page     int        `form:"page,default=1"`
-	Limit    int        `form:"limit,default=20"`
+	Username     []string   `form:"username"`
+	Group        []string   `form:"group"`
+	IP           []string   `form:"ip"`
+	Resource     []string   `form:"resource"`
+	Action       []string   `form:"action"`
+	Success      *bool      `form:"success"`
+	ResourceName string     `form:"resourceName"`
+	Q            string     `form:"q"`
+	From         *time.Time `form:"from" time_format:"2006-01-02"`
+	To           *time.Time `form:"to" time_format:"2006-01-02"`
+	Sort         string     `form:"sort"`
+	Order        string     `form:"order"`
+	Cursor       string     `form:"cursor"`
+	Page         int        `form:"page,default=1"`
+	Limit        int        `form:"limit,default=20"`
+}
+
+// filter converts the query; multi-value parameters may be repeated or
+// comma-separated.
+func (q auditQuery) filter() *entities.AuditFilter {
+	return &entities.AuditFilter{
+		Usernames:    splitValues(q.Username),
+		UserGroups:   splitValues(q.Group),
+		IPAddresses:  splitValues(q.IP),
+		Resources:    splitValues(q.Resource),
+		Actions:      splitValues(q.Action),
+		Success:      q.Success,
+		ResourceName: q.ResourceName,
+		Query:        q.Q,
+		FromTime:     q.From,
+		ToTime:       q.To,
+		Sort:         q.Sort,
+		Order:        strings.ToLower(q.Order),
+	}
+}
+
+func splitValues(in []string) []string {
+	var out []string
+	for _, v := range in {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
 }
 
 func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 	var q auditQuery
 	_ = c.ShouldBindQuery(&q)
-	filter := &entities.AuditFilter{
-		Username:  q.Username,
-		UserGroup: q.Group,
-		IPAddress: q.IP,
-		Resource:  q.Resource,
-		FromTime:  q.From,
-		ToTime:    q.To,
-		Page:      q.Page,
-		Limit:     q.Limit,
+	filter := q.filter()
+	filter.Page = q.Page
+	filter.Limit = q.Limit
+	if !filter.ValidSort() {
+		http.RespondWithBadRequest(c, "invalid sort or order")
+		return
 	}
 	logs, total, _ := h.uc.List(c.Request.Context(), filter)
 	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"logs": logs, "total": total, "page": filter.Page, "limit": filter.Limit})
 }
 
+// SearchAuditLogs godoc
+// @Summary Search audit logs
+// @Description Filter entries by any combination of fields and full text, paging with a cursor: pass nextCursor from one response as cursor to get the next page with the same filters and sort. No total is computed.
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Param username query []string false "Filter by username (repeat or comma-separate for several)"
+// @Param group query []string false "Filter by group"
+// @Param ip query []string false "Filter by IP address"
+// @Param resource query []string false "Filter by resource type"
+// @Param action query []string false "Filter by action"
+// @Param success query bool false "Filter by outcome"
+// @Param resourceName query string false "Resource name contains"
+// @Param q query string false "Full-text search over actor, action, target and request"
+// @Param from query string false "Start date" Format(date)
+// @Param to query string false "End date" Format(date)
+// @Param sort query string false "created_at, username, action or resource_type" default(created_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param limit query int false "Items per page, at most 500" default(50)
+// @Success 200 {object} response.SuccessResponse{data=[]entities.AuditLog}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/audit/search [get]
+func (h *AuditHandler) SearchAuditLogs(c *gin.Context) {
+	var q auditQuery
+	_ = c.ShouldBindQuery(&q)
+	filter := q.filter()
+	filter.Cursor = q.Cursor
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+	if !filter.ValidSort() {
+		http.RespondWithBadRequest(c, "invalid sort or order")
+		return
+	}
+	logs, next, err := h.uc.Search(c.Request.Context(), filter)
+	if errors.Is(err, entities.ErrInvalidAuditCursor) {
+		http.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to search audit logs")
+		http.RespondWithInternalError(c, "failed to search audit logs")
+		return
+	}
+	if logs == nil {
+		logs = []*entities.AuditLog{}
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"logs": logs, "nextCursor": next, "limit": filter.Limit})
+}
+
 // ExportAuditLogs godoc
 // @Summary Export audit logs
+// @Description Stream every matching entry, oldest first, as CSV, JSON Lines or ArcSight CEF, optionally gzip-compressed.
 // @Tags Audit
 // @Security BearerAuth
 // @Produce text/csv
-// @Param username query string false "Filter by username"
-// @Param group query string false "Filter by group"
-// @Param ip query string false "Filter by IP address"
+// @Produce application/x-ndjson
+// @Produce text/plain
+// @Produce application/gzip
+// @Param username query []string false "Filter by username"
+// @Param group query []string false "Filter by group"
+// @Param ip query []string false "Filter by IP address"
+// @Param resource query []string false "Filter by resource type"
+// @Param action query []string false "Filter by action"
+// @Param success query bool false "Filter by outcome"
+// @Param resourceName query string false "Resource name contains"
+// @Param q query string false "Full-text search"
 // @Param from query string false "Start date" Format(date)
 // @Param to query string false "End date" Format(date)
-// @Success 200 {string} string "CSV file"
+// @Param format query string false "csv, jsonl or cef" default(csv)
+// @Param gzip query bool false "Compress the export" default(false)
+// @Success 200 {string} string "Export file"
+// @Failure 400 {object} response.ErrorResponse
 // @Router /api/portal/audit/logs/export [get]
 func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
 	var q auditQuery
 	_ = c.ShouldBindQuery(&q)
-	filter := &entities.AuditFilter{
-		Username:  q.Username,
-		UserGroup: q.Group,
-		IPAddress: q.IP,
-		Resource:  q.Resource,
-		FromTime:  q.From,
-		ToTime:    q.To,
-	}
-	logs, _, _ := h.uc.List(c.Request.Context(), filter)
-	var buf bytes.Buffer
-	w := csv.NewWriter(&buf)
-	_ = w.Write([]string{"id", "user_id", "username", "user_group", "action", "resource_type", "resource_name", "ip_address", "success", "created_at"})
-	for _, l := range logs {
-		uid := l.UserID.String()
-		_ = w.Write([]string{
-			l.ID.String(),
-			uid,
-			l.Username,
-			l.UserGroup,
-			l.Action,
-			l.ResourceType,
-			l.ResourceName,
-			l.IPAddress,
-			strconv.FormatBool(l.Success),
-			l.CreatedAt.Format(time.RFC3339),
-		})
-	}
-	w.Flush()
-	c.Header("Content-Disposition", "attachment; filename=audit_logs.csv")
-	c.Data(nethttp.StatusOK, "text/csv", buf.Bytes())
+	filter := q.filter()
+	format := c.DefaultQuery("format", usecases.AuditFormatCSV)
+	contentType, ok := auditExportTypes[format]
+	if !ok {
+		http.RespondWithBadRequest(c, usecases.ErrExportFormat.Error())
+		return
+	}
+	compress, _ := strconv.ParseBool(c.Query("gzip"))
+	filename := "audit_logs." + format
+
+	var out io.Writer = c.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(c.Writer)
+		out = gz
+		filename += ".gz"
+		contentType = "application/gzip"
+	}
+	enc, err := usecases.NewAuditEncoder(format, out)
+	if err != nil {
+		http.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	// Large exports outlast the server's write timeout
+	_ = nethttp.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", contentType)
+	c.Status(nethttp.StatusOK)
+
+	n := 0
+	err = h.uc.Export(c.Request.Context(), filter, func(a *entities.AuditLog) error {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+		n++
+		// Hand rows to the client as they are read instead of buffering
+		if n%exportFlushEvery == 0 {
+			if err := enc.Flush(); err != nil {
+				return err
+			}
+			if gz != nil {
+				if err := gz.Flush(); err != nil {
+					return err
+				}
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = enc.Flush()
+	}
+	if gz != nil {
+		if cerr := gz.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		// The status is already sent, so the client sees a truncated file
+		logger.Log.WithError(err).WithField("rows", n).Error("audit export failed")
+		c.Abort()
+	}
+}
+
+// auditExportTypes maps export formats to their content types.
+var auditExportTypes = map[string]string{
+	usecases.AuditFormatCSV:   "text/csv",
+	usecases.AuditFormatJSONL: "application/x-ndjson",
+	usecases.AuditFormatCEF:   "text/plain",
 }
 
+// exportFlushEvery is how many entries are written between flushes.
+const exportFlushEvery = 500
+
 // GetAuditStats godoc
 // @Summary Audit statistics
+// @Description Count the matching entries overall, by action, resource type, user and group, and per time bucket.
 // @Tags Audit
 // @Security BearerAuth
 // @Produce json
-// @Param username query string false "Filter by username"
-// @Param group query string false "Filter by group"
-// @Param ip query string false "Filter by IP address"
+// @Param username query []string false "Filter by username"
+// @Param group query []string false "Filter by group"
+// @Param ip query []string false "Filter by IP address"
+// @Param resource query []string false "Filter by resource type"
+// @Param action query []string false "Filter by action"
+// @Param success query bool false "Filter by outcome"
+// @Param resourceName query string false "Resource name contains"
+// @Param q query string false "Full-text search"
 // @Param from query string false "Start date" Format(date)
 // @Param to query string false "End date" Format(date)
-// @Success 200 {object} response.SuccessResponse{data=map[string]int}
+// @Param bucket query string false "Timeline bucket: hour, day or week" default(day)
+// @Param top query int false "Keys per breakdown" default(10)
+// @Success 200 {object} response.SuccessResponse{data=dto.AuditStatsResponse}
+// @Failure 400 {object} response.ErrorResponse
 // @Router /api/portal/audit/stats [get]
 func (h *AuditHandler) GetAuditStats(c *gin.Context) {
 	var q auditQuery
 	_ = c.ShouldBindQuery(&q)
-	filter := &entities.AuditFilter{Username: q.Username, UserGroup: q.Group, IPAddress: q.IP, Resource: q.Resource, FromTime: q.From, ToTime: q.To}
-	logs, _, _ := h.uc.List(c.Request.Context(), filter)
-	var total, success, failed int
-	for _, l := range logs {
-		total++
-		if l.Success {
-			success++
-		} else {
-			failed++
-		}
+	filter := q.filter()
+	top, _ := strconv.Atoi(c.Query("top"))
+	sq := entities.AuditStatsQuery{Bucket: c.Query("bucket"), Top: top}
+	sq.SetDefaults()
+	if !entities.ValidAuditBucket(sq.Bucket) {
+		http.RespondWithBadRequest(c, "bucket must be hour, day or week")
+		return
+	}
+	stats, err := h.uc.Stats(c.Request.Context(), filter, sq)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to compute audit statistics")
+		http.RespondWithInternalError(c, "failed to compute audit statistics")
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewAuditStatsResponse(stats, sq.Bucket))
+}
+
+// VerifyAuditChain godoc
+// @Summary Verify audit log integrity
+// @Description Walk the audit hash chain and the signed checkpoints and report the first entry that was altered, removed or inserted.
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=dto.AuditChainReportResponse}
+// @Router /api/portal/audit/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	report, err := h.chain.Verify(c.Request.Context())
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to verify audit chain")
+		http.RespondWithInternalError(c, "failed to verify audit chain")
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewAuditChainReportResponse(report))
+}
+
+// ListAuditCheckpoints godoc
+// @Summary Export audit checkpoints
+// @Description Signed checkpoints of the audit hash chain, oldest first, for storage in an external system. Page with afterSeq set to the last seq received.
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Param afterSeq query int false "Return checkpoints after this chain position"
+// @Param limit query int false "Maximum checkpoints" default(100)
+// @Success 200 {object} response.SuccessResponse{data=[]dto.AuditCheckpointResponse}
+// @Router /api/portal/audit/checkpoints [get]
+func (h *AuditHandler) ListAuditCheckpoints(c *gin.Context) {
+	afterSeq, _ := strconv.ParseInt(c.DefaultQuery("afterSeq", "0"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	items, err := h.chain.ListCheckpoints(c.Request.Context(), afterSeq, limit)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to list audit checkpoints")
+		http.RespondWithInternalError(c, "failed to list audit checkpoints")
+		return
+	}
+	out := make([]dto.AuditCheckpointResponse, 0, len(items))
+	for _, cp := range items {
+		out = append(out, dto.NewAuditCheckpointResponse(cp))
 	}
-	stats := map[string]int{"total": total, "success": success, "failed": failed}
-	http.RespondWithSuccess(c, nethttp.StatusOK, stats)
+	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"checkpoints": out})
 }
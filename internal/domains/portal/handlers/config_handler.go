@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	nethttp "net/http"
 	"system-portal/internal/domains/portal/dto"
 	"system-portal/internal/domains/portal/entities"
@@ -9,13 +13,59 @@ import (
 	httpresp "system-portal/internal/shared/response"
 )
 
+// Connection changes that may be held for approval
+const (
+	OpSetOpenVPNConfig    = "config.set_openvpn"
+	OpDeleteOpenVPNConfig = "config.delete_openvpn"
+	OpSetLDAPConfig       = "config.set_ldap"
+	OpDeleteLDAPConfig    = "config.delete_ldap"
+)
+
+const manageConnections = "config.manage_connections"
+
 type ConfigHandler struct {
-	uc     usecases.ConfigUsecase
-	reload func()
+	uc        usecases.ConfigUsecase
+	reload    func()
+	approvals *ApprovalHandler
+}
+
+func NewConfigHandler(u usecases.ConfigUsecase, reload func(), approvals *ApprovalHandler) *ConfigHandler {
+	return &ConfigHandler{uc: u, reload: reload, approvals: approvals}
 }
 
-func NewConfigHandler(u usecases.ConfigUsecase, reload func()) *ConfigHandler {
-	return &ConfigHandler{uc: u, reload: reload}
+// RegisterChanges registers the connection changes that can be held for
+// approval.
+func (h *ConfigHandler) RegisterChanges(a usecases.ApprovalUsecase) {
+	a.Register(OpSetOpenVPNConfig, func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		var req dto.OpenVPNConfigRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, err
+		}
+		cfg := &entities.OpenVPNConfig{Host: req.Host, Username: req.Username, Password: req.Password, Port: req.Port}
+		return nil, h.applied(h.uc.SetOpenVPN(ctx, cfg))
+	})
+	a.Register(OpDeleteOpenVPNConfig, func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		return nil, h.applied(h.uc.DeleteOpenVPN(ctx))
+	})
+	a.Register(OpSetLDAPConfig, func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		var req dto.LDAPConfigRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, err
+		}
+		cfg := &entities.LDAPConfig{Host: req.Host, Port: req.Port, BindDN: req.BindDN, BindPassword: req.BindPassword, BaseDN: req.BaseDN}
+		return nil, h.applied(h.uc.SetLDAP(ctx, cfg))
+	})
+	a.Register(OpDeleteLDAPConfig, func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		return nil, h.applied(h.uc.DeleteLDAP(ctx))
+	})
+}
+
+// applied reloads the connections after a successful change.
+func (h *ConfigHandler) applied(err error) error {
+	if err == nil && h.reload != nil {
+		h.reload()
+	}
+	return err
 }
 
 // GetOpenVPNConfig godoc
@@ -88,6 +138,10 @@ func (h *ConfigHandler) CreateOpenVPNConfig(c *gin.Context) {
 		return
 	}
 
+	if h.approvals.Hold(c, manageConnections, OpSetOpenVPNConfig, "openvpn", req) {
+		return
+	}
+
 	cfg := &entities.OpenVPNConfig{
 		Host:     req.Host,
 		Username: req.Username,
@@ -129,6 +183,10 @@ func (h *ConfigHandler) UpdateOpenVPNConfig(c *gin.Context) {
 		return
 	}
 
+	if h.approvals.Hold(c, manageConnections, OpSetOpenVPNConfig, "openvpn", req) {
+		return
+	}
+
 	cfg := &entities.OpenVPNConfig{
 		Host:     req.Host,
 		Username: req.Username,
@@ -153,6 +211,9 @@ func (h *ConfigHandler) UpdateOpenVPNConfig(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse
 // @Router /api/portal/connections/openvpn [delete]
 func (h *ConfigHandler) DeleteOpenVPNConfig(c *gin.Context) {
+	if h.approvals.Hold(c, manageConnections, OpDeleteOpenVPNConfig, "openvpn", nil) {
+		return
+	}
 	if err := h.uc.DeleteOpenVPN(c.Request.Context()); err != nil {
 		httpresp.RespondWithBadRequest(c, err.Error())
 		return
@@ -233,6 +294,10 @@ func (h *ConfigHandler) CreateLDAPConfig(c *gin.Context) {
 		return
 	}
 
+	if h.approvals.Hold(c, manageConnections, OpSetLDAPConfig, "ldap", req) {
+		return
+	}
+
 	cfg := &entities.LDAPConfig{
 		Host:         req.Host,
 		Port:         req.Port,
@@ -275,6 +340,10 @@ func (h *ConfigHandler) UpdateLDAPConfig(c *gin.Context) {
 		return
 	}
 
+	if h.approvals.Hold(c, manageConnections, OpSetLDAPConfig, "ldap", req) {
+		return
+	}
+
 	cfg := &entities.LDAPConfig{
 		Host:         req.Host,
 		Port:         req.Port,
@@ -300,6 +369,9 @@ func (h *ConfigHandler) UpdateLDAPConfig(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse
 // @Router /api/portal/connections/ldap [delete]
 func (h *ConfigHandler) DeleteLDAPConfig(c *gin.Context) {
+	if h.approvals.Hold(c, manageConnections, OpDeleteLDAPConfig, "ldap", nil) {
+		return
+	}
 	if err := h.uc.DeleteLDAP(c.Request.Context()); err != nil {
 		httpresp.RespondWithBadRequest(c, err.Error())
 		return
@@ -309,3 +381,100 @@ func (h *ConfigHandler) DeleteLDAPConfig(c *gin.Context) {
 	}
 	httpresp.RespondWithMessage(c, nethttp.StatusOK, "deleted")
 }
+
+// ListLDAPGroupMappings godoc
+// @Summary List LDAP group mappings
+// @Description AD groups whose members are given a portal group on LDAP login
+// @Tags Connections
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]dto.LDAPGroupMappingResponse}
+// @Router /api/portal/connections/ldap/group-mappings [get]
+func (h *ConfigHandler) ListLDAPGroupMappings(c *gin.Context) {
+	mappings, err := h.uc.ListLDAPGroupMappings(c.Request.Context())
+	if err != nil {
+		httpresp.RespondWithInternalError(c, "failed to list mappings")
+		return
+	}
+	resp := make([]dto.LDAPGroupMappingResponse, 0, len(mappings))
+	for _, m := range mappings {
+		resp = append(resp, dto.LDAPGroupMappingResponse{ID: m.ID, LDAPGroupDN: m.LDAPGroupDN, GroupID: m.GroupID, Priority: m.Priority})
+	}
+	httpresp.RespondWithSuccess(c, nethttp.StatusOK, resp)
+}
+
+// CreateLDAPGroupMapping godoc
+// @Summary Create LDAP group mapping
+// @Tags Connections
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.LDAPGroupMappingRequest true "Mapping"
+// @Success 201 {object} response.SuccessResponse{data=dto.LDAPGroupMappingResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/connections/ldap/group-mappings [post]
+func (h *ConfigHandler) CreateLDAPGroupMapping(c *gin.Context) {
+	var req dto.LDAPGroupMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresp.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	m := &entities.LDAPGroupMapping{LDAPGroupDN: req.LDAPGroupDN, GroupID: req.GroupID, Priority: req.Priority}
+	if err := h.uc.CreateLDAPGroupMapping(c.Request.Context(), m); err != nil {
+		httpresp.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	httpresp.RespondWithSuccess(c, nethttp.StatusCreated, dto.LDAPGroupMappingResponse{ID: m.ID, LDAPGroupDN: m.LDAPGroupDN, GroupID: m.GroupID, Priority: m.Priority})
+}
+
+// UpdateLDAPGroupMapping godoc
+// @Summary Update LDAP group mapping
+// @Tags Connections
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Param request body dto.LDAPGroupMappingRequest true "Mapping"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/connections/ldap/group-mappings/{id} [put]
+func (h *ConfigHandler) UpdateLDAPGroupMapping(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		httpresp.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	var req dto.LDAPGroupMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpresp.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	m := &entities.LDAPGroupMapping{ID: id, LDAPGroupDN: req.LDAPGroupDN, GroupID: req.GroupID, Priority: req.Priority}
+	if err := h.uc.UpdateLDAPGroupMapping(c.Request.Context(), m); err != nil {
+		httpresp.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	httpresp.RespondWithMessage(c, nethttp.StatusOK, "updated")
+}
+
+// DeleteLDAPGroupMapping godoc
+// @Summary Delete LDAP group mapping
+// @Tags Connections
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/connections/ldap/group-mappings/{id} [delete]
+func (h *ConfigHandler) DeleteLDAPGroupMapping(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		httpresp.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	if err := h.uc.DeleteLDAPGroupMapping(c.Request.Context(), id); err != nil {
+		httpresp.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	httpresp.RespondWithMessage(c, nethttp.StatusOK, "deleted")
+}
@@ -1,35 +1,40 @@
 package handlers
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"system-portal/internal/domains/portal/dto"
-	"system-portal/internal/domains/portal/entities"
-	"system-portal/internal/domains/portal/repositories"
+	"system-portal/internal/domains/portal/usecases"
+	"system-portal/internal/shared/middleware"
 	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
 )
 
 type DashboardHandler struct {
-	userRepo  repositories.UserRepository
-	auditRepo repositories.AuditRepository
+	uc usecases.DashboardUsecase
 }
 
-func NewDashboardHandler(u repositories.UserRepository, a repositories.AuditRepository) *DashboardHandler {
-	return &DashboardHandler{userRepo: u, auditRepo: a}
+func NewDashboardHandler(u usecases.DashboardUsecase) *DashboardHandler {
+	return &DashboardHandler{uc: u}
 }
 
 // GetDashboardStats godoc
 // @Summary Dashboard statistics
+// @Description Portal users, OpenVPN users and today's audit activity. The vpn section reports available=false with a reason when OpenVPN is not configured or unreachable, and only lists users and sessions to callers with openvpn.view_users.
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} response.SuccessResponse{data=dto.StatsResponse}
 // @Router /api/portal/dashboard/stats [get]
 func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
-	users, total, _ := h.userRepo.List(c.Request.Context(), &entities.UserFilter{})
-	if total == 0 {
-		total = len(users)
+	stats, err := h.uc.Stats(c.Request.Context(), vpnDetail(c))
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load dashboard statistics")
+		http.RespondWithInternalError(c, "failed to load dashboard statistics")
+		return
 	}
-	http.RespondWithSuccess(c, 200, dto.StatsResponse{Users: total})
+	http.RespondWithSuccess(c, 200, dto.NewStatsResponse(stats))
 }
 
 // GetRecentActivities godoc
@@ -40,8 +45,12 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse{data=[]dto.AuditResponse}
 // @Router /api/portal/dashboard/activities [get]
 func (h *DashboardHandler) GetRecentActivities(c *gin.Context) {
-	filter := &entities.AuditFilter{Page: 1, Limit: 10}
-	logs, _, _ := h.auditRepo.List(c.Request.Context(), filter)
+	logs, err := h.uc.RecentActivity(c.Request.Context(), 10)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load recent activities")
+		http.RespondWithInternalError(c, "failed to load recent activities")
+		return
+	}
 	resp := make([]dto.AuditResponse, 0, len(logs))
 	for _, l := range logs {
 		resp = append(resp, dto.AuditResponse{
@@ -58,22 +67,58 @@ func (h *DashboardHandler) GetRecentActivities(c *gin.Context) {
 
 // GetUserChartData godoc
 // @Summary User chart data
+// @Description Portal users by group and active state, OpenVPN users by group, auth method and MFA state with upcoming expirations and live sessions (listed only with openvpn.view_users), and the most active users and groups of the last days.
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} response.SuccessResponse{data=map[string]interface{}}
+// @Param days query int false "Days to cover" default(30)
+// @Success 200 {object} response.SuccessResponse{data=dto.UserChartResponse}
 // @Router /api/portal/dashboard/charts/users [get]
 func (h *DashboardHandler) GetUserChartData(c *gin.Context) {
-	http.RespondWithSuccess(c, 200, gin.H{})
+	days := chartDays(c)
+	chart, err := h.uc.UserChart(c.Request.Context(), days, vpnDetail(c))
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load user chart data")
+		http.RespondWithInternalError(c, "failed to load chart data")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.NewUserChartResponse(chart, days))
 }
 
 // GetActivityChartData godoc
 // @Summary Activity chart data
+// @Description Daily audit activity of the last days, overall and per action, with the most frequent resource types. Days without activity are reported as zero.
 // @Tags Dashboard
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} response.SuccessResponse{data=map[string]interface{}}
+// @Param days query int false "Days to cover" default(30)
+// @Success 200 {object} response.SuccessResponse{data=dto.ActivityChartResponse}
 // @Router /api/portal/dashboard/charts/activities [get]
 func (h *DashboardHandler) GetActivityChartData(c *gin.Context) {
-	http.RespondWithSuccess(c, 200, gin.H{})
+	days := chartDays(c)
+	chart, err := h.uc.ActivityChart(c.Request.Context(), days)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to load activity chart data")
+		http.RespondWithInternalError(c, "failed to load chart data")
+		return
+	}
+	http.RespondWithSuccess(c, 200, dto.NewActivityChartResponse(chart, days))
+}
+
+// vpnDetail reports whether the caller may see the OpenVPN users behind the
+// dashboard counts.
+func vpnDetail(c *gin.Context) bool {
+	return c.GetBool(middleware.PermittedKey("openvpn.view_users"))
+}
+
+// chartDays reads the days a chart covers, 30 by default and at most a year.
+func chartDays(c *gin.Context) int {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 {
+		days = 30
+	}
+	if days > 366 {
+		days = 366
+	}
+	return days
 }
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/dto"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/usecases"
+	http "system-portal/internal/shared/response"
+	"system-portal/pkg/logger"
+)
+
+type ElevationHandler struct{ uc usecases.ElevationUsecase }
+
+func NewElevationHandler(u usecases.ElevationUsecase) *ElevationHandler {
+	return &ElevationHandler{uc: u}
+}
+
+// RequestElevation godoc
+// @Summary Request temporary elevation
+// @Description Request a permission or the permissions of a group for a limited time. Matching auto-approve rules grant it immediately; otherwise it waits for an approver.
+// @Tags Elevation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ElevationRequest true "Elevation request"
+// @Success 201 {object} response.SuccessResponse{data=dto.ElevationResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/elevations [post]
+func (h *ElevationHandler) RequestElevation(c *gin.Context) {
+	var req dto.ElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	// Elevation is for people, so API tokens can neither request nor use it
+	if _, isToken := c.Get("apiTokenID"); isToken {
+		http.RespondWithForbidden(c, "elevation requires an interactive session")
+		return
+	}
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	g, err := h.uc.Request(c.Request.Context(), actor, usecases.ElevationRequest{
+		Permission:    req.Permission,
+		VPNGroupScope: req.VPNGroupScope,
+		GroupID:       req.GroupID,
+		Minutes:       req.Minutes,
+		Justification: req.Justification,
+	})
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusCreated, dto.NewElevationResponse(g))
+}
+
+// ListElevations godoc
+// @Summary List elevation grants
+// @Tags Elevation
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param userId query string false "Filter by user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=[]dto.ElevationResponse}
+// @Router /api/portal/elevations [get]
+type elevationQuery struct {
+	Status string `form:"status"`
+	UserID string `form:"userId"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=20"`
+}
+
+func (h *ElevationHandler) ListElevations(c *gin.Context) {
+	var q elevationQuery
+	_ = c.ShouldBindQuery(&q)
+	filter := &entities.ElevationFilter{Status: q.Status, Page: q.Page, Limit: q.Limit}
+	if q.UserID != "" {
+		id, err := uuid.Parse(q.UserID)
+		if err != nil {
+			http.RespondWithBadRequest(c, "invalid userId")
+			return
+		}
+		filter.UserID = id
+	}
+	h.list(c, filter)
+}
+
+// ListMyElevations godoc
+// @Summary List my elevation grants
+// @Tags Elevation
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=[]dto.ElevationResponse}
+// @Router /api/portal/elevations/mine [get]
+func (h *ElevationHandler) ListMyElevations(c *gin.Context) {
+	var q elevationQuery
+	_ = c.ShouldBindQuery(&q)
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	h.list(c, &entities.ElevationFilter{UserID: actor.UserID, Status: q.Status, Page: q.Page, Limit: q.Limit})
+}
+
+func (h *ElevationHandler) list(c *gin.Context, filter *entities.ElevationFilter) {
+	items, total, err := h.uc.List(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to list elevation grants")
+		http.RespondWithInternalError(c, "failed to list elevation grants")
+		return
+	}
+	out := make([]dto.ElevationResponse, 0, len(items))
+	for _, g := range items {
+		out = append(out, dto.NewElevationResponse(g))
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"grants": out, "total": total, "page": filter.Page, "limit": filter.Limit})
+}
+
+// ApproveElevation godoc
+// @Summary Approve elevation request
+// @Description The grant starts now and lasts the requested duration. Approvers must be another user who holds the requested permission, or who manages portal users for group requests.
+// @Tags Elevation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Elevation grant ID"
+// @Param request body dto.ElevationReviewRequest false "Review comment"
+// @Success 200 {object} response.SuccessResponse{data=dto.ElevationResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/elevations/{id}/approve [post]
+func (h *ElevationHandler) ApproveElevation(c *gin.Context) {
+	h.review(c, h.uc.Approve)
+}
+
+// RejectElevation godoc
+// @Summary Reject elevation request
+// @Tags Elevation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Elevation grant ID"
+// @Param request body dto.ElevationReviewRequest false "Review comment"
+// @Success 200 {object} response.SuccessResponse{data=dto.ElevationResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/elevations/{id}/reject [post]
+func (h *ElevationHandler) RejectElevation(c *gin.Context) {
+	h.review(c, h.uc.Reject)
+}
+
+// RevokeElevation godoc
+// @Summary Revoke elevation
+// @Description End an active grant early or withdraw a pending request. Users may revoke their own; others need portal.approve_elevations.
+// @Tags Elevation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Elevation grant ID"
+// @Param request body dto.ElevationReviewRequest false "Reason"
+// @Success 200 {object} response.SuccessResponse{data=dto.ElevationResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/portal/elevations/{id}/revoke [post]
+func (h *ElevationHandler) RevokeElevation(c *gin.Context) {
+	h.review(c, h.uc.Revoke)
+}
+
+type elevationReviewFunc func(ctx context.Context, id uuid.UUID, actor usecases.ApprovalActor, comment string) (*entities.ElevationGrant, error)
+
+func (h *ElevationHandler) review(c *gin.Context, fn elevationReviewFunc) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	var req dto.ElevationReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			http.RespondWithBadRequest(c, "invalid request")
+			return
+		}
+	}
+	if _, isToken := c.Get("apiTokenID"); isToken {
+		http.RespondWithForbidden(c, "elevation requires an interactive session")
+		return
+	}
+	actor, ok := approvalActor(c)
+	if !ok {
+		http.RespondWithUnauthorized(c, "session required")
+		return
+	}
+	g, err := fn(c.Request.Context(), id, actor, req.Comment)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, dto.NewElevationResponse(g))
+}
+
+func (h *ElevationHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrElevationNotFound):
+		http.RespondWithNotFound(c, err.Error())
+	case errors.Is(err, usecases.ErrElevationTarget),
+		errors.Is(err, usecases.ErrElevationDuration),
+		errors.Is(err, usecases.ErrElevationUnknownPerm),
+		errors.Is(err, usecases.ErrElevationUnknownGroup),
+		errors.Is(err, usecases.ErrElevationScope):
+		http.RespondWithBadRequest(c, err.Error())
+	case errors.Is(err, usecases.ErrElevationSelfReview),
+		errors.Is(err, usecases.ErrElevationNotAuthorized):
+		http.RespondWithForbidden(c, err.Error())
+	case errors.Is(err, usecases.ErrElevationClosed),
+		errors.Is(err, usecases.ErrElevationNotActive):
+		http.RespondWithConflict(c, err.Error())
+	default:
+		logger.Log.WithError(err).Error("elevation operation failed")
+		http.RespondWithInternalError(c, "elevation operation failed")
+	}
+}
@@ -171,6 +171,9 @@ func (h *GroupHandler) GetGroupPermissions(c *gin.Context) {
 
 type updatePermsRequest struct {
 	PermissionIDs []uuid.UUID `json:"permission_ids"`
+	// VPNGroupScopes limits openvpn grants to VPN group names or patterns
+	// such as "eng-*", keyed by permission ID. Omit it to keep current scopes.
+	VPNGroupScopes map[uuid.UUID][]string `json:"vpn_group_scopes"`
 }
 
 // UpdateGroupPermissions godoc
@@ -180,7 +183,7 @@ type updatePermsRequest struct {
 // @Accept json
 // @Produce json
 // @Param id path string true "Group ID"
-// @Param request body updatePermsRequest true "Permission IDs"
+// @Param request body updatePermsRequest true "Permission IDs and optional VPN group scopes"
 // @Success 200 {object} response.SuccessResponse
 // @Router /api/portal/groups/{id}/permissions [put]
 func (h *GroupHandler) UpdateGroupPermissions(c *gin.Context) {
@@ -194,7 +197,7 @@ func (h *GroupHandler) UpdateGroupPermissions(c *gin.Context) {
 		http.RespondWithBadRequest(c, "invalid request")
 		return
 	}
-	if err := h.uc.UpdatePermissions(c.Request.Context(), id, req.PermissionIDs); err != nil {
+	if err := h.uc.UpdatePermissions(c.Request.Context(), id, req.PermissionIDs, req.VPNGroupScopes); err != nil {
 		http.RespondWithBadRequest(c, err.Error())
 		return
 	}
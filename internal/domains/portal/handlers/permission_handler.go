@@ -107,3 +107,28 @@ func (h *PermissionHandler) DeletePermission(c *gin.Context) {
 	}
 	httpresp.RespondWithMessage(c, nethttp.StatusOK, "deleted")
 }
+
+// GetCacheStats godoc
+// @Summary Permission cache statistics
+// @Description Hit and miss counters of the in-memory group permission cache
+// @Tags Permissions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=entities.PermissionCacheStats}
+// @Router /api/portal/permissions/cache [get]
+func (h *PermissionHandler) GetCacheStats(c *gin.Context) {
+	httpresp.RespondWithSuccess(c, nethttp.StatusOK, h.uc.CacheStats())
+}
+
+// FlushCache godoc
+// @Summary Flush permission cache
+// @Description Drop cached group permissions on every replica
+// @Tags Permissions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.SuccessResponse
+// @Router /api/portal/permissions/cache [delete]
+func (h *PermissionHandler) FlushCache(c *gin.Context) {
+	h.uc.InvalidateCache(c.Request.Context())
+	httpresp.RespondWithMessage(c, nethttp.StatusOK, "permission cache flushed")
+}
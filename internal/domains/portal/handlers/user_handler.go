@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	nethttp "net/http"
 	"time"
 
+	authdto "system-portal/internal/domains/auth/dto"
+	authusecases "system-portal/internal/domains/auth/usecases"
 	"system-portal/internal/domains/portal/dto"
 	"system-portal/internal/domains/portal/entities"
 	"system-portal/internal/domains/portal/usecases"
@@ -14,10 +17,14 @@ import (
 )
 
 type UserHandler struct {
-	uc usecases.UserUsecase
+	uc       usecases.UserUsecase
+	sessions authusecases.SessionUsecase
+	lockout  authusecases.LockoutUsecase
 }
 
-func NewUserHandler(u usecases.UserUsecase) *UserHandler { return &UserHandler{uc: u} }
+func NewUserHandler(u usecases.UserUsecase, s authusecases.SessionUsecase, l authusecases.LockoutUsecase) *UserHandler {
+	return &UserHandler{uc: u, sessions: s, lockout: l}
+}
 
 // ListUsers godoc
 // @Summary List portal users
@@ -43,12 +50,14 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	resp := make([]dto.PortalUserResponse, 0, len(users))
 	for _, u := range users {
 		resp = append(resp, dto.PortalUserResponse{
-			ID:       u.ID,
-			Username: u.Username,
-			Email:    u.Email,
-			FullName: u.FullName,
-			GroupID:  u.GroupID,
-			IsActive: u.IsActive,
+			ID:         u.ID,
+			Username:   u.Username,
+			Email:      u.Email,
+			FullName:   u.FullName,
+			GroupID:    u.GroupID,
+			GroupIDs:   u.GroupIDs,
+			IsActive:   u.IsActive,
+			AuthSource: u.AuthSource,
 		})
 	}
 	http.RespondWithSuccess(c, nethttp.StatusOK, gin.H{"users": resp, "total": total, "page": filter.Page, "limit": filter.Limit})
@@ -72,15 +81,17 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 	user := &entities.PortalUser{
-		ID:        uuid.New(),
-		Username:  req.Username,
-		Email:     req.Email,
-		FullName:  req.FullName,
-		Password:  req.Password,
-		GroupID:   req.GroupID,
-		IsActive:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:         uuid.New(),
+		Username:   req.Username,
+		Email:      req.Email,
+		FullName:   req.FullName,
+		Password:   req.Password,
+		GroupID:    req.GroupID,
+		GroupIDs:   req.GroupIDs,
+		IsActive:   true,
+		AuthSource: req.AuthSource,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
 	if err := h.uc.Create(c.Request.Context(), user); err != nil {
 		http.RespondWithBadRequest(c, err.Error())
@@ -111,14 +122,23 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		http.RespondWithNotFound(c, "not found")
 		return
 	}
-	http.RespondWithSuccess(c, nethttp.StatusOK, dto.PortalUserResponse{
-		ID:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-		FullName: u.FullName,
-		GroupID:  u.GroupID,
-		IsActive: u.IsActive,
-	})
+	resp := dto.PortalUserResponse{
+		ID:         u.ID,
+		Username:   u.Username,
+		Email:      u.Email,
+		FullName:   u.FullName,
+		GroupID:    u.GroupID,
+		GroupIDs:   u.GroupIDs,
+		IsActive:   u.IsActive,
+		AuthSource: u.AuthSource,
+	}
+	if a, err := h.lockout.Status(c.Request.Context(), u.Username); err == nil && a != nil {
+		resp.FailedLogins = a.Failures
+		if a.LockedUntil != nil && time.Now().Before(*a.LockedUntil) {
+			resp.LockedUntil = a.LockedUntil
+		}
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, resp)
 }
 
 // UpdateUser godoc
@@ -159,6 +179,36 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	http.RespondWithMessage(c, nethttp.StatusOK, "updated")
 }
 
+// UpdateUserGroups godoc
+// @Summary Set portal user groups
+// @Description Replace the groups a portal user belongs to. The user's permissions are the union of their groups' grants; existing access tokens must be refreshed to pick up the change.
+// @Tags Portal Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.PortalUserGroupsRequest true "Group memberships"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/portal/users/{id}/groups [put]
+func (h *UserHandler) UpdateUserGroups(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return
+	}
+	var req dto.PortalUserGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		http.RespondWithBadRequest(c, "invalid request")
+		return
+	}
+	if err := h.uc.SetGroups(c.Request.Context(), id, req.PrimaryGroupID, req.GroupIDs); err != nil {
+		http.RespondWithBadRequest(c, err.Error())
+		return
+	}
+	http.RespondWithMessage(c, nethttp.StatusOK, "updated")
+}
+
 // DeleteUser godoc
 // @Summary Delete portal user
 // @Description Remove a portal user
@@ -211,3 +261,134 @@ func (h *UserHandler) DeactivateUser(c *gin.Context) { http.RespondWithMessage(c
 // @Success 200 {object} response.SuccessResponse
 // @Router /api/portal/users/{id}/reset-password [put]
 func (h *UserHandler) ResetPassword(c *gin.Context) { http.RespondWithMessage(c, 200, "ok") }
+
+// ListUserSessions godoc
+// @Summary List portal user sessions
+// @Description List active sessions of a portal user
+// @Tags Portal Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse{data=[]authdto.SessionResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/portal/users/{id}/sessions [get]
+func (h *UserHandler) ListUserSessions(c *gin.Context) {
+	id, ok := h.existingUserID(c)
+	if !ok {
+		return
+	}
+	sessions, err := h.sessions.ListUserSessions(c.Request.Context(), id)
+	if err != nil {
+		http.RespondWithInternalError(c, "failed to list sessions")
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, authdto.NewSessionResponses(sessions, uuid.Nil))
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all portal user sessions
+// @Description Force logout of a portal user from every device
+// @Tags Portal Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse{data=authdto.RevokeSessionsResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/portal/users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	id, ok := h.existingUserID(c)
+	if !ok {
+		return
+	}
+	n, err := h.sessions.RevokeUserSessions(c.Request.Context(), id, uuid.Nil)
+	if err != nil {
+		http.RespondWithInternalError(c, "failed to revoke sessions")
+		return
+	}
+	http.RespondWithSuccess(c, nethttp.StatusOK, authdto.RevokeSessionsResponse{Revoked: n})
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a portal user session
+// @Tags Portal Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/portal/users/{id}/sessions/{sessionId} [delete]
+func (h *UserHandler) RevokeUserSession(c *gin.Context) {
+	id, ok := h.existingUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid session id")
+		return
+	}
+	if err := h.sessions.RevokeUserSession(c.Request.Context(), id, sessionID); err != nil {
+		if errors.Is(err, authusecases.ErrSessionNotFound) {
+			http.RespondWithNotFound(c, "session not found")
+			return
+		}
+		http.RespondWithInternalError(c, "failed to revoke session")
+		return
+	}
+	http.RespondWithMessage(c, nethttp.StatusOK, "session revoked")
+}
+
+// UnlockUser godoc
+// @Summary Unlock portal user
+// @Description Clear failed login attempts and any brute-force lockout of a portal user
+// @Tags Portal Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/portal/users/{id}/unlock [put]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	u, ok := h.existingUser(c)
+	if !ok {
+		return
+	}
+	if err := h.lockout.Unlock(c.Request.Context(), u.Username); err != nil {
+		http.RespondWithInternalError(c, "failed to unlock user")
+		return
+	}
+	http.RespondWithMessage(c, nethttp.StatusOK, "unlocked")
+}
+
+// existingUserID parses the :id parameter and checks the user exists,
+// writing the error response itself when it does not.
+func (h *UserHandler) existingUserID(c *gin.Context) (uuid.UUID, bool) {
+	u, ok := h.existingUser(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return u.ID, true
+}
+
+func (h *UserHandler) existingUser(c *gin.Context) (*entities.PortalUser, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		http.RespondWithBadRequest(c, "invalid id")
+		return nil, false
+	}
+	u, err := h.uc.Get(c.Request.Context(), id)
+	if err != nil {
+		http.RespondWithInternalError(c, "failed to load user")
+		return nil, false
+	}
+	if u == nil {
+		http.RespondWithNotFound(c, "not found")
+		return nil, false
+	}
+	return u, true
+}
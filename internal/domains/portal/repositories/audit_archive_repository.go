@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// AuditArchiveRepository selects rows past retention, removes them once
+// archived and records the archives so they can be restored.
+type AuditArchiveRepository interface {
+	// TryLock takes the retention lock shared by all instances. It reports
+	// false when another instance holds it, otherwise release frees it.
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+	// ExpiredAuditLogs returns up to limit expired entries in chain order
+	ExpiredAuditLogs(ctx context.Context, cutoffs entities.RetentionCutoffs, limit int) ([]*entities.AuditLog, error)
+	// PurgeAuditLogs records the archive and deletes its entries, leaving
+	// tombstones for chained ones
+	PurgeAuditLogs(ctx context.Context, archive *entities.AuditArchive, logs []*entities.AuditLog) error
+	// ExpiredSessions returns up to limit ended sessions last used before cutoff
+	ExpiredSessions(ctx context.Context, cutoff time.Time, limit int) ([]*entities.ArchivedSession, error)
+	PurgeSessions(ctx context.Context, archive *entities.AuditArchive, ids []uuid.UUID) error
+	// RestoreAuditLogs re-inserts archived entries with their chain fields
+	// and returns how many were missing from the log
+	RestoreAuditLogs(ctx context.Context, archive *entities.AuditArchive, logs []*entities.AuditLog, at time.Time) (int, error)
+	List(ctx context.Context, source string) ([]*entities.AuditArchive, error)
+	GetByName(ctx context.Context, name string) (*entities.AuditArchive, error)
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// AuditDeadLetterRepository keeps audit entries that could not be forwarded.
+type AuditDeadLetterRepository interface {
+	Add(ctx context.Context, d *entities.AuditDeadLetter) error
+	// List returns up to limit dead letters of the given sinks, least
+	// recently tried first
+	List(ctx context.Context, sinks []string, limit int) ([]*entities.AuditDeadLetter, error)
+	// Failed records another unsuccessful redelivery
+	Failed(ctx context.Context, id uuid.UUID, reason string) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
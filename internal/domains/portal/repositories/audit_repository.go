@@ -8,7 +8,31 @@ import (
 )
 
 type AuditRepository interface {
+	// Add appends the entry to the hash chain, setting Seq, PrevHash and Hash
 	Add(ctx context.Context, log *entities.AuditLog) error
 	List(ctx context.Context, filter *entities.AuditFilter) ([]*entities.AuditLog, int, error)
+	// Search returns a page of filtered entries and the cursor of the next
+	// page, empty on the last one
+	Search(ctx context.Context, filter *entities.AuditFilter) ([]*entities.AuditLog, string, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error)
+	// ActionTimeline counts the filtered entries per time bucket for the
+	// q.Top most frequent actions; the rest are counted under "other"
+	ActionTimeline(ctx context.Context, filter *entities.AuditFilter, q entities.AuditStatsQuery) ([]entities.AuditSeries, error)
+	// Each calls fn for every filtered entry, oldest first, ignoring
+	// pagination; it stops at the first error fn returns
+	Each(ctx context.Context, filter *entities.AuditFilter, fn func(*entities.AuditLog) error) error
+	// Stats counts the filtered entries overall, per key and per time bucket
+	Stats(ctx context.Context, filter *entities.AuditFilter, q entities.AuditStatsQuery) (*entities.AuditStats, error)
+	// ChainRange returns up to limit entries after afterSeq in chain order
+	ChainRange(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditLog, error)
+	// ChainHead returns the last chained entry's seq and hash, zero when empty
+	ChainHead(ctx context.Context) (int64, string, error)
+}
+
+// AuditCheckpointRepository stores signed checkpoints of the audit chain.
+type AuditCheckpointRepository interface {
+	Create(ctx context.Context, cp *entities.AuditCheckpoint) error
+	// List returns up to limit checkpoints after afterSeq, oldest first
+	List(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditCheckpoint, error)
+	Latest(ctx context.Context) (*entities.AuditCheckpoint, error)
 }
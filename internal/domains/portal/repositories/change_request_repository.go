@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ChangeRequestRepository stores operations awaiting approval.
+type ChangeRequestRepository interface {
+	Create(ctx context.Context, cr *entities.ChangeRequest) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.ChangeRequest, error)
+	List(ctx context.Context, filter *entities.ChangeRequestFilter) ([]*entities.ChangeRequest, int, error)
+	// Transition saves the status, review and outcome of cr provided it is
+	// still in status from, reporting whether it was.
+	Transition(ctx context.Context, cr *entities.ChangeRequest, from string) (bool, error)
+	// ExpirePending marks pending requests that expired before now.
+	ExpirePending(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ElevationRepository stores time-bound elevation grants.
+type ElevationRepository interface {
+	Create(ctx context.Context, g *entities.ElevationGrant) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.ElevationGrant, error)
+	List(ctx context.Context, filter *entities.ElevationFilter) ([]*entities.ElevationGrant, int, error)
+	// ListActive returns the grants of a user that are active at now.
+	ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entities.ElevationGrant, error)
+	// Transition saves the status and review of g provided it is still in
+	// status from, reporting whether it was.
+	Transition(ctx context.Context, g *entities.ElevationGrant, from string) (bool, error)
+	// ExpireDue ends active grants that expired and pending requests created
+	// before pendingBefore, returning the grants it changed.
+	ExpireDue(ctx context.Context, now, pendingBefore time.Time) ([]*entities.ElevationGrant, error)
+}
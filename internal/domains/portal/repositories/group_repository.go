@@ -12,6 +12,8 @@ type GroupRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.PortalGroup, error)
 	GetByName(ctx context.Context, name string) (*entities.PortalGroup, error)
        List(ctx context.Context, filter *entities.GroupFilter) ([]*entities.PortalGroup, int, error)
+	// ListByUser returns every group the user belongs to by name
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.PortalGroup, error)
 	Update(ctx context.Context, group *entities.PortalGroup) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
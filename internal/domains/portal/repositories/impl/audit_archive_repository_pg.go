@@ -0,0 +1,270 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+type pgAuditArchiveRepo struct{ db *sql.DB }
+
+func NewAuditArchiveRepositoryPG(db *sql.DB) repositories.AuditArchiveRepository {
+	return &pgAuditArchiveRepo{db: db}
+}
+
+// retentionLock keeps replicas from archiving the same rows twice.
+const retentionLock = 0x61756469745f7274
+
+// TryLock holds a session advisory lock on a dedicated connection, so it
+// spans the separate transactions of a retention run.
+func (r *pgAuditArchiveRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, int64(retentionLock)).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	release := func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, int64(retentionLock))
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+const auditArchiveColumns = `id, name, source, row_count, sha256, from_time, to_time,
+                        COALESCE(min_seq, 0), COALESCE(max_seq, 0), created_at, restored_at`
+
+func (r *pgAuditArchiveRepo) ExpiredAuditLogs(ctx context.Context, c entities.RetentionCutoffs, limit int) ([]*entities.AuditLog, error) {
+	args := []interface{}{c.HoldRestoredUntil}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	var expiry strings.Builder
+	expiry.WriteString("CASE")
+	for resourceType, cutoff := range c.ResourceTypes {
+		expiry.WriteString(" WHEN resource_type = " + arg(resourceType) + " THEN ")
+		if cutoff == nil {
+			expiry.WriteString("FALSE")
+		} else {
+			expiry.WriteString("created_at < " + arg(*cutoff))
+		}
+	}
+	if c.Default == nil {
+		expiry.WriteString(" ELSE FALSE END")
+	} else {
+		expiry.WriteString(" ELSE created_at < " + arg(*c.Default) + " END")
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditColumns+` FROM audit_logs
+                 WHERE (restored_at IS NULL OR restored_at < $1) AND `+expiry.String()+`
+                 ORDER BY seq LIMIT `+arg(limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var logs []*entities.AuditLog
+	for rows.Next() {
+		a, err := scanAudit(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, a)
+	}
+	return logs, rows.Err()
+}
+
+func (r *pgAuditArchiveRepo) PurgeAuditLogs(ctx context.Context, archive *entities.AuditArchive, logs []*entities.AuditLog) error {
+	ids := make([]string, 0, len(logs))
+	var seqs []int64
+	var tombIDs, prevs, hashes []string
+	for _, a := range logs {
+		ids = append(ids, a.ID.String())
+		if a.Hash != "" {
+			seqs = append(seqs, a.Seq)
+			tombIDs = append(tombIDs, a.ID.String())
+			prevs = append(prevs, a.PrevHash)
+			hashes = append(hashes, a.Hash)
+		}
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertArchive(ctx, tx, archive); err != nil {
+		return err
+	}
+	if len(seqs) > 0 {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO audit_log_tombstones (seq, id, prev_hash, hash, archive_id)
+                         SELECT s, i, p, h, $5 FROM unnest($1::bigint[], $2::uuid[], $3::text[], $4::text[]) AS t(s, i, p, h)
+                         ON CONFLICT (seq) DO NOTHING`,
+			pq.Array(seqs), pq.Array(tombIDs), pq.Array(prevs), pq.Array(hashes), archive.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM audit_logs WHERE id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *pgAuditArchiveRepo) ExpiredSessions(ctx context.Context, cutoff time.Time, limit int) ([]*entities.ArchivedSession, error) {
+	// Token hashes are left out; they are of no use once the session ended
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, created_at, to_jsonb(s) - 'token_hash' - 'refresh_token_hash'
+                 FROM user_sessions s
+                 WHERE (is_active = FALSE OR refresh_expires_at < NOW())
+                   AND GREATEST(COALESCE(last_activity, created_at), created_at) < $1
+                 ORDER BY created_at LIMIT $2`, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*entities.ArchivedSession
+	for rows.Next() {
+		var s entities.ArchivedSession
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.Data); err != nil {
+			return nil, err
+		}
+		out = append(out, &s)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgAuditArchiveRepo) PurgeSessions(ctx context.Context, archive *entities.AuditArchive, ids []uuid.UUID) error {
+	strIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strIDs = append(strIDs, id.String())
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertArchive(ctx, tx, archive); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_sessions WHERE id = ANY($1::uuid[])`, pq.Array(strIDs)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *pgAuditArchiveRepo) RestoreAuditLogs(ctx context.Context, archive *entities.AuditArchive, logs []*entities.AuditLog, at time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	restored := 0
+	var seqs []int64
+	for _, a := range logs {
+		params, body, err := auditContextArgs(a)
+		if err != nil {
+			return 0, err
+		}
+		// The user may have been deleted since; user_id is not part of the hash
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO audit_logs (
+                               id, user_id, username, user_group, action, resource_type,
+                               resource_name, ip_address, success, created_at,
+                               http_method, route, path_params, request_body, status_code, duration_ms, user_agent, request_id,
+                               seq, prev_hash, hash, restored_at)
+                       VALUES ($1,(SELECT id FROM users WHERE id = $2),$3,$4,$5,$6,$7,NULLIF($8, '')::inet,$9,$10,
+                               NULLIF($11, ''),NULLIF($12, ''),$13,$14,NULLIF($15, 0),NULLIF($16, 0),NULLIF($17, ''),NULLIF($18, ''),
+                               $19,NULLIF($20, ''),NULLIF($21, ''),$22)
+                       ON CONFLICT DO NOTHING`,
+			a.ID, a.UserID, a.Username, a.UserGroup, a.Action, a.ResourceType,
+			a.ResourceName, a.IPAddress, a.Success, a.CreatedAt,
+			a.Method, a.Route, params, body, a.StatusCode, a.DurationMs, a.UserAgent, a.RequestID,
+			a.Seq, a.PrevHash, a.Hash, at,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			restored++
+			seqs = append(seqs, a.Seq)
+		}
+	}
+	if len(seqs) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM audit_log_tombstones WHERE seq = ANY($1::bigint[])`, pq.Array(seqs)); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_archives SET restored_at=$2 WHERE id=$1`, archive.ID, at); err != nil {
+		return 0, err
+	}
+	return restored, tx.Commit()
+}
+
+func (r *pgAuditArchiveRepo) List(ctx context.Context, source string) ([]*entities.AuditArchive, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditArchiveColumns+` FROM audit_archives
+                 WHERE ($1 = '' OR source = $1) ORDER BY created_at`, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*entities.AuditArchive
+	for rows.Next() {
+		a, err := scanAuditArchive(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgAuditArchiveRepo) GetByName(ctx context.Context, name string) (*entities.AuditArchive, error) {
+	a, err := scanAuditArchive(r.db.QueryRowContext(ctx,
+		`SELECT `+auditArchiveColumns+` FROM audit_archives WHERE name=$1`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func insertArchive(ctx context.Context, tx *sql.Tx, a *entities.AuditArchive) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_archives (id, name, source, row_count, sha256, from_time, to_time, min_seq, max_seq, created_at)
+               VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8, 0),NULLIF($9, 0),$10)`,
+		a.ID, a.Name, a.Source, a.Rows, a.SHA256, a.FromTime, a.ToTime, a.MinSeq, a.MaxSeq, a.CreatedAt)
+	return err
+}
+
+func scanAuditArchive(row rowScanner) (*entities.AuditArchive, error) {
+	var a entities.AuditArchive
+	var from, to sql.NullTime
+	var restored sql.NullTime
+	if err := row.Scan(&a.ID, &a.Name, &a.Source, &a.Rows, &a.SHA256, &from, &to,
+		&a.MinSeq, &a.MaxSeq, &a.CreatedAt, &restored); err != nil {
+		return nil, err
+	}
+	a.FromTime, a.ToTime = from.Time, to.Time
+	if restored.Valid {
+		a.RestoredAt = &restored.Time
+	}
+	return &a, nil
+}
@@ -0,0 +1,63 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+type pgAuditCheckpointRepo struct{ db *sql.DB }
+
+func NewAuditCheckpointRepositoryPG(db *sql.DB) repositories.AuditCheckpointRepository {
+	return &pgAuditCheckpointRepo{db: db}
+}
+
+const auditCheckpointColumns = `id, seq, hash, key_id, public_key, signature, created_at`
+
+func (r *pgAuditCheckpointRepo) Create(ctx context.Context, cp *entities.AuditCheckpoint) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO audit_checkpoints (`+auditCheckpointColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		cp.ID, cp.Seq, cp.Hash, cp.KeyID, cp.PublicKey, cp.Signature, cp.CreatedAt)
+	return err
+}
+
+func (r *pgAuditCheckpointRepo) List(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditCheckpoint, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditCheckpointColumns+` FROM audit_checkpoints WHERE seq > $1 ORDER BY seq, created_at LIMIT $2`,
+		afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*entities.AuditCheckpoint
+	for rows.Next() {
+		cp, err := scanAuditCheckpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cp)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgAuditCheckpointRepo) Latest(ctx context.Context) (*entities.AuditCheckpoint, error) {
+	cp, err := scanAuditCheckpoint(r.db.QueryRowContext(ctx,
+		`SELECT `+auditCheckpointColumns+` FROM audit_checkpoints ORDER BY seq DESC, created_at DESC LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func scanAuditCheckpoint(row rowScanner) (*entities.AuditCheckpoint, error) {
+	var cp entities.AuditCheckpoint
+	if err := row.Scan(&cp.ID, &cp.Seq, &cp.Hash, &cp.KeyID, &cp.PublicKey, &cp.Signature, &cp.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
@@ -0,0 +1,58 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+type pgAuditDeadLetterRepo struct{ db *sql.DB }
+
+func NewAuditDeadLetterRepositoryPG(db *sql.DB) repositories.AuditDeadLetterRepository {
+	return &pgAuditDeadLetterRepo{db: db}
+}
+
+const auditDeadLetterColumns = `id, sink, audit_id, payload, error, attempts, created_at, last_attempt_at`
+
+func (r *pgAuditDeadLetterRepo) Add(ctx context.Context, d *entities.AuditDeadLetter) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO audit_dead_letters (`+auditDeadLetterColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		d.ID, d.Sink, d.AuditID, string(d.Payload), d.Error, d.Attempts, d.CreatedAt, d.LastAttemptAt)
+	return err
+}
+
+func (r *pgAuditDeadLetterRepo) List(ctx context.Context, sinks []string, limit int) ([]*entities.AuditDeadLetter, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditDeadLetterColumns+` FROM audit_dead_letters
+                 WHERE sink = ANY($1) ORDER BY last_attempt_at, created_at LIMIT $2`, pq.Array(sinks), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*entities.AuditDeadLetter
+	for rows.Next() {
+		var d entities.AuditDeadLetter
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.Sink, &d.AuditID, &payload, &d.Error, &d.Attempts, &d.CreatedAt, &d.LastAttemptAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		out = append(out, &d)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgAuditDeadLetterRepo) Failed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE audit_dead_letters SET error=$2, attempts=attempts+1, last_attempt_at=NOW() WHERE id=$1`, id, reason)
+	return err
+}
+
+func (r *pgAuditDeadLetterRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM audit_dead_letters WHERE id=$1`, id)
+	return err
+}
@@ -3,11 +3,15 @@ package impl
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"system-portal/internal/domains/portal/entities"
 	"system-portal/internal/domains/portal/repositories"
 )
@@ -18,6 +22,28 @@ func NewAuditRepositoryPG(db *sql.DB) repositories.AuditRepository {
 	return &pgAuditRepo{db: db}
 }
 
+// auditColumns selects an audit entry; context columns are empty for
+// entries recorded outside an API call.
+const auditColumns = `id, user_id, username, user_group, action, resource_type,
+                        resource_name, COALESCE(host(ip_address), ''), success, created_at,
+                        COALESCE(http_method, ''), COALESCE(route, ''), path_params, request_body,
+                        COALESCE(status_code, 0), COALESCE(duration_ms, 0), COALESCE(user_agent, ''), COALESCE(request_id, ''),
+                        seq, COALESCE(prev_hash, ''), COALESCE(hash, ''), FALSE`
+
+// tombstoneColumns reads an archived entry in the shape of auditColumns.
+const tombstoneColumns = `id, NULL::uuid, '', '', '', '', '', '', FALSE, to_timestamp(0),
+                        '', '', NULL::jsonb, NULL::jsonb, 0, 0, '', '',
+                        seq, prev_hash, hash, TRUE`
+
+// chainHeadQuery finds the last link of the chain, which may be archived.
+const chainHeadQuery = `SELECT seq, hash FROM (
+                        SELECT seq, hash FROM audit_logs WHERE hash IS NOT NULL
+                        UNION ALL SELECT seq, hash FROM audit_log_tombstones
+                ) chain ORDER BY seq DESC LIMIT 1`
+
+// auditChainLock serialises appends so each entry links to the one before.
+const auditChainLock = 0x61756469745f6c67
+
 func (r *pgAuditRepo) Add(ctx context.Context, a *entities.AuditLog) error {
 	var userID interface{}
 	if a.UserID == uuid.Nil {
@@ -25,15 +51,78 @@ func (r *pgAuditRepo) Add(ctx context.Context, a *entities.AuditLog) error {
 	} else {
 		userID = a.UserID
 	}
-	_, err := r.db.ExecContext(ctx,
+	params, body, err := auditContextArgs(a)
+	if err != nil {
+		return err
+	}
+	// Postgres keeps microseconds; hash what will be stored
+	a.CreatedAt = a.CreatedAt.Truncate(time.Microsecond)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(auditChainLock)); err != nil {
+		return err
+	}
+	var headSeq int64
+	a.PrevHash = ""
+	err = tx.QueryRowContext(ctx, chainHeadQuery).Scan(&headSeq, &a.PrevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	a.Hash = a.ChainHash()
+	err = tx.QueryRowContext(ctx,
 		`INSERT INTO audit_logs (
                        id, user_id, username, user_group, action, resource_type,
-                       resource_name, ip_address, success, created_at)
-               VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+                       resource_name, ip_address, success, created_at,
+                       http_method, route, path_params, request_body, status_code, duration_ms, user_agent, request_id,
+                       prev_hash, hash)
+               VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8, '')::inet,$9,$10,
+                       NULLIF($11, ''),NULLIF($12, ''),$13,$14,NULLIF($15, 0),NULLIF($16, 0),NULLIF($17, ''),NULLIF($18, ''),
+                       $19,$20)
+               RETURNING seq`,
 		a.ID, userID, a.Username, a.UserGroup, a.Action, a.ResourceType,
 		a.ResourceName, a.IPAddress, a.Success, a.CreatedAt,
-	)
-	return err
+		a.Method, a.Route, params, body, a.StatusCode, a.DurationMs, a.UserAgent, a.RequestID,
+		a.PrevHash, a.Hash,
+	).Scan(&a.Seq)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *pgAuditRepo) ChainRange(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditLog, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+auditColumns+` FROM audit_logs WHERE seq > $1
+                 UNION ALL
+                 SELECT `+tombstoneColumns+` FROM audit_log_tombstones WHERE seq > $1
+                 ORDER BY seq LIMIT $2`, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var logs []*entities.AuditLog
+	for rows.Next() {
+		a, err := scanAudit(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, a)
+	}
+	return logs, rows.Err()
+}
+
+func (r *pgAuditRepo) ChainHead(ctx context.Context) (int64, string, error) {
+	var seq int64
+	var hash string
+	err := r.db.QueryRowContext(ctx, chainHeadQuery).Scan(&seq, &hash)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	return seq, hash, err
 }
 
 func (r *pgAuditRepo) List(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditLog, int, error) {
@@ -42,50 +131,14 @@ func (r *pgAuditRepo) List(ctx context.Context, f *entities.AuditFilter) ([]*ent
 	}
 	f.SetDefaults()
 
-	base := `SELECT id, user_id, username, user_group, action, resource_type,
-                        resource_name, ip_address, success, created_at FROM audit_logs`
+	base := `SELECT ` + auditColumns + ` FROM audit_logs`
 	countBase := `SELECT COUNT(1) FROM audit_logs`
+	where, args := auditWhere(f)
 
-	clauses := []string{}
-	args := []interface{}{}
-	idx := 1
-	if f.Username != "" {
-		clauses = append(clauses, "username=$"+strconv.Itoa(idx))
-		args = append(args, f.Username)
-		idx++
-	}
-	if f.UserGroup != "" {
-		clauses = append(clauses, "user_group=$"+strconv.Itoa(idx))
-		args = append(args, f.UserGroup)
-		idx++
-	}
-	if f.IPAddress != "" {
-		clauses = append(clauses, "ip_address=$"+strconv.Itoa(idx))
-		args = append(args, f.IPAddress)
-		idx++
-	}
-	if f.Resource != "" {
-		clauses = append(clauses, "resource_type=$"+strconv.Itoa(idx))
-		args = append(args, f.Resource)
-		idx++
-	}
-	if f.FromTime != nil {
-		clauses = append(clauses, "created_at >= $"+strconv.Itoa(idx))
-		args = append(args, *f.FromTime)
-		idx++
-	}
-	if f.ToTime != nil {
-		clauses = append(clauses, "created_at <= $"+strconv.Itoa(idx))
-		args = append(args, *f.ToTime)
-		idx++
-	}
-
-	where := ""
-	if len(clauses) > 0 {
-		where = " WHERE " + strings.Join(clauses, " AND ")
+	if !f.ValidSort() {
+		return nil, 0, fmt.Errorf("invalid sort %s %s", f.Sort, f.Order)
 	}
-
-	query := base + where + " ORDER BY created_at DESC" +
+	query := base + where + auditOrderBy(f) +
 		fmt.Sprintf(" LIMIT %d OFFSET %d", f.Limit, f.Offset)
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -94,13 +147,11 @@ func (r *pgAuditRepo) List(ctx context.Context, f *entities.AuditFilter) ([]*ent
 	defer rows.Close()
 	var logs []*entities.AuditLog
 	for rows.Next() {
-		var a entities.AuditLog
-		if err := rows.Scan(&a.ID, &a.UserID, &a.Username, &a.UserGroup,
-			&a.Action, &a.ResourceType, &a.ResourceName, &a.IPAddress,
-			&a.Success, &a.CreatedAt); err != nil {
+		a, err := scanAudit(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		logs = append(logs, &a)
+		logs = append(logs, a)
 	}
 
 	countQuery := countBase + where
@@ -112,21 +163,385 @@ func (r *pgAuditRepo) List(ctx context.Context, f *entities.AuditFilter) ([]*ent
 	return logs, total, nil
 }
 
+// auditExportBatch is how many rows each FETCH of an export cursor returns.
+const auditExportBatch = 1000
+
+// Each reads the filtered entries oldest first through a server-side cursor
+// so exports hold one batch in memory at a time.
+func (r *pgAuditRepo) Each(ctx context.Context, f *entities.AuditFilter, fn func(*entities.AuditLog) error) error {
+	if f == nil {
+		f = &entities.AuditFilter{}
+	}
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	where, args := auditWhere(f)
+	if _, err := tx.ExecContext(ctx,
+		`DECLARE audit_export NO SCROLL CURSOR FOR SELECT `+auditColumns+` FROM audit_logs`+where+` ORDER BY created_at, seq`,
+		args...); err != nil {
+		return err
+	}
+	for {
+		n, err := r.fetch(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+		if n < auditExportBatch {
+			break
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *pgAuditRepo) fetch(ctx context.Context, tx *sql.Tx, fn func(*entities.AuditLog) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`FETCH %d FROM audit_export`, auditExportBatch))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		a, err := scanAudit(rows)
+		if err != nil {
+			return n, err
+		}
+		if err := fn(a); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// Stats aggregates the filtered entries in one scan: GROUPING SETS yields the
+// overall counts, each breakdown and the timeline, and the window keeps the
+// top keys of each breakdown.
+func (r *pgAuditRepo) Stats(ctx context.Context, f *entities.AuditFilter, q entities.AuditStatsQuery) (*entities.AuditStats, error) {
+	if f == nil {
+		f = &entities.AuditFilter{}
+	}
+	q.SetDefaults()
+	if !entities.ValidAuditBucket(q.Bucket) {
+		return nil, fmt.Errorf("invalid time bucket %q", q.Bucket)
+	}
+	where, args := auditWhere(f)
+	n := len(args)
+	args = append(args, q.Bucket, q.Top)
+	query := fmt.Sprintf(`WITH filtered AS (
+                         SELECT action, resource_type, username, COALESCE(user_group, '') AS user_group, success,
+                                date_trunc($%d, created_at AT TIME ZONE 'UTC') AS bucket
+                         FROM audit_logs%s
+                 ), grouped AS (
+                         SELECT CASE WHEN GROUPING(action) = 0 THEN 'action'
+                                     WHEN GROUPING(resource_type) = 0 THEN 'resource_type'
+                                     WHEN GROUPING(username) = 0 THEN 'user'
+                                     WHEN GROUPING(user_group) = 0 THEN 'group'
+                                     WHEN GROUPING(bucket) = 0 THEN 'time'
+                                     ELSE 'total' END AS dim,
+                                COALESCE(action, resource_type, username, user_group, '') AS key, bucket,
+                                COUNT(*) AS total, COUNT(*) FILTER (WHERE NOT success) AS failed
+                         FROM filtered
+                         GROUP BY GROUPING SETS ((), (action), (resource_type), (username), (user_group), (bucket))
+                 )
+                 SELECT dim, key, bucket, total, failed FROM (
+                         SELECT *, row_number() OVER (PARTITION BY dim ORDER BY total DESC, key) AS rank FROM grouped
+                 ) ranked
+                 WHERE dim IN ('total', 'time') OR rank <= $%d
+                 ORDER BY dim, bucket, total DESC, key`, n+1, where, n+2)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := &entities.AuditStats{}
+	for rows.Next() {
+		var dim string
+		var bucket sql.NullTime
+		var c entities.AuditCount
+		if err := rows.Scan(&dim, &c.Key, &bucket, &c.Total, &c.Failed); err != nil {
+			return nil, err
+		}
+		c.Success = c.Total - c.Failed
+		switch dim {
+		case "total":
+			stats.Total, stats.Success, stats.Failed = c.Total, c.Success, c.Failed
+		case "action":
+			stats.ByAction = append(stats.ByAction, c)
+		case "resource_type":
+			stats.ByResourceType = append(stats.ByResourceType, c)
+		case "user":
+			stats.ByUser = append(stats.ByUser, c)
+		case "group":
+			stats.ByGroup = append(stats.ByGroup, c)
+		case "time":
+			stats.Timeline = append(stats.Timeline, entities.AuditBucket{
+				Start: time.Date(bucket.Time.Year(), bucket.Time.Month(), bucket.Time.Day(), bucket.Time.Hour(), 0, 0, 0, time.UTC),
+				Total: c.Total, Success: c.Success, Failed: c.Failed,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	stats.Timeline = fillAuditTimeline(q.Bucket, stats.Timeline)
+	return stats, nil
+}
+
+func (r *pgAuditRepo) ActionTimeline(ctx context.Context, f *entities.AuditFilter, q entities.AuditStatsQuery) ([]entities.AuditSeries, error) {
+	if f == nil {
+		f = &entities.AuditFilter{}
+	}
+	q.SetDefaults()
+	if !entities.ValidAuditBucket(q.Bucket) {
+		return nil, fmt.Errorf("invalid time bucket %q", q.Bucket)
+	}
+	where, args := auditWhere(f)
+	n := len(args)
+	args = append(args, q.Bucket, q.Top, entities.AuditSeriesOther)
+	query := fmt.Sprintf(`WITH filtered AS (
+                         SELECT action, date_trunc($%d, created_at AT TIME ZONE 'UTC') AS bucket
+                         FROM audit_logs%s
+                 ), top AS (
+                         SELECT action FROM filtered GROUP BY action ORDER BY COUNT(*) DESC, action LIMIT $%d
+                 )
+                 SELECT CASE WHEN action IN (SELECT action FROM top) THEN action ELSE $%d END AS key,
+                        bucket, COUNT(*)
+                 FROM filtered GROUP BY 1, 2 ORDER BY 1, 2`, n+1, where, n+2, n+3)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var series []entities.AuditSeries
+	for rows.Next() {
+		var key string
+		var bucket time.Time
+		var count int64
+		if err := rows.Scan(&key, &bucket, &count); err != nil {
+			return nil, err
+		}
+		if len(series) == 0 || series[len(series)-1].Key != key {
+			series = append(series, entities.AuditSeries{Key: key})
+		}
+		cur := &series[len(series)-1]
+		cur.Total += count
+		cur.Buckets = append(cur.Buckets, entities.AuditBucket{
+			Start: time.Date(bucket.Year(), bucket.Month(), bucket.Day(), bucket.Hour(), 0, 0, 0, time.UTC),
+			Total: count,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Busiest first, with the remainder last
+	sort.SliceStable(series, func(i, j int) bool {
+		if (series[i].Key == entities.AuditSeriesOther) != (series[j].Key == entities.AuditSeriesOther) {
+			return series[j].Key == entities.AuditSeriesOther
+		}
+		return series[i].Total > series[j].Total
+	})
+	return series, nil
+}
+
+// fillAuditTimeline adds empty buckets between the first and last one so
+// charts get an evenly spaced series.
+func fillAuditTimeline(bucket string, in []entities.AuditBucket) []entities.AuditBucket {
+	if len(in) < 2 {
+		return in
+	}
+	out := make([]entities.AuditBucket, 0, len(in))
+	next := in[0].Start
+	for _, b := range in {
+		for next.Before(b.Start) {
+			out = append(out, entities.AuditBucket{Start: next})
+			next = entities.NextAuditBucket(bucket, next)
+		}
+		out = append(out, b)
+		next = entities.NextAuditBucket(bucket, b.Start)
+	}
+	return out
+}
+
+// Search returns one page of the filtered entries using keyset pagination:
+// rather than skipping rows, it continues after the cursor's sort value and
+// seq, so deep pages cost the same as the first.
+func (r *pgAuditRepo) Search(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditLog, string, error) {
+	f.SetDefaults()
+	if !f.ValidSort() {
+		return nil, "", fmt.Errorf("invalid sort %s %s", f.Sort, f.Order)
+	}
+	clauses, args := auditConditions(f)
+	if f.Cursor != "" {
+		c, err := entities.DecodeAuditCursor(f.Cursor, f)
+		if err != nil {
+			return nil, "", err
+		}
+		var value interface{} = c.Value
+		if f.Sort == entities.AuditSortCreatedAt {
+			t, err := time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, "", entities.ErrInvalidAuditCursor
+			}
+			value = t
+		}
+		cmp := "<"
+		if f.Order == entities.SortAsc {
+			cmp = ">"
+		}
+		args = append(args, value, c.Seq)
+		clauses = append(clauses, fmt.Sprintf("(%s, seq) %s ($%d, $%d)", f.Sort, cmp, len(args)-1, len(args)))
+	}
+	// One extra row tells whether another page follows
+	query := `SELECT ` + auditColumns + ` FROM audit_logs` + joinWhere(clauses) + auditOrderBy(f) +
+		fmt.Sprintf(" LIMIT %d", f.Limit+1)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var logs []*entities.AuditLog
+	for rows.Next() {
+		a, err := scanAudit(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		logs = append(logs, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	if len(logs) <= f.Limit {
+		return logs, "", nil
+	}
+	logs = logs[:f.Limit]
+	last := logs[len(logs)-1]
+	next := entities.AuditCursor{Sort: f.Sort, Order: f.Order, Seq: last.Seq}
+	switch f.Sort {
+	case entities.AuditSortUsername:
+		next.Value = last.Username
+	case entities.AuditSortAction:
+		next.Value = last.Action
+	case entities.AuditSortResourceType:
+		next.Value = last.ResourceType
+	default:
+		next.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return logs, next.Encode(), nil
+}
+
 func (r *pgAuditRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, username, user_group, action, resource_type,
-                        resource_name, ip_address, success, created_at
-                FROM audit_logs WHERE id=$1`, id)
+	a, err := scanAudit(r.db.QueryRowContext(ctx, `SELECT `+auditColumns+` FROM audit_logs WHERE id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// auditWhere builds the WHERE clause selecting the entries of a filter.
+func auditWhere(f *entities.AuditFilter) (string, []interface{}) {
+	clauses, args := auditConditions(f)
+	return joinWhere(clauses), args
+}
+
+func joinWhere(clauses []string) string {
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(clauses, " AND ")
+}
+
+func auditConditions(f *entities.AuditFilter) ([]string, []interface{}) {
+	clauses := []string{}
+	args := []interface{}{}
+	add := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		clauses = append(clauses, strings.ReplaceAll(clause, "$?", "$"+strconv.Itoa(len(args))))
+	}
+	if len(f.Usernames) > 0 {
+		add("username = ANY($?)", pq.Array(f.Usernames))
+	}
+	if len(f.UserGroups) > 0 {
+		add("user_group = ANY($?)", pq.Array(f.UserGroups))
+	}
+	if len(f.IPAddresses) > 0 {
+		add("ip_address = ANY($?::inet[])", pq.Array(f.IPAddresses))
+	}
+	if len(f.Resources) > 0 {
+		add("resource_type = ANY($?)", pq.Array(f.Resources))
+	}
+	if len(f.Actions) > 0 {
+		add("action = ANY($?)", pq.Array(f.Actions))
+	}
+	if f.Success != nil {
+		add("success = $?", *f.Success)
+	}
+	if f.ResourceName != "" {
+		add(`resource_name ILIKE $? ESCAPE '\'`, "%"+likeEscaper.Replace(f.ResourceName)+"%")
+	}
+	if f.Query != "" {
+		add("search @@ websearch_to_tsquery('simple', $?)", f.Query)
+	}
+	if f.FromTime != nil {
+		add("created_at >= $?", *f.FromTime)
+	}
+	if f.ToTime != nil {
+		add("created_at <= $?", *f.ToTime)
+	}
+	return clauses, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// auditOrderBy orders by the filter's sort column with seq breaking ties,
+// matching the keyset condition of Search.
+func auditOrderBy(f *entities.AuditFilter) string {
+	dir := " DESC"
+	if f.Order == entities.SortAsc {
+		dir = " ASC"
+	}
+	return " ORDER BY " + f.Sort + dir + ", seq" + dir
+}
+
+// auditContextArgs encodes the JSONB request context of an entry, NULL when
+// empty.
+func auditContextArgs(a *entities.AuditLog) (params, body interface{}, err error) {
+	if len(a.PathParams) > 0 {
+		raw, err := json.Marshal(a.PathParams)
+		if err != nil {
+			return nil, nil, err
+		}
+		params = string(raw)
+	}
+	if len(a.RequestBody) > 0 {
+		body = string(a.RequestBody)
+	}
+	return params, body, nil
+}
+
+func scanAudit(row rowScanner) (*entities.AuditLog, error) {
 	var a entities.AuditLog
+	var params, body []byte
 	err := row.Scan(
 		&a.ID, &a.UserID, &a.Username, &a.UserGroup, &a.Action, &a.ResourceType,
 		&a.ResourceName, &a.IPAddress, &a.Success, &a.CreatedAt,
+		&a.Method, &a.Route, &params, &body, &a.StatusCode, &a.DurationMs, &a.UserAgent, &a.RequestID,
+		&a.Seq, &a.PrevHash, &a.Hash, &a.Archived,
 	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
 	if err != nil {
 		return nil, err
 	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &a.PathParams); err != nil {
+			return nil, err
+		}
+	}
+	if len(body) > 0 {
+		a.RequestBody = json.RawMessage(body)
+	}
 	return &a, nil
 }
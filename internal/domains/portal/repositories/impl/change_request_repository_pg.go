@@ -0,0 +1,201 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
+)
+
+// changeRequestColumns selects a change request; nullable columns are
+// mapped to zero values.
+const changeRequestColumns = `id, operation, permission, COALESCE(target, ''), payload, status,
+        requested_by, requested_by_name, COALESCE(requester_group, ''), requester_scope,
+        reviewed_by, COALESCE(reviewed_by_name, ''), COALESCE(review_comment, ''), reviewed_at,
+        result, COALESCE(error, ''), expires_at, executed_at, created_at, updated_at
+        FROM change_requests`
+
+type pgChangeRequestRepo struct {
+	db *sql.DB
+	// key encrypts payloads, which may carry connection passwords
+	key string
+}
+
+func NewChangeRequestRepositoryPG(db *sql.DB, key string) repositories.ChangeRequestRepository {
+	return &pgChangeRequestRepo{db: db, key: key}
+}
+
+func (r *pgChangeRequestRepo) Create(ctx context.Context, cr *entities.ChangeRequest) error {
+	payload := string(cr.Payload)
+	if r.key != "" {
+		enc, err := utils.EncryptString(payload, r.key)
+		if err != nil {
+			return err
+		}
+		payload = enc
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO change_requests (id, operation, permission, target, payload, status, requested_by, requested_by_name,
+                requester_group, requester_scope, expires_at, created_at, updated_at)
+         VALUES ($1,$2,$3,NULLIF($4,''),$5,$6,$7,$8,NULLIF($9,''),$10,$11,$12,$12)`,
+		cr.ID, cr.Operation, cr.Permission, cr.Target, payload, cr.Status, nullUUID(cr.RequestedBy), cr.RequestedByName,
+		cr.RequesterGroup, pq.Array(cr.RequesterScope), cr.ExpiresAt, cr.CreatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("create change request failed")
+	}
+	return err
+}
+
+func (r *pgChangeRequestRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.ChangeRequest, error) {
+	cr, err := r.scan(r.db.QueryRowContext(ctx, `SELECT `+changeRequestColumns+` WHERE id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("get change request failed")
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (r *pgChangeRequestRepo) List(ctx context.Context, f *entities.ChangeRequestFilter) ([]*entities.ChangeRequest, int, error) {
+	if f == nil {
+		f = &entities.ChangeRequestFilter{}
+	}
+	f.SetDefaults()
+
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if f.Status != "" {
+		clauses = append(clauses, "status=$"+strconv.Itoa(idx))
+		args = append(args, f.Status)
+		idx++
+	}
+	if f.Operation != "" {
+		clauses = append(clauses, "operation=$"+strconv.Itoa(idx))
+		args = append(args, f.Operation)
+		idx++
+	}
+	if f.RequestedBy != uuid.Nil {
+		clauses = append(clauses, "requested_by=$"+strconv.Itoa(idx))
+		args = append(args, f.RequestedBy)
+		idx++
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query := `SELECT ` + changeRequestColumns + where + fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d OFFSET %d", f.Limit, f.Offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*entities.ChangeRequest
+	for rows.Next() {
+		cr, err := r.scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, cr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM change_requests`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+func (r *pgChangeRequestRepo) Transition(ctx context.Context, cr *entities.ChangeRequest, from string) (bool, error) {
+	var result interface{}
+	if len(cr.Result) > 0 {
+		result = string(cr.Result)
+	}
+	cr.UpdatedAt = time.Now()
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE change_requests SET status=$3, reviewed_by=$4, reviewed_by_name=NULLIF($5,''), review_comment=NULLIF($6,''),
+                reviewed_at=$7, result=$8, error=NULLIF($9,''), executed_at=$10, updated_at=$11
+         WHERE id=$1 AND status=$2`,
+		cr.ID, from, cr.Status, nullUUID(cr.ReviewedBy), cr.ReviewedByName, cr.ReviewComment,
+		cr.ReviewedAt, result, cr.Error, cr.ExecutedAt, cr.UpdatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("update change request failed")
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *pgChangeRequestRepo) ExpirePending(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE change_requests SET status=$1, updated_at=$3 WHERE status=$2 AND expires_at < $3`,
+		entities.ChangeRequestExpired, entities.ChangeRequestPending, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *pgChangeRequestRepo) scan(row rowScanner) (*entities.ChangeRequest, error) {
+	var cr entities.ChangeRequest
+	var payload string
+	var requestedBy, reviewedBy uuid.NullUUID
+	var reviewedAt, executedAt sql.NullTime
+	var result []byte
+	err := row.Scan(&cr.ID, &cr.Operation, &cr.Permission, &cr.Target, &payload, &cr.Status,
+		&requestedBy, &cr.RequestedByName, &cr.RequesterGroup, pq.Array(&cr.RequesterScope),
+		&reviewedBy, &cr.ReviewedByName, &cr.ReviewComment, &reviewedAt,
+		&result, &cr.Error, &cr.ExpiresAt, &executedAt, &cr.CreatedAt, &cr.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if r.key != "" {
+		plain, err := utils.DecryptString(payload, r.key)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt change request payload: %w", err)
+		}
+		payload = plain
+	}
+	cr.Payload = json.RawMessage(payload)
+	cr.RequestedBy = requestedBy.UUID
+	cr.ReviewedBy = reviewedBy.UUID
+	if reviewedAt.Valid {
+		cr.ReviewedAt = &reviewedAt.Time
+	}
+	if executedAt.Valid {
+		cr.ExecutedAt = &executedAt.Time
+	}
+	if len(result) > 0 {
+		cr.Result = json.RawMessage(result)
+	}
+	return &cr, nil
+}
+
+// nullUUID maps uuid.Nil to NULL for nullable foreign keys.
+func nullUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
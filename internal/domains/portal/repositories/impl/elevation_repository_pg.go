@@ -0,0 +1,175 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+// elevationColumns selects an elevation grant; nullable columns are mapped
+// to zero values.
+const elevationColumns = `id, user_id, username, COALESCE(permission, ''), group_id, COALESCE(group_name, ''),
+        vpn_group_scope, justification, duration_minutes, status, auto_approved,
+        approved_by, COALESCE(approved_by_name, ''), COALESCE(review_comment, ''), reviewed_at,
+        expires_at, ended_at, created_at, updated_at`
+
+type pgElevationRepo struct{ db *sql.DB }
+
+func NewElevationRepositoryPG(db *sql.DB) repositories.ElevationRepository {
+	return &pgElevationRepo{db: db}
+}
+
+func (r *pgElevationRepo) Create(ctx context.Context, g *entities.ElevationGrant) error {
+	scope := g.VPNGroupScope
+	if scope == nil {
+		scope = []string{}
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO elevation_grants (id, user_id, username, permission, group_id, group_name, vpn_group_scope,
+                justification, duration_minutes, status, auto_approved, reviewed_at, expires_at, created_at, updated_at)
+         VALUES ($1,$2,$3,NULLIF($4,''),$5,NULLIF($6,''),$7,$8,$9,$10,$11,$12,$13,$14,$14)`,
+		g.ID, g.UserID, g.Username, g.Permission, nullUUID(g.GroupID), g.GroupName, pq.Array(scope),
+		g.Justification, g.DurationMinutes, g.Status, g.AutoApproved, g.ReviewedAt, g.ExpiresAt, g.CreatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("create elevation grant failed")
+	}
+	return err
+}
+
+func (r *pgElevationRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.ElevationGrant, error) {
+	g, err := scanElevation(r.db.QueryRowContext(ctx, `SELECT `+elevationColumns+` FROM elevation_grants WHERE id=$1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Log.WithError(err).Error("get elevation grant failed")
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *pgElevationRepo) List(ctx context.Context, f *entities.ElevationFilter) ([]*entities.ElevationGrant, int, error) {
+	if f == nil {
+		f = &entities.ElevationFilter{}
+	}
+	f.SetDefaults()
+
+	clauses := []string{}
+	args := []interface{}{}
+	idx := 1
+	if f.UserID != uuid.Nil {
+		clauses = append(clauses, "user_id=$"+strconv.Itoa(idx))
+		args = append(args, f.UserID)
+		idx++
+	}
+	if f.Status != "" {
+		clauses = append(clauses, "status=$"+strconv.Itoa(idx))
+		args = append(args, f.Status)
+		idx++
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query := `SELECT ` + elevationColumns + ` FROM elevation_grants` + where + fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d OFFSET %d", f.Limit, f.Offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	out, err := scanElevations(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM elevation_grants`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+func (r *pgElevationRepo) ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entities.ElevationGrant, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+elevationColumns+` FROM elevation_grants WHERE user_id=$1 AND status=$2 AND expires_at > $3`,
+		userID, entities.ElevationActive, now)
+	if err != nil {
+		return nil, err
+	}
+	return scanElevations(rows)
+}
+
+func (r *pgElevationRepo) Transition(ctx context.Context, g *entities.ElevationGrant, from string) (bool, error) {
+	g.UpdatedAt = time.Now()
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE elevation_grants SET status=$3, auto_approved=$4, approved_by=$5, approved_by_name=NULLIF($6,''),
+                review_comment=NULLIF($7,''), reviewed_at=$8, expires_at=$9, ended_at=$10, updated_at=$11
+         WHERE id=$1 AND status=$2`,
+		g.ID, from, g.Status, g.AutoApproved, nullUUID(g.ApprovedBy), g.ApprovedByName,
+		g.ReviewComment, g.ReviewedAt, g.ExpiresAt, g.EndedAt, g.UpdatedAt,
+	)
+	if err != nil {
+		logger.Log.WithError(err).Error("update elevation grant failed")
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *pgElevationRepo) ExpireDue(ctx context.Context, now, pendingBefore time.Time) ([]*entities.ElevationGrant, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`UPDATE elevation_grants SET status=$1, ended_at=$4, updated_at=$4
+         WHERE (status=$2 AND expires_at <= $4) OR (status=$3 AND created_at < $5)
+         RETURNING `+elevationColumns,
+		entities.ElevationExpired, entities.ElevationActive, entities.ElevationPending, now, pendingBefore)
+	if err != nil {
+		return nil, err
+	}
+	return scanElevations(rows)
+}
+
+func scanElevations(rows *sql.Rows) ([]*entities.ElevationGrant, error) {
+	defer rows.Close()
+	var out []*entities.ElevationGrant
+	for rows.Next() {
+		g, err := scanElevation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+func scanElevation(row rowScanner) (*entities.ElevationGrant, error) {
+	var g entities.ElevationGrant
+	var groupID, approvedBy uuid.NullUUID
+	var reviewedAt, expiresAt, endedAt sql.NullTime
+	err := row.Scan(&g.ID, &g.UserID, &g.Username, &g.Permission, &groupID, &g.GroupName,
+		pq.Array(&g.VPNGroupScope), &g.Justification, &g.DurationMinutes, &g.Status, &g.AutoApproved,
+		&approvedBy, &g.ApprovedByName, &g.ReviewComment, &reviewedAt,
+		&expiresAt, &endedAt, &g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	g.GroupID = groupID.UUID
+	g.ApprovedBy = approvedBy.UUID
+	if reviewedAt.Valid {
+		g.ReviewedAt = &reviewedAt.Time
+	}
+	if expiresAt.Valid {
+		g.ExpiresAt = &expiresAt.Time
+	}
+	if endedAt.Valid {
+		g.EndedAt = &endedAt.Time
+	}
+	return &g, nil
+}
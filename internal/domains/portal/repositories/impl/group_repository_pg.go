@@ -20,18 +20,18 @@ func NewGroupRepositoryPG(db *sql.DB) repositories.GroupRepository {
 
 func (r *pgGroupRepo) Create(ctx context.Context, g *entities.PortalGroup) error {
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO groups (id, name, display_name, is_active, created_at, updated_at)
-         VALUES ($1,$2,$3,$4,$5,$6)`,
-		g.ID, g.Name, g.DisplayName, g.IsActive, g.CreatedAt, g.UpdatedAt,
+		`INSERT INTO groups (id, name, display_name, is_active, mfa_required, created_at, updated_at)
+         VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		g.ID, g.Name, g.DisplayName, g.IsActive, g.MFARequired, g.CreatedAt, g.UpdatedAt,
 	)
 	return err
 }
 
 func (r *pgGroupRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.PortalGroup, error) {
 	row := r.db.QueryRowContext(ctx,
-		`SELECT id, name, display_name, is_active, created_at, updated_at FROM groups WHERE id=$1`, id)
+		`SELECT id, name, display_name, is_active, COALESCE(mfa_required, false), created_at, updated_at FROM groups WHERE id=$1`, id)
 	var g entities.PortalGroup
-	err := row.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.CreatedAt, &g.UpdatedAt)
+	err := row.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.MFARequired, &g.CreatedAt, &g.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -43,9 +43,9 @@ func (r *pgGroupRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.Port
 
 func (r *pgGroupRepo) GetByName(ctx context.Context, name string) (*entities.PortalGroup, error) {
 	row := r.db.QueryRowContext(ctx,
-		`SELECT id, name, display_name, is_active, created_at, updated_at FROM groups WHERE name=$1`, name)
+		`SELECT id, name, display_name, is_active, COALESCE(mfa_required, false), created_at, updated_at FROM groups WHERE name=$1`, name)
 	var g entities.PortalGroup
-	err := row.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.CreatedAt, &g.UpdatedAt)
+	err := row.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.MFARequired, &g.CreatedAt, &g.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -61,7 +61,7 @@ func (r *pgGroupRepo) List(ctx context.Context, f *entities.GroupFilter) ([]*ent
 	}
 	f.SetDefaults()
 
-	base := `SELECT id, name, display_name, is_active, created_at, updated_at FROM groups`
+	base := `SELECT id, name, display_name, is_active, COALESCE(mfa_required, false), created_at, updated_at FROM groups`
 	countBase := `SELECT COUNT(1) FROM groups`
 	clauses := []string{}
 	args := []interface{}{}
@@ -84,7 +84,7 @@ func (r *pgGroupRepo) List(ctx context.Context, f *entities.GroupFilter) ([]*ent
 	var groups []*entities.PortalGroup
 	for rows.Next() {
 		var g entities.PortalGroup
-		if err := rows.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.MFARequired, &g.CreatedAt, &g.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
 		groups = append(groups, &g)
@@ -97,15 +97,49 @@ func (r *pgGroupRepo) List(ctx context.Context, f *entities.GroupFilter) ([]*ent
 	return groups, total, nil
 }
 
+func (r *pgGroupRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.PortalGroup, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.id, g.name, g.display_name, g.is_active, COALESCE(g.mfa_required, false), g.created_at, g.updated_at
+        FROM groups g JOIN user_groups ug ON ug.group_id = g.id
+        WHERE ug.user_id=$1 ORDER BY g.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var groups []*entities.PortalGroup
+	for rows.Next() {
+		var g entities.PortalGroup
+		if err := rows.Scan(&g.ID, &g.Name, &g.DisplayName, &g.IsActive, &g.MFARequired, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, &g)
+	}
+	return groups, rows.Err()
+}
+
 func (r *pgGroupRepo) Update(ctx context.Context, g *entities.PortalGroup) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE groups SET name=$2, display_name=$3, is_active=$4, updated_at=$5 WHERE id=$1`,
-		g.ID, g.Name, g.DisplayName, g.IsActive, g.UpdatedAt,
+		`UPDATE groups SET name=$2, display_name=$3, is_active=$4, mfa_required=$5, updated_at=$6 WHERE id=$1`,
+		g.ID, g.Name, g.DisplayName, g.IsActive, g.MFARequired, g.UpdatedAt,
 	)
 	return err
 }
 
+// Delete removes the group along with its memberships, invalidating the
+// access tokens of its members.
 func (r *pgGroupRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM groups WHERE id=$1`, id)
-	return err
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET groups_version = groups_version + 1 WHERE id IN (SELECT user_id FROM user_groups WHERE group_id=$1)`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE id=$1`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
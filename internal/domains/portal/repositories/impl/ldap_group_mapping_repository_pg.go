@@ -0,0 +1,68 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+type pgLDAPGroupMappingRepo struct{ db *sql.DB }
+
+func NewLDAPGroupMappingRepositoryPG(db *sql.DB) repositories.LDAPGroupMappingRepository {
+	return &pgLDAPGroupMappingRepo{db: db}
+}
+
+func (r *pgLDAPGroupMappingRepo) List(ctx context.Context) ([]*entities.LDAPGroupMapping, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, ldap_group_dn, group_id, priority, created_at, updated_at FROM ldap_group_mappings ORDER BY priority, ldap_group_dn`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var mappings []*entities.LDAPGroupMapping
+	for rows.Next() {
+		var m entities.LDAPGroupMapping
+		if err := rows.Scan(&m.ID, &m.LDAPGroupDN, &m.GroupID, &m.Priority, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, &m)
+	}
+	return mappings, rows.Err()
+}
+
+func (r *pgLDAPGroupMappingRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.LDAPGroupMapping, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT id, ldap_group_dn, group_id, priority, created_at, updated_at FROM ldap_group_mappings WHERE id=$1`, id)
+	var m entities.LDAPGroupMapping
+	if err := row.Scan(&m.ID, &m.LDAPGroupDN, &m.GroupID, &m.Priority, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *pgLDAPGroupMappingRepo) Create(ctx context.Context, m *entities.LDAPGroupMapping) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO ldap_group_mappings (id, ldap_group_dn, group_id, priority, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6)`,
+		m.ID, m.LDAPGroupDN, m.GroupID, m.Priority, m.CreatedAt, m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *pgLDAPGroupMappingRepo) Update(ctx context.Context, m *entities.LDAPGroupMapping) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE ldap_group_mappings SET ldap_group_dn=$2, group_id=$3, priority=$4, updated_at=$5 WHERE id=$1`,
+		m.ID, m.LDAPGroupDN, m.GroupID, m.Priority, m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *pgLDAPGroupMappingRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ldap_group_mappings WHERE id=$1`, id)
+	return err
+}
@@ -0,0 +1,217 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+// permissionChannel is the Postgres NOTIFY channel that tells other replicas
+// to drop their cached grants.
+const permissionChannel = "portal_permissions"
+
+type cachedGrants struct {
+	// scopes maps "resource.action" to the grant's VPN group scope
+	scopes   map[string][]string
+	loadedAt time.Time
+}
+
+// CachedPermissionRepository keeps each group's grants in memory so the
+// permission middleware does not join three tables on every request. Writes
+// made through it clear the cache immediately and notify other replicas;
+// anything else becomes visible once the entry expires.
+type CachedPermissionRepository struct {
+	next repositories.PermissionRepository
+	db   *sql.DB
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*cachedGrants
+	// gen is bumped on every invalidation so a load that raced with one is
+	// not stored
+	gen uint64
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewCachedPermissionRepository wraps next with an in-process cache of group
+// grants. db is used to notify other replicas of changes and may be nil. A
+// non-positive ttl disables caching.
+func NewCachedPermissionRepository(next repositories.PermissionRepository, db *sql.DB, ttl time.Duration) *CachedPermissionRepository {
+	return &CachedPermissionRepository{next: next, db: db, ttl: ttl, groups: make(map[string]*cachedGrants)}
+}
+
+func (r *CachedPermissionRepository) List(ctx context.Context) ([]*entities.Permission, error) {
+	return r.next.List(ctx)
+}
+
+func (r *CachedPermissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Permission, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *CachedPermissionRepository) Create(ctx context.Context, p *entities.Permission) error {
+	if err := r.next.Create(ctx, p); err != nil {
+		return err
+	}
+	r.InvalidatePermissions(ctx)
+	return nil
+}
+
+func (r *CachedPermissionRepository) Update(ctx context.Context, p *entities.Permission) error {
+	if err := r.next.Update(ctx, p); err != nil {
+		return err
+	}
+	r.InvalidatePermissions(ctx)
+	return nil
+}
+
+func (r *CachedPermissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.InvalidatePermissions(ctx)
+	return nil
+}
+
+func (r *CachedPermissionRepository) GetByResourceAction(ctx context.Context, resource, action string) (*entities.Permission, error) {
+	return r.next.GetByResourceAction(ctx, resource, action)
+}
+
+func (r *CachedPermissionRepository) GetByGroup(ctx context.Context, groupID uuid.UUID) ([]*entities.Permission, error) {
+	return r.next.GetByGroup(ctx, groupID)
+}
+
+func (r *CachedPermissionRepository) GetByGroupName(ctx context.Context, groupName string) ([]*entities.Permission, error) {
+	return r.next.GetByGroupName(ctx, groupName)
+}
+
+// SetForGroup clears the whole cache since entries are keyed by group name.
+func (r *CachedPermissionRepository) SetForGroup(ctx context.Context, groupID uuid.UUID, permIDs []uuid.UUID, scopes map[uuid.UUID][]string) error {
+	if err := r.next.SetForGroup(ctx, groupID, permIDs, scopes); err != nil {
+		return err
+	}
+	r.InvalidatePermissions(ctx)
+	return nil
+}
+
+func (r *CachedPermissionRepository) HasGroupPermission(ctx context.Context, groupName, resource, action string) (bool, error) {
+	granted, _, err := r.GroupPermissionScope(ctx, groupName, resource, action)
+	return granted, err
+}
+
+func (r *CachedPermissionRepository) GroupPermissionScope(ctx context.Context, groupName, resource, action string) (bool, []string, error) {
+	if r.ttl <= 0 {
+		r.misses.Add(1)
+		return r.next.GroupPermissionScope(ctx, groupName, resource, action)
+	}
+	grants, err := r.grants(ctx, groupName)
+	if err != nil {
+		return false, nil, err
+	}
+	scope, ok := grants.scopes[resource+"."+action]
+	return ok, scope, nil
+}
+
+// grants returns the cached grants of a group, loading them on a miss.
+func (r *CachedPermissionRepository) grants(ctx context.Context, groupName string) (*cachedGrants, error) {
+	now := time.Now()
+	r.mu.Lock()
+	if e, ok := r.groups[groupName]; ok && now.Sub(e.loadedAt) < r.ttl {
+		r.mu.Unlock()
+		r.hits.Add(1)
+		return e, nil
+	}
+	gen := r.gen
+	r.mu.Unlock()
+	r.misses.Add(1)
+
+	perms, err := r.next.GetByGroupName(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+	e := &cachedGrants{scopes: make(map[string][]string, len(perms)), loadedAt: now}
+	for _, p := range perms {
+		e.scopes[p.Resource+"."+p.Action] = p.VPNGroupScope
+	}
+
+	r.mu.Lock()
+	if r.gen == gen {
+		r.groups[groupName] = e
+	}
+	r.mu.Unlock()
+	return e, nil
+}
+
+// InvalidatePermissions drops every cached grant and tells other replicas
+// to do the same.
+func (r *CachedPermissionRepository) InvalidatePermissions(ctx context.Context) {
+	r.invalidate()
+	if r.db == nil {
+		return
+	}
+	if _, err := r.db.ExecContext(ctx, `SELECT pg_notify($1, '')`, permissionChannel); err != nil {
+		logger.Log.WithError(err).Warn("failed to notify permission change")
+	}
+}
+
+func (r *CachedPermissionRepository) invalidate() {
+	r.mu.Lock()
+	r.gen++
+	r.groups = make(map[string]*cachedGrants)
+	r.mu.Unlock()
+	r.invalidations.Add(1)
+}
+
+func (r *CachedPermissionRepository) PermissionCacheStats() entities.PermissionCacheStats {
+	r.mu.Lock()
+	groups := len(r.groups)
+	r.mu.Unlock()
+	s := entities.PermissionCacheStats{
+		Enabled:       r.ttl > 0,
+		Groups:        groups,
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.Hits) / float64(total)
+	}
+	return s
+}
+
+// Listen subscribes to change notifications from other replicas using a
+// dedicated connection to dsn. Notifications missed while reconnecting are
+// covered by clearing the cache once the connection is back.
+func (r *CachedPermissionRepository) Listen(dsn string) error {
+	l := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Log.WithError(err).Warn("permission change listener error")
+		}
+	})
+	if err := l.Listen(permissionChannel); err != nil {
+		l.Close()
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case <-l.Notify:
+				// A nil notification means the connection was re-established
+				r.invalidate()
+			case <-time.After(90 * time.Second):
+				go l.Ping()
+			}
+		}
+	}()
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"system-portal/internal/domains/portal/entities"
 	"system-portal/internal/domains/portal/repositories"
 )
@@ -78,7 +79,7 @@ func (r *pgPermissionRepo) GetByResourceAction(ctx context.Context, resource, ac
 }
 
 func (r *pgPermissionRepo) GetByGroup(ctx context.Context, groupID uuid.UUID) ([]*entities.Permission, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT p.id, p.resource, p.action, p.description
+	rows, err := r.db.QueryContext(ctx, `SELECT p.id, p.resource, p.action, p.description, gp.vpn_group_scope
         FROM permissions p
         JOIN group_permissions gp ON gp.permission_id = p.id
         WHERE gp.group_id=$1`, groupID)
@@ -89,7 +90,7 @@ func (r *pgPermissionRepo) GetByGroup(ctx context.Context, groupID uuid.UUID) ([
 	var perms []*entities.Permission
 	for rows.Next() {
 		var p entities.Permission
-		if err := rows.Scan(&p.ID, &p.Resource, &p.Action, &p.Description); err != nil {
+		if err := rows.Scan(&p.ID, &p.Resource, &p.Action, &p.Description, pq.Array(&p.VPNGroupScope)); err != nil {
 			return nil, err
 		}
 		perms = append(perms, &p)
@@ -97,17 +98,44 @@ func (r *pgPermissionRepo) GetByGroup(ctx context.Context, groupID uuid.UUID) ([
 	return perms, nil
 }
 
-func (r *pgPermissionRepo) SetForGroup(ctx context.Context, groupID uuid.UUID, permIDs []uuid.UUID) error {
+func (r *pgPermissionRepo) GetByGroupName(ctx context.Context, groupName string) ([]*entities.Permission, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT p.id, p.resource, p.action, p.description, gp.vpn_group_scope
+        FROM permissions p
+        JOIN group_permissions gp ON gp.permission_id = p.id
+        JOIN groups g ON g.id = gp.group_id
+        WHERE g.name=$1`, groupName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var perms []*entities.Permission
+	for rows.Next() {
+		var p entities.Permission
+		if err := rows.Scan(&p.ID, &p.Resource, &p.Action, &p.Description, pq.Array(&p.VPNGroupScope)); err != nil {
+			return nil, err
+		}
+		perms = append(perms, &p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *pgPermissionRepo) SetForGroup(ctx context.Context, groupID uuid.UUID, permIDs []uuid.UUID, scopes map[uuid.UUID][]string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	if scopes == nil {
+		if scopes, err = groupScopes(ctx, tx, groupID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM group_permissions WHERE group_id=$1`, groupID); err != nil {
 		tx.Rollback()
 		return err
 	}
 	for _, pid := range permIDs {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO group_permissions (group_id, permission_id) VALUES ($1,$2)`, groupID, pid); err != nil {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO group_permissions (group_id, permission_id, vpn_group_scope) VALUES ($1,$2,$3)`, groupID, pid, pq.Array(scopes[pid])); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -127,3 +155,39 @@ func (r *pgPermissionRepo) HasGroupPermission(ctx context.Context, groupName, re
 	}
 	return count > 0, nil
 }
+
+func (r *pgPermissionRepo) GroupPermissionScope(ctx context.Context, groupName, resource, action string) (bool, []string, error) {
+	var scope []string
+	err := r.db.QueryRowContext(ctx, `SELECT gp.vpn_group_scope
+        FROM group_permissions gp
+        JOIN groups g ON g.id = gp.group_id
+        JOIN permissions p ON p.id = gp.permission_id
+        WHERE g.name=$1 AND p.resource=$2 AND p.action=$3`, groupName, resource, action).Scan(pq.Array(&scope))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, scope, nil
+}
+
+// groupScopes loads the current VPN group scopes of a group's grants.
+func groupScopes(ctx context.Context, tx *sql.Tx, groupID uuid.UUID) (map[uuid.UUID][]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT permission_id, vpn_group_scope FROM group_permissions
+        WHERE group_id=$1 AND vpn_group_scope IS NOT NULL`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	scopes := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var scope []string
+		if err := rows.Scan(&id, pq.Array(&scope)); err != nil {
+			return nil, err
+		}
+		scopes[id] = scope
+	}
+	return scopes, rows.Err()
+}
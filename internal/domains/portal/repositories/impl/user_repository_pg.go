@@ -13,6 +13,7 @@ import (
 	"system-portal/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type pgUserRepo struct{ db *sql.DB }
@@ -21,65 +22,74 @@ func NewUserRepositoryPG(db *sql.DB) repositories.UserRepository {
 	return &pgUserRepo{db: db}
 }
 
+// userColumns selects a user with the IDs of every group they belong to.
+const userColumns = `id, username, email, COALESCE(password_hash,''), full_name, group_id, is_active, COALESCE(auth_source,'local'), COALESCE(external_id,''), created_at, updated_at,
+        COALESCE(groups_version, 1), ARRAY(SELECT ug.group_id::text FROM user_groups ug WHERE ug.user_id = users.id ORDER BY ug.created_at)
+        FROM users`
+
+// Create inserts the user together with the membership of their primary
+// group.
 func (r *pgUserRepo) Create(ctx context.Context, u *entities.PortalUser) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO users (id, username, email, password_hash, full_name, group_id, is_active, created_at, updated_at)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
-		u.ID, u.Username, u.Email, u.Password, u.FullName, u.GroupID, u.IsActive, u.CreatedAt, u.UpdatedAt,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO users (id, username, email, password_hash, full_name, group_id, is_active, auth_source, external_id, created_at, updated_at)
+         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NULLIF($9,''),$10,$11)`,
+		u.ID, u.Username, u.Email, u.Password, u.FullName, u.GroupID, u.IsActive, authSource(u.AuthSource), u.ExternalID, u.CreatedAt, u.UpdatedAt,
 	)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `INSERT INTO user_groups (user_id, group_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, u.ID, u.GroupID)
+	}
 	if err != nil {
+		tx.Rollback()
 		logger.Log.WithError(err).Error("create user failed")
+		return err
 	}
-	return err
+	if err := tx.Commit(); err != nil {
+		logger.Log.WithError(err).Error("create user failed")
+		return err
+	}
+	u.GroupIDs = []uuid.UUID{u.GroupID}
+	u.GroupsVersion = 1
+	return nil
 }
 
 func (r *pgUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.PortalUser, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, username, email, full_name, group_id, is_active, created_at, updated_at
-        FROM users WHERE id=$1`, id)
-	var u entities.PortalUser
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.GroupID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	u, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` WHERE id=$1`, id))
 	if err != nil {
 		logger.Log.WithError(err).Error("get user by id failed")
-		return nil, err
 	}
-	return &u, nil
+	return u, err
 }
 
 func (r *pgUserRepo) GetByUsername(ctx context.Context, username string) (*entities.PortalUser, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, username, email, password_hash, full_name, group_id, is_active, created_at, updated_at
-        FROM users WHERE username=$1`, username)
-	var u entities.PortalUser
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.FullName, &u.GroupID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	u, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` WHERE username=$1`, username))
 	if err != nil {
 		logger.Log.WithError(err).Error("get user by username failed")
 		return nil, err
 	}
-	logger.Log.WithField("username", u.Username).Debug("user retrieved")
-	return &u, nil
+	if u != nil {
+		logger.Log.WithField("username", u.Username).Debug("user retrieved")
+	}
+	return u, nil
 }
 
 func (r *pgUserRepo) GetByEmail(ctx context.Context, email string) (*entities.PortalUser, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, username, email, password_hash, full_name, group_id, is_active, created_at, updated_at
-        FROM users WHERE email=$1`, email)
-	var u entities.PortalUser
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.FullName, &u.GroupID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	u, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` WHERE email=$1`, email))
 	if err != nil {
 		logger.Log.WithError(err).Error("get user by email failed")
-		return nil, err
 	}
-	return &u, nil
+	return u, err
+}
+
+func (r *pgUserRepo) GetByExternalID(ctx context.Context, authSource, externalID string) (*entities.PortalUser, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` WHERE auth_source=$1 AND external_id=$2`, authSource, externalID))
+	if err != nil {
+		logger.Log.WithError(err).Error("get user by external id failed")
+	}
+	return u, err
 }
 
 func (r *pgUserRepo) List(ctx context.Context, f *entities.UserFilter) ([]*entities.PortalUser, int, error) {
@@ -88,7 +98,8 @@ func (r *pgUserRepo) List(ctx context.Context, f *entities.UserFilter) ([]*entit
 	}
 	f.SetDefaults()
 
-	base := `SELECT id, username, email, full_name, group_id, is_active, created_at, updated_at FROM users`
+	base := `SELECT id, username, email, full_name, group_id, is_active, COALESCE(auth_source,'local'), created_at, updated_at,
+        ARRAY(SELECT ug.group_id::text FROM user_groups ug WHERE ug.user_id = users.id ORDER BY ug.created_at) FROM users`
 	countBase := `SELECT COUNT(1) FROM users`
 	clauses := []string{}
 	args := []interface{}{}
@@ -104,7 +115,7 @@ func (r *pgUserRepo) List(ctx context.Context, f *entities.UserFilter) ([]*entit
 		idx++
 	}
 	if f.GroupID != uuid.Nil {
-		clauses = append(clauses, "group_id=$"+strconv.Itoa(idx))
+		clauses = append(clauses, "EXISTS (SELECT 1 FROM user_groups ug WHERE ug.user_id = users.id AND ug.group_id=$"+strconv.Itoa(idx)+")")
 		args = append(args, f.GroupID)
 		idx++
 	}
@@ -121,7 +132,11 @@ func (r *pgUserRepo) List(ctx context.Context, f *entities.UserFilter) ([]*entit
 	var users []*entities.PortalUser
 	for rows.Next() {
 		var u entities.PortalUser
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.GroupID, &u.IsActive, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		var groupIDs []string
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.GroupID, &u.IsActive, &u.AuthSource, &u.CreatedAt, &u.UpdatedAt, pq.Array(&groupIDs)); err != nil {
+			return nil, 0, err
+		}
+		if u.GroupIDs, err = parseGroupIDs(groupIDs); err != nil {
 			return nil, 0, err
 		}
 		users = append(users, &u)
@@ -134,18 +149,83 @@ func (r *pgUserRepo) List(ctx context.Context, f *entities.UserFilter) ([]*entit
 	return users, total, nil
 }
 
+// Update saves the user. Moving them to another primary group replaces the
+// membership of the old primary group with the new one.
 func (r *pgUserRepo) Update(ctx context.Context, u *entities.PortalUser) error {
 	u.UpdatedAt = time.Now()
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE users SET password_hash=$2, full_name=$3, group_id=$4, is_active=$5, updated_at=$6 WHERE id=$1`,
-		u.ID, u.Password, u.FullName, u.GroupID, u.IsActive, u.UpdatedAt,
-	)
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var previous uuid.UUID
+		if err := tx.QueryRowContext(ctx, `SELECT group_id FROM users WHERE id=$1 FOR UPDATE`, u.ID).Scan(&previous); err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET password_hash=$2, full_name=$3, group_id=$4, is_active=$5, auth_source=$6, external_id=NULLIF($7,''), updated_at=$8 WHERE id=$1`,
+			u.ID, u.Password, u.FullName, u.GroupID, u.IsActive, authSource(u.AuthSource), u.ExternalID, u.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		if previous == u.GroupID {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_groups WHERE user_id=$1 AND group_id=$2`, u.ID, previous); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_groups (user_id, group_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, u.ID, u.GroupID); err != nil {
+			return err
+		}
+		ids := []uuid.UUID{u.GroupID}
+		for _, id := range u.GroupIDs {
+			if id != previous && id != u.GroupID {
+				ids = append(ids, id)
+			}
+		}
+		u.GroupIDs = ids
+		return tx.QueryRowContext(ctx, `UPDATE users SET groups_version = groups_version + 1 WHERE id=$1 RETURNING groups_version`, u.ID).Scan(&u.GroupsVersion)
+	})
 	if err != nil {
 		logger.Log.WithError(err).Error("update user failed")
 	}
 	return err
 }
 
+// SetGroups makes primary the user's primary group and groupIDs, plus
+// primary, their only memberships.
+func (r *pgUserRepo) SetGroups(ctx context.Context, userID, primary uuid.UUID, groupIDs []uuid.UUID) error {
+	ids := []uuid.UUID{primary}
+	for _, id := range groupIDs {
+		if id != primary {
+			ids = append(ids, id)
+		}
+	}
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE users SET group_id=$2, groups_version = groups_version + 1, updated_at=$3 WHERE id=$1`,
+			userID, primary, time.Now()); err != nil {
+			return err
+		}
+		keep := make([]string, len(ids))
+		for i, id := range ids {
+			keep[i] = id.String()
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_groups WHERE user_id=$1 AND NOT (group_id = ANY($2::uuid[]))`, userID, pq.Array(keep)); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO user_groups (user_id, group_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, userID, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Log.WithError(err).Error("set user groups failed")
+	}
+	return err
+}
+
 func (r *pgUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id=$1`, id)
 	if err != nil {
@@ -153,3 +233,80 @@ func (r *pgUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 	return err
 }
+
+func (r *pgUserRepo) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanUser(row *sql.Row) (*entities.PortalUser, error) {
+	var u entities.PortalUser
+	var groupIDs []string
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.FullName, &u.GroupID, &u.IsActive, &u.AuthSource, &u.ExternalID, &u.CreatedAt, &u.UpdatedAt,
+		&u.GroupsVersion, pq.Array(&groupIDs))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.GroupIDs, err = parseGroupIDs(groupIDs); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func parseGroupIDs(ids []string) ([]uuid.UUID, error) {
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+func authSource(s string) string {
+	if s == "" {
+		return entities.AuthSourceLocal
+	}
+	return s
+}
+
+func (r *pgUserRepo) Summary(ctx context.Context) (*entities.PortalUserSummary, error) {
+	s := &entities.PortalUserSummary{}
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE is_active IS NOT FALSE) FROM users`,
+	).Scan(&s.Total, &s.Active); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT g.name,
+                        COUNT(u.id) FILTER (WHERE u.is_active IS NOT FALSE),
+                        COUNT(u.id) FILTER (WHERE u.is_active = FALSE)
+                 FROM groups g
+                 LEFT JOIN user_groups ug ON ug.group_id = g.id
+                 LEFT JOIN users u ON u.id = ug.user_id
+                 GROUP BY g.name ORDER BY g.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var g entities.GroupUserCount
+		if err := rows.Scan(&g.Group, &g.Active, &g.Inactive); err != nil {
+			return nil, err
+		}
+		s.ByGroup = append(s.ByGroup, g)
+	}
+	return s, rows.Err()
+}
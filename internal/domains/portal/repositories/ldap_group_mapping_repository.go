@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+type LDAPGroupMappingRepository interface {
+	// List returns all mappings ordered by priority
+	List(ctx context.Context) ([]*entities.LDAPGroupMapping, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.LDAPGroupMapping, error)
+	Create(ctx context.Context, m *entities.LDAPGroupMapping) error
+	Update(ctx context.Context, m *entities.LDAPGroupMapping) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
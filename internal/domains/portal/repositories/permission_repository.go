@@ -15,6 +15,45 @@ type PermissionRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByResourceAction(ctx context.Context, resource, action string) (*entities.Permission, error)
 	GetByGroup(ctx context.Context, groupID uuid.UUID) ([]*entities.Permission, error)
-	SetForGroup(ctx context.Context, groupID uuid.UUID, permIDs []uuid.UUID) error
+	GetByGroupName(ctx context.Context, groupName string) ([]*entities.Permission, error)
+	// SetForGroup replaces the group's grants. scopes maps permission IDs to
+	// VPN group patterns; when scopes is nil the existing scopes of retained
+	// grants are kept.
+	SetForGroup(ctx context.Context, groupID uuid.UUID, permIDs []uuid.UUID, scopes map[uuid.UUID][]string) error
 	HasGroupPermission(ctx context.Context, groupName, resource, action string) (bool, error)
+	// GroupPermissionScope reports whether the group holds the permission and
+	// the VPN group patterns the grant is limited to.
+	GroupPermissionScope(ctx context.Context, groupName, resource, action string) (bool, []string, error)
+}
+
+// PermissionCache is implemented by permission repositories that keep group
+// grants in memory.
+type PermissionCache interface {
+	// InvalidatePermissions drops every cached grant, including on other
+	// replicas when they listen for changes.
+	InvalidatePermissions(ctx context.Context)
+	PermissionCacheStats() entities.PermissionCacheStats
+}
+
+// GroupsPermissionScope combines the grants of resource.action across
+// groups. The permission is held when any group holds it, and its VPN group
+// scope is the union of the grants' scopes, unrestricted when any grant is.
+func GroupsPermissionScope(ctx context.Context, perms PermissionRepository, groups []string, resource, action string) (bool, []string, error) {
+	allowed := false
+	var scope []string
+	for _, g := range groups {
+		ok, s, err := perms.GroupPermissionScope(ctx, g, resource, action)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if len(s) == 0 {
+			return true, nil, nil
+		}
+		allowed = true
+		scope = append(scope, s...)
+	}
+	return allowed, scope, nil
 }
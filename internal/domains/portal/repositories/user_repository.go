@@ -13,7 +13,14 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.PortalUser, error)
 	GetByUsername(ctx context.Context, username string) (*entities.PortalUser, error)
 	GetByEmail(ctx context.Context, email string) (*entities.PortalUser, error)
+	// GetByExternalID finds a user linked to an external identity
+	GetByExternalID(ctx context.Context, authSource, externalID string) (*entities.PortalUser, error)
        List(ctx context.Context, filter *entities.UserFilter) ([]*entities.PortalUser, int, error)
 	Update(ctx context.Context, user *entities.PortalUser) error
+	// SetGroups replaces the user's memberships; primary becomes their
+	// primary group and is always a member
+	SetGroups(ctx context.Context, userID, primary uuid.UUID, groupIDs []uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Summary counts users and the active and inactive members of each group
+	Summary(ctx context.Context) (*entities.PortalUserSummary, error)
 }
@@ -16,6 +16,9 @@ var (
 	auditHandler      *portalHandlers.AuditHandler
 	dashboardHandler  *portalHandlers.DashboardHandler
 	configHandler     *portalHandlers.ConfigHandler
+	approvalHandler   *portalHandlers.ApprovalHandler
+	elevationHandler  *portalHandlers.ElevationHandler
+	permMiddleware    *middleware.PermissionMiddleware
 )
 
 // Initialize sets up the handler dependencies
@@ -26,6 +29,9 @@ func Initialize(
 	ah *portalHandlers.AuditHandler,
 	dh *portalHandlers.DashboardHandler,
 	ch *portalHandlers.ConfigHandler,
+	aph *portalHandlers.ApprovalHandler,
+	eh *portalHandlers.ElevationHandler,
+	pmw *middleware.PermissionMiddleware,
 ) {
 	userHandler = uh
 	groupHandler = gh
@@ -33,15 +39,15 @@ func Initialize(
 	auditHandler = ah
 	dashboardHandler = dh
 	configHandler = ch
+	approvalHandler = aph
+	elevationHandler = eh
+	permMiddleware = pmw
 }
 
-// RegisterRoutes registers all portal routes
+// RegisterRoutes registers all portal routes with permission-based access control
 func RegisterRoutes(router *gin.RouterGroup) {
 	portal := router.Group("/api/portal")
 
-	// All portal routes require admin access
-	portal.Use(middleware.RequireGroup("admin"))
-
 	// Portal user management routes
 	registerUserRoutes(portal)
 	registerPermissionRoutes(portal)
@@ -52,6 +58,12 @@ func RegisterRoutes(router *gin.RouterGroup) {
 	// Connection config routes
 	registerConfigRoutes(portal)
 
+	// Four-eyes approval of held operations
+	registerApprovalRoutes(portal)
+
+	// Time-bound privilege elevation
+	registerElevationRoutes(portal)
+
 	// Audit log routes
 	registerAuditRoutes(portal)
 
@@ -62,57 +74,115 @@ func RegisterRoutes(router *gin.RouterGroup) {
 func registerUserRoutes(portal *gin.RouterGroup) {
 	users := portal.Group("/users")
 	{
-		users.GET("", userHandler.ListUsers)
-		users.POST("", userHandler.CreateUser)
-		users.GET("/:id", userHandler.GetUser)
-		users.PUT("/:id", userHandler.UpdateUser)
-		users.DELETE("/:id", userHandler.DeleteUser)
+		view := permMiddleware.RequirePermission("portal.view_users")
+		manage := permMiddleware.RequirePermission("portal.manage_users")
+
+		users.GET("", view, userHandler.ListUsers)
+		users.POST("", manage, userHandler.CreateUser)
+		users.GET("/:id", view, userHandler.GetUser)
+		users.PUT("/:id", manage, userHandler.UpdateUser)
+		users.DELETE("/:id", manage, userHandler.DeleteUser)
 
 		// User actions
-		users.PUT("/:id/activate", userHandler.ActivateUser)
-		users.PUT("/:id/deactivate", userHandler.DeactivateUser)
-		users.PUT("/:id/reset-password", userHandler.ResetPassword)
+		users.PUT("/:id/activate", manage, userHandler.ActivateUser)
+		users.PUT("/:id/deactivate", manage, userHandler.DeactivateUser)
+		users.PUT("/:id/reset-password", manage, userHandler.ResetPassword)
+		users.PUT("/:id/unlock", manage, userHandler.UnlockUser)
+		users.PUT("/:id/groups", manage, userHandler.UpdateUserGroups)
+
+		// Session management
+		users.GET("/:id/sessions", view, userHandler.ListUserSessions)
+		users.DELETE("/:id/sessions", manage, userHandler.RevokeUserSessions)
+		users.DELETE("/:id/sessions/:sessionId", manage, userHandler.RevokeUserSession)
 	}
 }
 
 func registerGroupRoutes(portal *gin.RouterGroup) {
 	groups := portal.Group("/groups")
 	{
-		groups.GET("", groupHandler.ListGroups)
-		groups.GET("/:id", groupHandler.GetGroup)
-		groups.POST("", groupHandler.CreateGroup)
-		groups.PUT("/:id", groupHandler.UpdateGroup)
-		groups.DELETE("/:id", groupHandler.DeleteGroup)
-		groups.GET("/:id/permissions", groupHandler.GetGroupPermissions)
-		groups.PUT("/:id/permissions", groupHandler.UpdateGroupPermissions)
+		view := permMiddleware.RequirePermission("portal.view_groups")
+		manage := permMiddleware.RequirePermission("portal.manage_groups")
+
+		groups.GET("", view, groupHandler.ListGroups)
+		groups.GET("/:id", view, groupHandler.GetGroup)
+		groups.POST("", manage, groupHandler.CreateGroup)
+		groups.PUT("/:id", manage, groupHandler.UpdateGroup)
+		groups.DELETE("/:id", manage, groupHandler.DeleteGroup)
+		groups.GET("/:id/permissions", view, groupHandler.GetGroupPermissions)
+		groups.PUT("/:id/permissions", manage, groupHandler.UpdateGroupPermissions)
 	}
 }
 
 func registerPermissionRoutes(portal *gin.RouterGroup) {
 	perms := portal.Group("/permissions")
 	{
-		perms.GET("", permissionHandler.ListPermissions)
-		perms.POST("", permissionHandler.CreatePermission)
-		perms.PUT("/:id", permissionHandler.UpdatePermission)
-		perms.DELETE("/:id", permissionHandler.DeletePermission)
+		// The catalog is needed to assign permissions to groups
+		perms.GET("", permMiddleware.RequirePermission("portal.view_groups"), permissionHandler.ListPermissions)
+
+		manage := permMiddleware.RequirePermission("portal.manage_permissions")
+		perms.POST("", manage, permissionHandler.CreatePermission)
+		perms.PUT("/:id", manage, permissionHandler.UpdatePermission)
+		perms.DELETE("/:id", manage, permissionHandler.DeletePermission)
+
+		// Monitoring of the permission cache used by every permission check
+		perms.GET("/cache", manage, permissionHandler.GetCacheStats)
+		perms.DELETE("/cache", manage, permissionHandler.FlushCache)
 	}
 }
 
 func registerAuditRoutes(portal *gin.RouterGroup) {
 	audit := portal.Group("/audit")
 	{
-		audit.GET("/logs", auditHandler.GetAuditLogs)
-		audit.GET("/logs/export", auditHandler.ExportAuditLogs)
-		audit.GET("/stats", auditHandler.GetAuditStats)
+		audit.GET("/logs", permMiddleware.RequirePermission("audit.view_logs"), auditHandler.GetAuditLogs)
+		audit.GET("/search", permMiddleware.RequirePermission("audit.view_logs"), auditHandler.SearchAuditLogs)
+		audit.GET("/logs/export", permMiddleware.RequirePermission("audit.export_logs"), auditHandler.ExportAuditLogs)
+		audit.GET("/stats", permMiddleware.RequirePermission("audit.view_logs"), auditHandler.GetAuditStats)
+		audit.GET("/verify", permMiddleware.RequirePermission("audit.view_logs"), auditHandler.VerifyAuditChain)
+		audit.GET("/checkpoints", permMiddleware.RequirePermission("audit.export_logs"), auditHandler.ListAuditCheckpoints)
+	}
+}
+
+func registerApprovalRoutes(portal *gin.RouterGroup) {
+	approvals := portal.Group("/approvals")
+	{
+		view := permMiddleware.RequirePermission("portal.view_approvals")
+		review := permMiddleware.RequirePermission("portal.approve_changes")
+
+		approvals.GET("", view, approvalHandler.ListChangeRequests)
+		approvals.GET("/:id", view, approvalHandler.GetChangeRequest)
+		approvals.POST("/:id/approve", review, approvalHandler.ApproveChangeRequest)
+		approvals.POST("/:id/reject", review, approvalHandler.RejectChangeRequest)
+
+		// Requesters may withdraw their own requests
+		approvals.POST("/:id/cancel", approvalHandler.CancelChangeRequest)
+	}
+}
+
+func registerElevationRoutes(portal *gin.RouterGroup) {
+	elevations := portal.Group("/elevations")
+	{
+		review := permMiddleware.RequirePermission("portal.approve_elevations")
+
+		// Any signed-in user may ask for elevation and follow their requests
+		elevations.POST("", elevationHandler.RequestElevation)
+		elevations.GET("/mine", elevationHandler.ListMyElevations)
+		elevations.POST("/:id/revoke", elevationHandler.RevokeElevation)
+
+		elevations.GET("", review, elevationHandler.ListElevations)
+		elevations.POST("/:id/approve", review, elevationHandler.ApproveElevation)
+		elevations.POST("/:id/reject", review, elevationHandler.RejectElevation)
 	}
 }
 
 func registerDashboardRoutes(portal *gin.RouterGroup) {
 	dashboard := portal.Group("/dashboard")
+	dashboard.Use(permMiddleware.RequirePermission("dashboard.view_stats"))
 	{
-		dashboard.GET("/stats", dashboardHandler.GetDashboardStats)
+		// OpenVPN usernames and sessions are only listed to those who may view users
+		vpnUsers := permMiddleware.ProbePermission("openvpn.view_users")
+		dashboard.GET("/stats", vpnUsers, dashboardHandler.GetDashboardStats)
 		dashboard.GET("/activities", dashboardHandler.GetRecentActivities)
-		dashboard.GET("/charts/users", dashboardHandler.GetUserChartData)
+		dashboard.GET("/charts/users", vpnUsers, dashboardHandler.GetUserChartData)
 		dashboard.GET("/charts/activities", dashboardHandler.GetActivityChartData)
 	}
 }
@@ -120,15 +190,22 @@ func registerDashboardRoutes(portal *gin.RouterGroup) {
 func registerConfigRoutes(portal *gin.RouterGroup) {
 	conn := portal.Group("/connections")
 	{
-		conn.GET("/openvpn", configHandler.GetOpenVPNConfig)
-		conn.POST("/openvpn", configHandler.CreateOpenVPNConfig)
-		conn.PUT("/openvpn", configHandler.UpdateOpenVPNConfig)
-		conn.DELETE("/openvpn", configHandler.DeleteOpenVPNConfig)
-		conn.POST("/openvpn/test", configHandler.TestOpenVPN)
-		conn.GET("/ldap", configHandler.GetLDAPConfig)
-		conn.POST("/ldap", configHandler.CreateLDAPConfig)
-		conn.PUT("/ldap", configHandler.UpdateLDAPConfig)
-		conn.DELETE("/ldap", configHandler.DeleteLDAPConfig)
-		conn.POST("/ldap/test", configHandler.TestLDAP)
+		view := permMiddleware.RequirePermission("config.view_connections")
+		manage := permMiddleware.RequirePermission("config.manage_connections")
+
+		conn.GET("/openvpn", view, configHandler.GetOpenVPNConfig)
+		conn.POST("/openvpn", manage, configHandler.CreateOpenVPNConfig)
+		conn.PUT("/openvpn", manage, configHandler.UpdateOpenVPNConfig)
+		conn.DELETE("/openvpn", manage, configHandler.DeleteOpenVPNConfig)
+		conn.POST("/openvpn/test", manage, configHandler.TestOpenVPN)
+		conn.GET("/ldap", view, configHandler.GetLDAPConfig)
+		conn.POST("/ldap", manage, configHandler.CreateLDAPConfig)
+		conn.PUT("/ldap", manage, configHandler.UpdateLDAPConfig)
+		conn.DELETE("/ldap", manage, configHandler.DeleteLDAPConfig)
+		conn.POST("/ldap/test", manage, configHandler.TestLDAP)
+		conn.GET("/ldap/group-mappings", view, configHandler.ListLDAPGroupMappings)
+		conn.POST("/ldap/group-mappings", manage, configHandler.CreateLDAPGroupMapping)
+		conn.PUT("/ldap/group-mappings/:id", manage, configHandler.UpdateLDAPGroupMapping)
+		conn.DELETE("/ldap/group-mappings/:id", manage, configHandler.DeleteLDAPGroupMapping)
 	}
 }
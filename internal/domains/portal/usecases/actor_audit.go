@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+// recordActorAudit adds an audit entry attributed to actor. Failures are
+// logged rather than failing the audited operation.
+func recordActorAudit(ctx context.Context, audit repositories.AuditRepository, actor ApprovalActor, action, resourceType, resource string, success bool) {
+	if audit == nil {
+		return
+	}
+	if len(resource) > 200 {
+		resource = resource[:200]
+	}
+	entry := &entities.AuditLog{
+		ID:           uuid.New(),
+		UserID:       actor.UserID,
+		Username:     actor.Username,
+		UserGroup:    actor.Group,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceName: resource,
+		IPAddress:    actor.IP,
+		Success:      success,
+		CreatedAt:    time.Now(),
+	}
+	if err := audit.Add(ctx, entry); err != nil {
+		logger.Log.WithError(err).WithField("action", action).Warn("failed to audit " + resourceType)
+	}
+}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ChangeExecutor applies the payload of an approved change request. The
+// returned result, if any, is stored with the request.
+type ChangeExecutor func(ctx context.Context, payload json.RawMessage) (interface{}, error)
+
+// ApprovalActor is the portal user submitting or reviewing a change request.
+// Scope is the VPN group scope of the actor's grant for the operation.
+type ApprovalActor struct {
+	UserID   uuid.UUID
+	Username string
+	Group    string
+	Groups   []string
+	Scope    []string
+	IP       string
+}
+
+// ApprovalUsecase holds operations guarded by selected permissions until a
+// second user approves them, then runs them through the executor registered
+// for the operation.
+type ApprovalUsecase interface {
+	// Required reports whether operations guarded by perm need approval.
+	Required(perm string) bool
+	// Register sets the executor of operation, replacing any previous one.
+	Register(operation string, exec ChangeExecutor)
+	Submit(ctx context.Context, requester ApprovalActor, perm, operation, target string, payload interface{}) (*entities.ChangeRequest, error)
+	List(ctx context.Context, filter *entities.ChangeRequestFilter) ([]*entities.ChangeRequest, int, error)
+	Get(ctx context.Context, id uuid.UUID) (*entities.ChangeRequest, error)
+	// Approve runs the operation. A request whose operation failed is
+	// returned with status failed rather than as an error.
+	Approve(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ChangeRequest, error)
+	Reject(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ChangeRequest, error)
+	// Cancel withdraws a pending request; only its requester may do so.
+	Cancel(ctx context.Context, id uuid.UUID, requester ApprovalActor) (*entities.ChangeRequest, error)
+}
@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	vpnentities "system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+var (
+	ErrChangeRequestNotFound = errors.New("change request not found")
+	ErrChangeRequestClosed   = errors.New("change request is no longer pending")
+	ErrChangeRequestExpired  = errors.New("change request has expired")
+	ErrSelfReview            = errors.New("change requests must be reviewed by another user")
+	ErrReviewerNotAuthorized = errors.New("reviewer is not authorized for this operation")
+	ErrNotRequester          = errors.New("only the requester can cancel a change request")
+	ErrOperationUnavailable  = errors.New("operation is not available")
+)
+
+// ApprovalPolicy selects the permissions whose operations need approval and
+// how long a request stays open.
+type ApprovalPolicy struct {
+	Permissions []string
+	TTL         time.Duration
+}
+
+type approvalUsecaseImpl struct {
+	repo   repositories.ChangeRequestRepository
+	perms  repositories.PermissionRepository
+	audit  repositories.AuditRepository
+	policy ApprovalPolicy
+	guard  map[string]bool
+
+	mu        sync.RWMutex
+	executors map[string]ChangeExecutor
+}
+
+func NewApprovalUsecase(repo repositories.ChangeRequestRepository, perms repositories.PermissionRepository, audit repositories.AuditRepository, policy ApprovalPolicy) ApprovalUsecase {
+	if policy.TTL <= 0 {
+		policy.TTL = 72 * time.Hour
+	}
+	guard := make(map[string]bool, len(policy.Permissions))
+	for _, p := range policy.Permissions {
+		guard[strings.TrimSpace(p)] = true
+	}
+	return &approvalUsecaseImpl{repo: repo, perms: perms, audit: audit, policy: policy, guard: guard, executors: make(map[string]ChangeExecutor)}
+}
+
+func (u *approvalUsecaseImpl) Required(perm string) bool {
+	return u.guard[perm]
+}
+
+func (u *approvalUsecaseImpl) Register(operation string, exec ChangeExecutor) {
+	u.mu.Lock()
+	u.executors[operation] = exec
+	u.mu.Unlock()
+}
+
+func (u *approvalUsecaseImpl) executor(operation string) ChangeExecutor {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.executors[operation]
+}
+
+func (u *approvalUsecaseImpl) Submit(ctx context.Context, requester ApprovalActor, perm, operation, target string, payload interface{}) (*entities.ChangeRequest, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode change request payload: %w", err)
+	}
+	now := time.Now()
+	cr := &entities.ChangeRequest{
+		ID:              uuid.New(),
+		Operation:       operation,
+		Permission:      perm,
+		Target:          target,
+		Payload:         raw,
+		Status:          entities.ChangeRequestPending,
+		RequestedBy:     requester.UserID,
+		RequestedByName: requester.Username,
+		RequesterGroup:  requester.Group,
+		RequesterScope:  requester.Scope,
+		ExpiresAt:       now.Add(u.policy.TTL),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := u.repo.Create(ctx, cr); err != nil {
+		return nil, err
+	}
+	u.record(ctx, requester, "approval.request", cr.Operation+" "+cr.Target, true)
+	return cr, nil
+}
+
+func (u *approvalUsecaseImpl) List(ctx context.Context, filter *entities.ChangeRequestFilter) ([]*entities.ChangeRequest, int, error) {
+	u.expire(ctx)
+	return u.repo.List(ctx, filter)
+}
+
+func (u *approvalUsecaseImpl) Get(ctx context.Context, id uuid.UUID) (*entities.ChangeRequest, error) {
+	u.expire(ctx)
+	cr, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cr == nil {
+		return nil, ErrChangeRequestNotFound
+	}
+	return cr, nil
+}
+
+func (u *approvalUsecaseImpl) expire(ctx context.Context) {
+	if _, err := u.repo.ExpirePending(ctx, time.Now()); err != nil {
+		logger.Log.WithError(err).Warn("failed to expire change requests")
+	}
+}
+
+func (u *approvalUsecaseImpl) Approve(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ChangeRequest, error) {
+	cr, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkReviewer(ctx, cr, reviewer); err != nil {
+		return nil, err
+	}
+	exec := u.executor(cr.Operation)
+	if exec == nil {
+		return nil, ErrOperationUnavailable
+	}
+
+	now := time.Now()
+	cr.Status = entities.ChangeRequestApproved
+	cr.ReviewedBy = reviewer.UserID
+	cr.ReviewedByName = reviewer.Username
+	cr.ReviewComment = comment
+	cr.ReviewedAt = &now
+	ok, err := u.repo.Transition(ctx, cr, entities.ChangeRequestPending)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrChangeRequestClosed
+	}
+	u.record(ctx, reviewer, "approval.approve", cr.Operation+" "+cr.Target, true)
+
+	// The operation runs with the requester's scope, which the reviewer was
+	// checked to cover, not with the reviewer's possibly wider one.
+	result, execErr := exec(vpnentities.WithGroupScope(ctx, cr.RequesterScope), cr.Payload)
+	done := time.Now()
+	cr.ExecutedAt = &done
+	cr.Status = entities.ChangeRequestExecuted
+	if execErr != nil {
+		cr.Status = entities.ChangeRequestFailed
+		cr.Error = execErr.Error()
+	}
+	if result != nil {
+		if raw, err := json.Marshal(result); err == nil {
+			cr.Result = raw
+		}
+	}
+	if _, err := u.repo.Transition(ctx, cr, entities.ChangeRequestApproved); err != nil {
+		logger.Log.WithError(err).WithField("change_request", cr.ID).Error("failed to record change request outcome")
+	}
+
+	// The change itself is attributed to the requester, naming the approver.
+	requester := ApprovalActor{UserID: cr.RequestedBy, Username: cr.RequestedByName, Group: cr.RequesterGroup, IP: reviewer.IP}
+	u.record(ctx, requester, cr.Operation, cr.Target+" (approved by "+reviewer.Username+")", execErr == nil)
+	return cr, nil
+}
+
+func (u *approvalUsecaseImpl) Reject(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ChangeRequest, error) {
+	cr, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkReviewer(ctx, cr, reviewer); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	cr.Status = entities.ChangeRequestRejected
+	cr.ReviewedBy = reviewer.UserID
+	cr.ReviewedByName = reviewer.Username
+	cr.ReviewComment = comment
+	cr.ReviewedAt = &now
+	if err := u.close(ctx, cr); err != nil {
+		return nil, err
+	}
+	u.record(ctx, reviewer, "approval.reject", cr.Operation+" "+cr.Target, true)
+	return cr, nil
+}
+
+func (u *approvalUsecaseImpl) Cancel(ctx context.Context, id uuid.UUID, requester ApprovalActor) (*entities.ChangeRequest, error) {
+	cr, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cr.RequestedBy != requester.UserID {
+		return nil, ErrNotRequester
+	}
+	cr.Status = entities.ChangeRequestCancelled
+	if err := u.close(ctx, cr); err != nil {
+		return nil, err
+	}
+	u.record(ctx, requester, "approval.cancel", cr.Operation+" "+cr.Target, true)
+	return cr, nil
+}
+
+// pending loads a request that can still be reviewed, expiring it first if
+// its time is up.
+func (u *approvalUsecaseImpl) pending(ctx context.Context, id uuid.UUID) (*entities.ChangeRequest, error) {
+	cr, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cr == nil {
+		return nil, ErrChangeRequestNotFound
+	}
+	if cr.Status != entities.ChangeRequestPending {
+		return nil, ErrChangeRequestClosed
+	}
+	if time.Now().After(cr.ExpiresAt) {
+		cr.Status = entities.ChangeRequestExpired
+		_ = u.close(ctx, cr)
+		return nil, ErrChangeRequestExpired
+	}
+	return cr, nil
+}
+
+// close moves a pending request to its final status.
+func (u *approvalUsecaseImpl) close(ctx context.Context, cr *entities.ChangeRequest) error {
+	ok, err := u.repo.Transition(ctx, cr, entities.ChangeRequestPending)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChangeRequestClosed
+	}
+	return nil
+}
+
+// checkReviewer requires the reviewer to be someone other than the
+// requester who holds the operation's permission over at least the
+// requester's VPN group scope.
+func (u *approvalUsecaseImpl) checkReviewer(ctx context.Context, cr *entities.ChangeRequest, reviewer ApprovalActor) error {
+	if reviewer.UserID == uuid.Nil || reviewer.UserID == cr.RequestedBy {
+		return ErrSelfReview
+	}
+	parts := strings.SplitN(cr.Permission, ".", 2)
+	if len(parts) != 2 {
+		return ErrReviewerNotAuthorized
+	}
+	ok, scope, err := repositories.GroupsPermissionScope(ctx, u.perms, reviewer.Groups, parts[0], parts[1])
+	if err != nil {
+		return err
+	}
+	if !ok || !scopeCovers(scope, cr.RequesterScope) {
+		return ErrReviewerNotAuthorized
+	}
+	return nil
+}
+
+// scopeCovers reports whether a grant limited to outer allows every group
+// inner does. Patterns in inner are only covered by an identical pattern
+// or an unrestricted outer scope.
+func scopeCovers(outer, inner []string) bool {
+	if vpnentities.GroupScope(outer).Unrestricted() {
+		return true
+	}
+	if len(inner) == 0 {
+		return false
+	}
+	for _, p := range inner {
+		if strings.ContainsAny(p, `*?[\`) {
+			if !containsFold(outer, p) {
+				return false
+			}
+			continue
+		}
+		if !vpnentities.GroupScope(outer).AllowsGroup(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *approvalUsecaseImpl) record(ctx context.Context, actor ApprovalActor, action, resource string, success bool) {
+	recordActorAudit(ctx, u.audit, actor, action, "change_request", resource, success)
+}
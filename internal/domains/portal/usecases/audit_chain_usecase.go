@@ -0,0 +1,18 @@
+package usecases
+
+import (
+	"context"
+
+	"system-portal/internal/domains/portal/entities"
+)
+
+// AuditChainUsecase proves the audit log was not altered: it walks the hash
+// chain and issues signed checkpoints of its head.
+type AuditChainUsecase interface {
+	// Verify walks the whole chain and reports the first broken link
+	Verify(ctx context.Context) (*entities.AuditChainReport, error)
+	// Checkpoint signs the current chain head. It returns nil when the head
+	// has not moved since the last checkpoint.
+	Checkpoint(ctx context.Context) (*entities.AuditCheckpoint, error)
+	ListCheckpoints(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditCheckpoint, error)
+}
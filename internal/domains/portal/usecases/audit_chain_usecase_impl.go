@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+var ErrCheckpointsDisabled = errors.New("audit checkpoint signing key is not configured")
+
+// auditChainBatch is how many entries or checkpoints are read at a time.
+const auditChainBatch = 1000
+
+type auditChainUsecaseImpl struct {
+	audit       repositories.AuditRepository
+	checkpoints repositories.AuditCheckpointRepository
+	key         ed25519.PrivateKey
+	// trusted maps key IDs to the public keys checkpoints may be signed with
+	trusted map[string]ed25519.PublicKey
+}
+
+// NewAuditChainUsecase verifies and checkpoints the audit chain. Checkpoints
+// must be signed by key or one of the retired keys in trusted; the public key
+// stored with a checkpoint is never relied on, since whoever can rewrite the
+// log can also re-sign it. Without a key checkpoints cannot be issued, but
+// existing ones are still verified against trusted.
+func NewAuditChainUsecase(audit repositories.AuditRepository, checkpoints repositories.AuditCheckpointRepository, key ed25519.PrivateKey, trusted []ed25519.PublicKey) AuditChainUsecase {
+	u := &auditChainUsecaseImpl{audit: audit, checkpoints: checkpoints, key: key, trusted: map[string]ed25519.PublicKey{}}
+	if key != nil {
+		trusted = append(trusted, key.Public().(ed25519.PublicKey))
+	}
+	for _, pub := range trusted {
+		u.trusted[CheckpointKeyID(pub)] = pub
+	}
+	return u
+}
+
+// ParseCheckpointKey decodes a base64 Ed25519 seed or private key. An empty
+// value returns a nil key.
+func ParseCheckpointKey(s string) (ed25519.PrivateKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("audit checkpoint key: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+	return nil, fmt.Errorf("audit checkpoint key: want %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+}
+
+// ParseCheckpointPublicKeys decodes base64 Ed25519 public keys.
+func ParseCheckpointPublicKeys(keys []string) ([]ed25519.PublicKey, error) {
+	out := make([]ed25519.PublicKey, 0, len(keys))
+	for _, s := range keys {
+		raw, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted checkpoint key: %w", err)
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trusted checkpoint key: want %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+		}
+		out = append(out, ed25519.PublicKey(raw))
+	}
+	return out, nil
+}
+
+// CheckpointKeyID identifies a checkpoint public key.
+func CheckpointKeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (u *auditChainUsecaseImpl) Verify(ctx context.Context) (*entities.AuditChainReport, error) {
+	report := &entities.AuditChainReport{Valid: true}
+	expected, err := u.loadCheckpoints(ctx, report)
+	if err != nil || !report.Valid {
+		report.VerifiedAt = time.Now()
+		return report, err
+	}
+
+	var after int64
+	prev, started := "", false
+	for report.Valid {
+		batch, err := u.audit.ChainRange(ctx, after, auditChainBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range batch {
+			after = a.Seq
+			if a.Hash == "" {
+				if started {
+					breakChain(report, a, "entry is missing from the hash chain")
+					break
+				}
+				report.Unchained++
+				continue
+			}
+			started = true
+			// Archived entries keep only their link; their content is checked
+			// against the archive's digest and again once restored
+			switch {
+			case a.PrevHash != prev:
+				breakChain(report, a, "previous hash does not match the preceding entry")
+			case !a.Archived && a.ChainHash() != a.Hash:
+				breakChain(report, a, "entry content does not match its hash")
+			}
+			if !report.Valid {
+				break
+			}
+			if hash, ok := expected[a.Seq]; ok {
+				if hash != a.Hash {
+					breakChain(report, a, "entry differs from the signed checkpoint")
+					break
+				}
+				report.CheckpointsChecked++
+				delete(expected, a.Seq)
+			}
+			prev = a.Hash
+			if a.Archived {
+				report.Archived++
+			}
+			report.Checked++
+			report.HeadSeq, report.HeadHash = a.Seq, a.Hash
+		}
+		if len(batch) < auditChainBatch {
+			break
+		}
+	}
+	// Checkpointed entries that were never reached have been removed
+	if report.Valid && len(expected) > 0 {
+		var first int64
+		for seq := range expected {
+			if first == 0 || seq < first {
+				first = seq
+			}
+		}
+		report.Valid = false
+		report.BrokenSeq = first
+		report.Reason = "entry covered by a signed checkpoint is missing"
+	}
+	report.VerifiedAt = time.Now()
+	return report, nil
+}
+
+// loadCheckpoints checks every checkpoint signature against the trusted keys
+// and returns the hash each one expects at its seq.
+func (u *auditChainUsecaseImpl) loadCheckpoints(ctx context.Context, report *entities.AuditChainReport) (map[int64]string, error) {
+	expected := map[int64]string{}
+	var after int64
+	for {
+		batch, err := u.checkpoints.List(ctx, after, auditChainBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, cp := range batch {
+			after = cp.Seq
+			pub, ok := u.trusted[cp.KeyID]
+			if !ok {
+				report.Valid = false
+				report.BrokenSeq = cp.Seq
+				report.Reason = "checkpoint is signed by an untrusted key"
+				return nil, nil
+			}
+			if !checkpointSignatureValid(cp, pub) {
+				report.Valid = false
+				report.BrokenSeq = cp.Seq
+				report.Reason = "checkpoint signature is invalid"
+				return nil, nil
+			}
+			expected[cp.Seq] = cp.Hash
+		}
+		if len(batch) < auditChainBatch {
+			return expected, nil
+		}
+	}
+}
+
+func breakChain(r *entities.AuditChainReport, a *entities.AuditLog, reason string) {
+	r.Valid = false
+	r.BrokenSeq = a.Seq
+	r.BrokenID = a.ID
+	r.Reason = reason
+}
+
+func checkpointSignatureValid(cp *entities.AuditCheckpoint, pub ed25519.PublicKey) bool {
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, cp.Message(), sig)
+}
+
+func (u *auditChainUsecaseImpl) Checkpoint(ctx context.Context) (*entities.AuditCheckpoint, error) {
+	if u.key == nil {
+		return nil, ErrCheckpointsDisabled
+	}
+	seq, hash, err := u.audit.ChainHead(ctx)
+	if err != nil || seq == 0 {
+		return nil, err
+	}
+	last, err := u.checkpoints.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && last.Seq == seq {
+		return nil, nil
+	}
+	pub := u.key.Public().(ed25519.PublicKey)
+	cp := &entities.AuditCheckpoint{
+		ID:        uuid.New(),
+		Seq:       seq,
+		Hash:      hash,
+		KeyID:     CheckpointKeyID(pub),
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+		CreatedAt: time.Now().Truncate(time.Microsecond),
+	}
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(u.key, cp.Message()))
+	if err := u.checkpoints.Create(ctx, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func (u *auditChainUsecaseImpl) ListCheckpoints(ctx context.Context, afterSeq int64, limit int) ([]*entities.AuditCheckpoint, error) {
+	if limit <= 0 || limit > auditChainBatch {
+		limit = 100
+	}
+	return u.checkpoints.List(ctx, afterSeq, limit)
+}
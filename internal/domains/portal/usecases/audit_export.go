@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// Formats audit entries can be exported in.
+const (
+	AuditFormatCSV   = "csv"
+	AuditFormatJSONL = "jsonl"
+	AuditFormatCEF   = "cef"
+)
+
+var ErrExportFormat = errors.New("export format must be csv, jsonl or cef")
+
+// AuditEncoder writes audit entries one at a time; Flush passes anything
+// buffered on to the underlying writer.
+type AuditEncoder interface {
+	Encode(a *entities.AuditLog) error
+	Flush() error
+}
+
+// NewAuditEncoder returns an encoder for format writing to w.
+func NewAuditEncoder(format string, w io.Writer) (AuditEncoder, error) {
+	switch format {
+	case AuditFormatCSV:
+		return newCSVAuditEncoder(w)
+	case AuditFormatJSONL:
+		return &jsonlAuditEncoder{enc: json.NewEncoder(w)}, nil
+	case AuditFormatCEF:
+		return &cefAuditEncoder{w: w}, nil
+	}
+	return nil, ErrExportFormat
+}
+
+// auditRecord is the JSON form of an audit entry in exports and archives.
+// It carries every hashed field so the entry can be verified against the
+// chain again.
+type auditRecord struct {
+	ID           uuid.UUID         `json:"id"`
+	UserID       uuid.UUID         `json:"user_id"`
+	Username     string            `json:"username"`
+	UserGroup    string            `json:"user_group"`
+	Action       string            `json:"action"`
+	ResourceType string            `json:"resource_type"`
+	ResourceName string            `json:"resource_name"`
+	IPAddress    string            `json:"ip_address"`
+	Success      bool              `json:"success"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Method       string            `json:"http_method,omitempty"`
+	Route        string            `json:"route,omitempty"`
+	PathParams   map[string]string `json:"path_params,omitempty"`
+	RequestBody  json.RawMessage   `json:"request_body,omitempty"`
+	StatusCode   int               `json:"status_code,omitempty"`
+	DurationMs   int64             `json:"duration_ms,omitempty"`
+	UserAgent    string            `json:"user_agent,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Seq          int64             `json:"seq"`
+	PrevHash     string            `json:"prev_hash,omitempty"`
+	Hash         string            `json:"hash,omitempty"`
+}
+
+func newAuditRecord(a *entities.AuditLog) auditRecord {
+	return auditRecord{
+		ID: a.ID, UserID: a.UserID, Username: a.Username, UserGroup: a.UserGroup,
+		Action: a.Action, ResourceType: a.ResourceType, ResourceName: a.ResourceName,
+		IPAddress: a.IPAddress, Success: a.Success, CreatedAt: a.CreatedAt,
+		Method: a.Method, Route: a.Route, PathParams: a.PathParams, RequestBody: a.RequestBody,
+		StatusCode: a.StatusCode, DurationMs: a.DurationMs, UserAgent: a.UserAgent, RequestID: a.RequestID,
+		Seq: a.Seq, PrevHash: a.PrevHash, Hash: a.Hash,
+	}
+}
+
+func (r auditRecord) entry() *entities.AuditLog {
+	return &entities.AuditLog{
+		ID: r.ID, UserID: r.UserID, Username: r.Username, UserGroup: r.UserGroup,
+		Action: r.Action, ResourceType: r.ResourceType, ResourceName: r.ResourceName,
+		IPAddress: r.IPAddress, Success: r.Success, CreatedAt: r.CreatedAt,
+		Method: r.Method, Route: r.Route, PathParams: r.PathParams, RequestBody: r.RequestBody,
+		StatusCode: r.StatusCode, DurationMs: r.DurationMs, UserAgent: r.UserAgent, RequestID: r.RequestID,
+		Seq: r.Seq, PrevHash: r.PrevHash, Hash: r.Hash,
+	}
+}
+
+type jsonlAuditEncoder struct{ enc *json.Encoder }
+
+func (e *jsonlAuditEncoder) Encode(a *entities.AuditLog) error {
+	return e.enc.Encode(newAuditRecord(a))
+}
+func (e *jsonlAuditEncoder) Flush() error { return nil }
+
+type csvAuditEncoder struct{ w *csv.Writer }
+
+var auditCSVHeader = []string{
+	"id", "user_id", "username", "user_group", "action", "resource_type", "resource_name", "ip_address", "success", "created_at",
+	"http_method", "route", "status_code", "duration_ms", "user_agent", "request_id", "seq", "hash",
+}
+
+func newCSVAuditEncoder(w io.Writer) (*csvAuditEncoder, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(auditCSVHeader); err != nil {
+		return nil, err
+	}
+	return &csvAuditEncoder{w: cw}, nil
+}
+
+func (e *csvAuditEncoder) Encode(a *entities.AuditLog) error {
+	return e.w.Write([]string{
+		a.ID.String(),
+		a.UserID.String(),
+		a.Username,
+		a.UserGroup,
+		a.Action,
+		a.ResourceType,
+		a.ResourceName,
+		a.IPAddress,
+		strconv.FormatBool(a.Success),
+		a.CreatedAt.Format(time.RFC3339),
+		a.Method,
+		a.Route,
+		strconv.Itoa(a.StatusCode),
+		strconv.FormatInt(a.DurationMs, 10),
+		a.UserAgent,
+		a.RequestID,
+		strconv.FormatInt(a.Seq, 10),
+		a.Hash,
+	})
+}
+
+func (e *csvAuditEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type cefAuditEncoder struct{ w io.Writer }
+
+func (e *cefAuditEncoder) Encode(a *entities.AuditLog) error {
+	_, err := io.WriteString(e.w, FormatAuditCEF(a)+"\n")
+	return err
+}
+
+func (e *cefAuditEncoder) Flush() error { return nil }
+
+// CEF header fields identifying the portal as the event source.
+const (
+	cefVendor  = "SystemPortal"
+	cefProduct = "system-portal"
+	cefVersion = "2.0.0"
+)
+
+// FormatAuditCEF renders an entry as an ArcSight Common Event Format line.
+// Failed operations are reported with a higher severity.
+func FormatAuditCEF(a *entities.AuditLog) string {
+	severity, outcome := "3", "success"
+	if !a.Success {
+		severity, outcome = "7", "failure"
+	}
+	ext := []string{
+		"rt=" + strconv.FormatInt(a.CreatedAt.UnixMilli(), 10),
+		"externalId=" + a.ID.String(),
+		"outcome=" + outcome,
+	}
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtension(value))
+		}
+	}
+	add("suser", a.Username)
+	add("src", a.IPAddress)
+	add("act", a.Action)
+	add("requestMethod", a.Method)
+	add("request", a.Route)
+	add("requestClientApplication", a.UserAgent)
+	add("cs1Label", "userGroup")
+	add("cs1", a.UserGroup)
+	add("cs2Label", "resourceType")
+	add("cs2", a.ResourceType)
+	add("cs3Label", "resourceName")
+	add("cs3", a.ResourceName)
+	add("cs4Label", "requestId")
+	add("cs4", a.RequestID)
+	if a.StatusCode != 0 {
+		add("cn1Label", "statusCode")
+		add("cn1", strconv.Itoa(a.StatusCode))
+	}
+	if a.Seq != 0 {
+		add("cn2Label", "chainSeq")
+		add("cn2", strconv.FormatInt(a.Seq, 10))
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%s|%s",
+		cefHeader(cefVendor), cefHeader(cefProduct), cefHeader(cefVersion),
+		cefHeader(a.Action), cefHeader(a.Action+" "+a.ResourceType), severity,
+		strings.Join(ext, " "))
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func cefHeader(s string) string    { return cefHeaderEscaper.Replace(s) }
+func cefExtension(s string) string { return cefExtensionEscaper.Replace(s) }
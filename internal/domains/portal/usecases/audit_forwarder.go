@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+// AuditSink delivers audit entries to an external system such as syslog or
+// a webhook. Send receives the entry encoded in the sink's Format, jsonl or
+// cef, and must be safe to retry.
+type AuditSink interface {
+	Name() string
+	Format() string
+	Send(ctx context.Context, a *entities.AuditLog, body []byte) error
+}
+
+// AuditForwardPolicy bounds the work spent on each entry.
+type AuditForwardPolicy struct {
+	// QueueSize is the number of entries each sink may have waiting
+	QueueSize int
+	// MaxAttempts is how often delivery is tried before dead-lettering
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout limits a single delivery attempt
+	Timeout time.Duration
+}
+
+var (
+	errAuditQueueFull      = errors.New("sink queue full")
+	errAuditForwardStopped = errors.New("forwarder stopped before delivery")
+)
+
+// AuditForwarder fans stored audit entries out to sinks in the background.
+// Each sink has its own bounded queue and worker, so a slow or unreachable
+// sink never delays requests or the other sinks. Entries that overflow a
+// queue or exhaust their retries go to the dead-letter table.
+type AuditForwarder struct {
+	workers     []*auditSinkWorker
+	deadLetters repositories.AuditDeadLetterRepository
+	policy      AuditForwardPolicy
+	stop        chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+}
+
+type auditSinkWorker struct {
+	sink  AuditSink
+	queue chan *entities.AuditLog
+}
+
+// NewAuditForwarder starts a worker for every sink.
+func NewAuditForwarder(sinks []AuditSink, deadLetters repositories.AuditDeadLetterRepository, policy AuditForwardPolicy) (*AuditForwarder, error) {
+	if policy.QueueSize <= 0 {
+		policy.QueueSize = 1000
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 5
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = time.Second
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = 5 * time.Second
+	}
+	f := &AuditForwarder{deadLetters: deadLetters, policy: policy, stop: make(chan struct{})}
+	names := map[string]bool{}
+	for _, s := range sinks {
+		if s.Format() != AuditFormatJSONL && s.Format() != AuditFormatCEF {
+			return nil, fmt.Errorf("audit sink %s: format must be jsonl or cef", s.Name())
+		}
+		if names[s.Name()] {
+			return nil, fmt.Errorf("audit sink %s is configured twice", s.Name())
+		}
+		names[s.Name()] = true
+		f.workers = append(f.workers, &auditSinkWorker{sink: s, queue: make(chan *entities.AuditLog, policy.QueueSize)})
+	}
+	for _, w := range f.workers {
+		f.wg.Add(1)
+		go f.run(w)
+	}
+	return f, nil
+}
+
+// Repository returns repo with Add also forwarding every stored entry, so
+// entries written by any usecase reach the sinks.
+func (f *AuditForwarder) Repository(repo repositories.AuditRepository) repositories.AuditRepository {
+	return &forwardingAuditRepo{AuditRepository: repo, forwarder: f}
+}
+
+type forwardingAuditRepo struct {
+	repositories.AuditRepository
+	forwarder *AuditForwarder
+}
+
+func (r *forwardingAuditRepo) Add(ctx context.Context, a *entities.AuditLog) error {
+	if err := r.AuditRepository.Add(ctx, a); err != nil {
+		return err
+	}
+	r.forwarder.Publish(ctx, a)
+	return nil
+}
+
+// Publish queues a stored entry for every sink without waiting for
+// delivery. A full queue dead-letters the entry for that sink.
+func (f *AuditForwarder) Publish(ctx context.Context, a *entities.AuditLog) {
+	entry := *a
+	for _, w := range f.workers {
+		select {
+		case w.queue <- &entry:
+		default:
+			f.deadLetter(context.WithoutCancel(ctx), w.sink, &entry, 0, errAuditQueueFull)
+		}
+	}
+}
+
+// Close stops the workers; entries still queued are dead-lettered so a
+// later redelivery sends them.
+func (f *AuditForwarder) Close() {
+	f.stopOnce.Do(func() { close(f.stop) })
+	f.wg.Wait()
+}
+
+func (f *AuditForwarder) run(w *auditSinkWorker) {
+	defer f.wg.Done()
+	for {
+		select {
+		case a := <-w.queue:
+			f.deliver(w.sink, a)
+		case <-f.stop:
+			for {
+				select {
+				case a := <-w.queue:
+					f.deadLetter(context.Background(), w.sink, a, 0, errAuditForwardStopped)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver tries an entry with exponential backoff until it is accepted or
+// the attempts run out.
+func (f *AuditForwarder) deliver(sink AuditSink, a *entities.AuditLog) {
+	body, err := encodeAuditEntry(sink.Format(), a)
+	if err != nil {
+		f.deadLetter(context.Background(), sink, a, 0, err)
+		return
+	}
+	backoff := f.policy.InitialBackoff
+	attempts := 0
+	for {
+		attempts++
+		if err = f.send(sink, a, body); err == nil {
+			return
+		}
+		if attempts >= f.policy.MaxAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-f.stop:
+			f.deadLetter(context.Background(), sink, a, attempts, err)
+			return
+		}
+		backoff *= 2
+		if backoff > f.policy.MaxBackoff {
+			backoff = f.policy.MaxBackoff
+		}
+	}
+	logger.Log.WithError(err).WithField("sink", sink.Name()).Warn("audit entry not forwarded; dead-lettered")
+	f.deadLetter(context.Background(), sink, a, attempts, err)
+}
+
+func (f *AuditForwarder) send(sink AuditSink, a *entities.AuditLog, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), f.policy.Timeout)
+	defer cancel()
+	return sink.Send(ctx, a, body)
+}
+
+func (f *AuditForwarder) deadLetter(ctx context.Context, sink AuditSink, a *entities.AuditLog, attempts int, reason error) {
+	payload, err := json.Marshal(newAuditRecord(a))
+	if err == nil {
+		now := time.Now()
+		err = f.deadLetters.Add(ctx, &entities.AuditDeadLetter{
+			ID:            uuid.New(),
+			Sink:          sink.Name(),
+			AuditID:       a.ID,
+			Payload:       payload,
+			Error:         reason.Error(),
+			Attempts:      attempts,
+			CreatedAt:     now,
+			LastAttemptAt: now,
+		})
+	}
+	if err != nil {
+		logger.Log.WithError(err).WithField("sink", sink.Name()).WithField("audit_id", a.ID).Error("failed to dead-letter audit entry")
+	}
+}
+
+// Redeliver retries up to limit dead letters once each, least recently tried
+// first, and returns how many were delivered. Dead letters of sinks no
+// longer configured are kept but not selected.
+func (f *AuditForwarder) Redeliver(ctx context.Context, limit int) (int, error) {
+	sinks := map[string]AuditSink{}
+	names := make([]string, 0, len(f.workers))
+	for _, w := range f.workers {
+		sinks[w.sink.Name()] = w.sink
+		names = append(names, w.sink.Name())
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+	items, err := f.deadLetters.List(ctx, names, limit)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, d := range items {
+		sink, ok := sinks[d.Sink]
+		if !ok {
+			continue
+		}
+		var r auditRecord
+		if err := json.Unmarshal(d.Payload, &r); err != nil {
+			return delivered, err
+		}
+		a := r.entry()
+		body, err := encodeAuditEntry(sink.Format(), a)
+		if err == nil {
+			err = f.send(sink, a, body)
+		}
+		if err != nil {
+			if ferr := f.deadLetters.Failed(ctx, d.ID, err.Error()); ferr != nil {
+				return delivered, ferr
+			}
+			continue
+		}
+		if err := f.deadLetters.Delete(ctx, d.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// encodeAuditEntry renders an entry as a single JSON object or CEF line.
+func encodeAuditEntry(format string, a *entities.AuditLog) ([]byte, error) {
+	if format == AuditFormatCEF {
+		return []byte(FormatAuditCEF(a)), nil
+	}
+	return json.Marshal(newAuditRecord(a))
+}
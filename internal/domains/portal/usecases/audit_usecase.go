@@ -9,5 +9,9 @@ import (
 type AuditUsecase interface {
 	Add(ctx context.Context, log *entities.AuditLog) error
 	List(ctx context.Context, filter *entities.AuditFilter) ([]*entities.AuditLog, int, error)
+	Search(ctx context.Context, filter *entities.AuditFilter) ([]*entities.AuditLog, string, error)
 	Get(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error)
+	// Export passes every filtered entry to fn, oldest first
+	Export(ctx context.Context, filter *entities.AuditFilter, fn func(*entities.AuditLog) error) error
+	Stats(ctx context.Context, filter *entities.AuditFilter, q entities.AuditStatsQuery) (*entities.AuditStats, error)
 }
@@ -22,6 +22,18 @@ func (a *auditUsecaseImpl) List(ctx context.Context, f *entities.AuditFilter) ([
 	return a.repo.List(ctx, f)
 }
 
+func (a *auditUsecaseImpl) Search(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditLog, string, error) {
+	return a.repo.Search(ctx, f)
+}
+
 func (a *auditUsecaseImpl) Get(ctx context.Context, id uuid.UUID) (*entities.AuditLog, error) {
 	return a.repo.GetByID(ctx, id)
 }
+
+func (a *auditUsecaseImpl) Export(ctx context.Context, f *entities.AuditFilter, fn func(*entities.AuditLog) error) error {
+	return a.repo.Each(ctx, f, fn)
+}
+
+func (a *auditUsecaseImpl) Stats(ctx context.Context, f *entities.AuditFilter, q entities.AuditStatsQuery) (*entities.AuditStats, error) {
+	return a.repo.Stats(ctx, f, q)
+}
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 	"system-portal/internal/domains/portal/entities"
 )
 
@@ -14,4 +16,8 @@ type ConfigUsecase interface {
 	SetLDAP(ctx context.Context, cfg *entities.LDAPConfig) error
 	DeleteLDAP(ctx context.Context) error
 	TestLDAP(ctx context.Context, cfg *entities.LDAPConfig) error
+	ListLDAPGroupMappings(ctx context.Context) ([]*entities.LDAPGroupMapping, error)
+	CreateLDAPGroupMapping(ctx context.Context, m *entities.LDAPGroupMapping) error
+	UpdateLDAPGroupMapping(ctx context.Context, m *entities.LDAPGroupMapping) error
+	DeleteLDAPGroupMapping(ctx context.Context, id uuid.UUID) error
 }
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,12 +14,14 @@ import (
 )
 
 type configUsecaseImpl struct {
-	ovRepo   repositories.OpenVPNConfigRepository
-	ldapRepo repositories.LDAPConfigRepository
+	ovRepo      repositories.OpenVPNConfigRepository
+	ldapRepo    repositories.LDAPConfigRepository
+	mappingRepo repositories.LDAPGroupMappingRepository
+	groupRepo   repositories.GroupRepository
 }
 
-func NewConfigUsecase(ov repositories.OpenVPNConfigRepository, ldap repositories.LDAPConfigRepository) ConfigUsecase {
-	return &configUsecaseImpl{ovRepo: ov, ldapRepo: ldap}
+func NewConfigUsecase(ov repositories.OpenVPNConfigRepository, ldap repositories.LDAPConfigRepository, mappings repositories.LDAPGroupMappingRepository, groups repositories.GroupRepository) ConfigUsecase {
+	return &configUsecaseImpl{ovRepo: ov, ldapRepo: ldap, mappingRepo: mappings, groupRepo: groups}
 }
 
 func (u *configUsecaseImpl) GetOpenVPN(ctx context.Context) (*entities.OpenVPNConfig, error) {
@@ -108,3 +112,65 @@ func (u *configUsecaseImpl) TestLDAP(ctx context.Context, cfg *entities.LDAPConf
 	conn.Close()
 	return nil
 }
+
+func (u *configUsecaseImpl) ListLDAPGroupMappings(ctx context.Context) ([]*entities.LDAPGroupMapping, error) {
+	return u.mappingRepo.List(ctx)
+}
+
+func (u *configUsecaseImpl) CreateLDAPGroupMapping(ctx context.Context, m *entities.LDAPGroupMapping) error {
+	if err := u.validateMapping(ctx, m); err != nil {
+		return err
+	}
+	now := time.Now()
+	m.ID = uuid.New()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+	return u.mappingRepo.Create(ctx, m)
+}
+
+func (u *configUsecaseImpl) UpdateLDAPGroupMapping(ctx context.Context, m *entities.LDAPGroupMapping) error {
+	existing, err := u.mappingRepo.GetByID(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("mapping not found")
+	}
+	if err := u.validateMapping(ctx, m); err != nil {
+		return err
+	}
+	m.CreatedAt = existing.CreatedAt
+	m.UpdatedAt = time.Now()
+	return u.mappingRepo.Update(ctx, m)
+}
+
+func (u *configUsecaseImpl) DeleteLDAPGroupMapping(ctx context.Context, id uuid.UUID) error {
+	existing, err := u.mappingRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("mapping not found")
+	}
+	return u.mappingRepo.Delete(ctx, id)
+}
+
+func (u *configUsecaseImpl) validateMapping(ctx context.Context, m *entities.LDAPGroupMapping) error {
+	m.LDAPGroupDN = strings.TrimSpace(m.LDAPGroupDN)
+	if m.LDAPGroupDN == "" {
+		return fmt.Errorf("ldap group DN is required")
+	}
+	if g, _ := u.groupRepo.GetByID(ctx, m.GroupID); g == nil {
+		return fmt.Errorf("group not found")
+	}
+	mappings, err := u.mappingRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range mappings {
+		if other.ID != m.ID && strings.EqualFold(other.LDAPGroupDN, m.LDAPGroupDN) {
+			return fmt.Errorf("mapping for this ldap group already exists")
+		}
+	}
+	return nil
+}
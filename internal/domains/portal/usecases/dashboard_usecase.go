@@ -0,0 +1,30 @@
+package usecases
+
+import (
+	"context"
+
+	ovEntities "system-portal/internal/domains/openvpn/entities"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// VPNDirectory reads OpenVPN users and live sessions for the dashboard.
+// Users honours the group scope of ctx; sessions carry no group and are
+// filtered by the caller.
+type VPNDirectory interface {
+	Users(ctx context.Context) ([]*ovEntities.User, error)
+	ConnectedUsers(ctx context.Context) ([]*ovEntities.ConnectedUser, error)
+}
+
+// DashboardUsecase assembles dashboard figures from portal users, the audit
+// log and, when configured, OpenVPN. OpenVPN figures honour the group scope
+// of ctx; vpnDetail adds the users and sessions behind them.
+type DashboardUsecase interface {
+	Stats(ctx context.Context, vpnDetail bool) (*entities.DashboardStats, error)
+	// UserChart ranks audit activity over the last days
+	UserChart(ctx context.Context, days int, vpnDetail bool) (*entities.UserChart, error)
+	ActivityChart(ctx context.Context, days int) (*entities.ActivityChart, error)
+	RecentActivity(ctx context.Context, limit int) ([]*entities.AuditLog, error)
+	// SetVPN switches to the OpenVPN connection configured now; nil means
+	// OpenVPN is not configured
+	SetVPN(vpn VPNDirectory)
+}
@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ovEntities "system-portal/internal/domains/openvpn/entities"
+	ovRepos "system-portal/internal/domains/openvpn/repositories"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
+)
+
+type dashboardUsecaseImpl struct {
+	users repositories.UserRepository
+	audit repositories.AuditRepository
+
+	mu  sync.RWMutex
+	vpn VPNDirectory
+}
+
+func NewDashboardUsecase(users repositories.UserRepository, audit repositories.AuditRepository) DashboardUsecase {
+	return &dashboardUsecaseImpl{users: users, audit: audit}
+}
+
+func (u *dashboardUsecaseImpl) SetVPN(vpn VPNDirectory) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.vpn = vpn
+}
+
+func (u *dashboardUsecaseImpl) Stats(ctx context.Context, vpnDetail bool) (*entities.DashboardStats, error) {
+	users, err := u.users.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	audit, err := u.audit.Stats(ctx, &entities.AuditFilter{FromTime: &today}, entities.AuditStatsQuery{Bucket: entities.AuditBucketDay, Top: 1})
+	if err != nil {
+		return nil, err
+	}
+	return &entities.DashboardStats{
+		Users:       *users,
+		VPN:         u.vpnSummary(ctx, vpnDetail),
+		AuditToday:  audit.Total,
+		FailedToday: audit.Failed,
+	}, nil
+}
+
+func (u *dashboardUsecaseImpl) UserChart(ctx context.Context, days int, vpnDetail bool) (*entities.UserChart, error) {
+	users, err := u.users.Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := chartStart(days)
+	audit, err := u.audit.Stats(ctx, &entities.AuditFilter{FromTime: &from}, entities.AuditStatsQuery{Bucket: entities.AuditBucketDay})
+	if err != nil {
+		return nil, err
+	}
+	return &entities.UserChart{
+		From:         from,
+		Portal:       *users,
+		VPN:          u.vpnSummary(ctx, vpnDetail),
+		ActiveUsers:  audit.ByUser,
+		ActiveGroups: audit.ByGroup,
+	}, nil
+}
+
+func (u *dashboardUsecaseImpl) ActivityChart(ctx context.Context, days int) (*entities.ActivityChart, error) {
+	from := chartStart(days)
+	filter := &entities.AuditFilter{FromTime: &from}
+	q := entities.AuditStatsQuery{Bucket: entities.AuditBucketDay, Top: 8}
+	stats, err := u.audit.Stats(ctx, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	series, err := u.audit.ActionTimeline(ctx, filter, q)
+	if err != nil {
+		return nil, err
+	}
+	for i := range series {
+		series[i].Buckets = fillDays(from, series[i].Buckets)
+	}
+	return &entities.ActivityChart{
+		From:           from,
+		Total:          stats.Total,
+		Failed:         stats.Failed,
+		Timeline:       fillDays(from, stats.Timeline),
+		ByAction:       series,
+		ByResourceType: stats.ByResourceType,
+	}, nil
+}
+
+func (u *dashboardUsecaseImpl) RecentActivity(ctx context.Context, limit int) ([]*entities.AuditLog, error) {
+	logs, _, err := u.audit.Search(ctx, &entities.AuditFilter{Limit: limit})
+	return logs, err
+}
+
+// vpnSummary reads OpenVPN. It never fails: an unconfigured or unreachable
+// server is reported in the summary so the rest of the dashboard loads.
+func (u *dashboardUsecaseImpl) vpnSummary(ctx context.Context, detail bool) entities.VPNSummary {
+	u.mu.RLock()
+	vpn := u.vpn
+	u.mu.RUnlock()
+	if vpn == nil {
+		return entities.VPNSummary{Reason: "OpenVPN is not configured"}
+	}
+	users, err := vpn.Users(ctx)
+	if err != nil {
+		logger.Log.WithError(err).Warn("dashboard could not list OpenVPN users")
+		return entities.VPNSummary{Reason: "OpenVPN server is unreachable"}
+	}
+	s := summarizeVPNUsers(users, time.Now())
+	// Sessions are optional; users are still worth showing without them
+	sessions, err := vpn.ConnectedUsers(ctx)
+	if err != nil {
+		logger.Log.WithError(err).Warn("dashboard could not read OpenVPN sessions")
+		s.Reason = "connected users are unavailable"
+	}
+	// Sessions carry no group, so keep those of users within the scope
+	if !ovEntities.GroupScopeFrom(ctx).Unrestricted() {
+		inScope := make(map[string]bool, len(users))
+		for _, user := range users {
+			inScope[strings.ToLower(user.Username)] = true
+		}
+		kept := sessions[:0]
+		for _, c := range sessions {
+			if inScope[strings.ToLower(c.Username)] {
+				kept = append(kept, c)
+			}
+		}
+		sessions = kept
+	}
+	s.Connected = len(sessions)
+	if detail {
+		s.Detailed = true
+		s.Sessions = sessions
+	} else {
+		s.Expiring = nil
+	}
+	return s
+}
+
+func summarizeVPNUsers(users []*ovEntities.User, now time.Time) entities.VPNSummary {
+	s := entities.VPNSummary{Available: true, Users: len(users)}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	groups, methods, mfa := map[string]int{}, map[string]int{}, map[string]int{}
+	for _, user := range users {
+		groups[orNone(user.GroupName)]++
+		methods[orNone(strings.ToLower(user.AuthMethod))]++
+		if user.IsMFAEnabled() {
+			mfa["enabled"]++
+		} else {
+			mfa["disabled"]++
+		}
+		if user.IsAccessDenied() {
+			s.Disabled++
+		}
+		if user.UserExpiration == "" {
+			continue
+		}
+		expires, err := ovEntities.ParseUserExpiration(user.UserExpiration)
+		if err != nil {
+			continue
+		}
+		daysLeft := int(expires.Sub(today).Hours() / 24)
+		switch {
+		case daysLeft < 0:
+			s.Expired++
+		case daysLeft <= 30:
+			if daysLeft <= 7 {
+				s.Expiring7++
+			}
+			s.Expiring30++
+			s.Expiring = append(s.Expiring, entities.ExpiringVPNUser{
+				Username:  user.Username,
+				GroupName: user.GroupName,
+				ExpiresOn: expires,
+				DaysLeft:  daysLeft,
+			})
+		}
+	}
+	sort.Slice(s.Expiring, func(i, j int) bool {
+		if s.Expiring[i].DaysLeft != s.Expiring[j].DaysLeft {
+			return s.Expiring[i].DaysLeft < s.Expiring[j].DaysLeft
+		}
+		return s.Expiring[i].Username < s.Expiring[j].Username
+	})
+	s.ByGroup = sortedCounts(groups)
+	s.ByAuthMethod = sortedCounts(methods)
+	s.ByMFA = sortedCounts(mfa)
+	return s
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// sortedCounts orders counts from largest to smallest, then by key.
+func sortedCounts(m map[string]int) []entities.DashboardCount {
+	out := make([]entities.DashboardCount, 0, len(m))
+	for k, n := range m {
+		out = append(out, entities.DashboardCount{Key: k, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// chartStart returns the start of the first of the last days days in UTC.
+func chartStart(days int) time.Time {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, 1-days)
+}
+
+// fillDays returns one bucket per day from from through today, zero where
+// buckets has none.
+func fillDays(from time.Time, buckets []entities.AuditBucket) []entities.AuditBucket {
+	byDay := make(map[time.Time]entities.AuditBucket, len(buckets))
+	for _, b := range buckets {
+		byDay[b.Start] = b
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var out []entities.AuditBucket
+	for d := from; !d.After(today); d = d.AddDate(0, 0, 1) {
+		b, ok := byDay[d]
+		if !ok {
+			b = entities.AuditBucket{Start: d}
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// vpnDirectory reads the dashboard's OpenVPN data from the repositories of
+// the current connection.
+type vpnDirectory struct {
+	users  ovRepos.UserRepository
+	status ovRepos.VPNStatusRepository
+}
+
+func NewVPNDirectory(users ovRepos.UserRepository, status ovRepos.VPNStatusRepository) VPNDirectory {
+	return &vpnDirectory{users: users, status: status}
+}
+
+// Users lists the users within the group scope of ctx.
+func (d *vpnDirectory) Users(ctx context.Context) ([]*ovEntities.User, error) {
+	return d.users.List(ctx, &ovEntities.UserFilter{GroupScope: ovEntities.GroupScopeFrom(ctx)})
+}
+
+func (d *vpnDirectory) ConnectedUsers(ctx context.Context) ([]*ovEntities.ConnectedUser, error) {
+	return d.status.GetConnectedUsers(ctx)
+}
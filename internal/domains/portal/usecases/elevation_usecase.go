@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ElevationRequest asks for either a permission, optionally limited to VPN
+// groups, or the permissions of a portal group for Minutes.
+type ElevationRequest struct {
+	Permission    string
+	VPNGroupScope []string
+	GroupID       uuid.UUID
+	Minutes       int
+	Justification string
+}
+
+// ElevationRule approves matching requests without a reviewer. A rule
+// names a permission or a group; RequesterGroups limits it to members of
+// those groups and is open to everyone when empty. A permission rule only
+// approves requests whose VPN group scope VPNGroupScope covers; an empty
+// VPNGroupScope covers every group.
+type ElevationRule struct {
+	Permission      string
+	Group           string
+	VPNGroupScope   []string
+	RequesterGroups []string
+	MaxDuration     time.Duration
+}
+
+// ElevationPolicy bounds elevation requests. Pending requests not reviewed
+// within PendingTTL expire.
+type ElevationPolicy struct {
+	MaxDuration time.Duration
+	PendingTTL  time.Duration
+	AutoApprove []ElevationRule
+}
+
+// ElevationUsecase manages time-bound elevation grants from request to
+// expiry. Every transition is audited.
+type ElevationUsecase interface {
+	Request(ctx context.Context, requester ApprovalActor, req ElevationRequest) (*entities.ElevationGrant, error)
+	List(ctx context.Context, filter *entities.ElevationFilter) ([]*entities.ElevationGrant, int, error)
+	Approve(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ElevationGrant, error)
+	Reject(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ElevationGrant, error)
+	// Revoke ends an active grant or withdraws a pending request. Users may
+	// revoke their own grants; others need portal.approve_elevations.
+	Revoke(ctx context.Context, id uuid.UUID, actor ApprovalActor, comment string) (*entities.ElevationGrant, error)
+	// Sweep expires grants past their end and stale requests.
+	Sweep(ctx context.Context) (int, error)
+}
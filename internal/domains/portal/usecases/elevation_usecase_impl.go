@@ -0,0 +1,298 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+var (
+	ErrElevationNotFound      = errors.New("elevation grant not found")
+	ErrElevationClosed        = errors.New("elevation grant is no longer pending")
+	ErrElevationNotActive     = errors.New("elevation grant is not active")
+	ErrElevationTarget        = errors.New("request either a permission or a group")
+	ErrElevationDuration      = errors.New("elevation duration is out of range")
+	ErrElevationUnknownPerm   = errors.New("unknown permission")
+	ErrElevationUnknownGroup  = errors.New("unknown group")
+	ErrElevationScope         = errors.New("invalid vpn group scope")
+	ErrElevationSelfReview    = errors.New("elevation must be approved by another user")
+	ErrElevationNotAuthorized = errors.New("not authorized to grant this elevation")
+)
+
+type elevationUsecaseImpl struct {
+	repo   repositories.ElevationRepository
+	perms  repositories.PermissionRepository
+	groups repositories.GroupRepository
+	audit  repositories.AuditRepository
+	policy ElevationPolicy
+}
+
+func NewElevationUsecase(repo repositories.ElevationRepository, perms repositories.PermissionRepository, groups repositories.GroupRepository, audit repositories.AuditRepository, policy ElevationPolicy) ElevationUsecase {
+	if policy.MaxDuration <= 0 {
+		policy.MaxDuration = 4 * time.Hour
+	}
+	if policy.PendingTTL <= 0 {
+		policy.PendingTTL = 24 * time.Hour
+	}
+	return &elevationUsecaseImpl{repo: repo, perms: perms, groups: groups, audit: audit, policy: policy}
+}
+
+func (u *elevationUsecaseImpl) Request(ctx context.Context, requester ApprovalActor, req ElevationRequest) (*entities.ElevationGrant, error) {
+	if (req.Permission == "") == (req.GroupID == uuid.Nil) {
+		return nil, ErrElevationTarget
+	}
+	if req.Permission == "" && len(req.VPNGroupScope) > 0 {
+		return nil, fmt.Errorf("%w: a scope only applies to a permission", ErrElevationScope)
+	}
+	if req.Minutes <= 0 || time.Duration(req.Minutes)*time.Minute > u.policy.MaxDuration {
+		return nil, fmt.Errorf("%w: 1 to %d minutes", ErrElevationDuration, int(u.policy.MaxDuration/time.Minute))
+	}
+	now := time.Now()
+	g := &entities.ElevationGrant{
+		ID:              uuid.New(),
+		UserID:          requester.UserID,
+		Username:        requester.Username,
+		Justification:   req.Justification,
+		DurationMinutes: req.Minutes,
+		Status:          entities.ElevationPending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if req.Permission != "" {
+		parts := strings.SplitN(req.Permission, ".", 2)
+		if len(parts) != 2 {
+			return nil, ErrElevationUnknownPerm
+		}
+		p, err := u.perms.GetByResourceAction(ctx, parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, ErrElevationUnknownPerm
+		}
+		if len(req.VPNGroupScope) > 0 {
+			if err := validateVPNGroupScope(p.Resource, req.VPNGroupScope); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrElevationScope, err)
+			}
+		}
+		g.Permission = req.Permission
+		g.VPNGroupScope = req.VPNGroupScope
+	} else {
+		grp, err := u.groups.GetByID(ctx, req.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if grp == nil {
+			return nil, ErrElevationUnknownGroup
+		}
+		g.GroupID = grp.ID
+		g.GroupName = grp.Name
+	}
+
+	action := "elevation.request"
+	if u.autoApproved(g, requester) {
+		g.Status = entities.ElevationActive
+		g.AutoApproved = true
+		g.ReviewedAt = &now
+		expires := now.Add(g.Duration())
+		g.ExpiresAt = &expires
+		action = "elevation.auto_approve"
+	}
+	if err := u.repo.Create(ctx, g); err != nil {
+		return nil, err
+	}
+	u.record(ctx, requester, action, g)
+	return g, nil
+}
+
+// autoApproved reports whether a rule of the policy covers the request.
+func (u *elevationUsecaseImpl) autoApproved(g *entities.ElevationGrant, requester ApprovalActor) bool {
+	for _, r := range u.policy.AutoApprove {
+		if g.Permission != "" && (r.Permission != g.Permission || !scopeCovers(r.VPNGroupScope, g.VPNGroupScope)) {
+			continue
+		}
+		if g.GroupName != "" && !strings.EqualFold(r.Group, g.GroupName) {
+			continue
+		}
+		if r.MaxDuration > 0 && g.Duration() > r.MaxDuration {
+			continue
+		}
+		if len(r.RequesterGroups) == 0 || shareGroup(r.RequesterGroups, requester.Groups) {
+			return true
+		}
+	}
+	return false
+}
+
+func shareGroup(a, b []string) bool {
+	for _, x := range a {
+		if containsFold(b, x) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *elevationUsecaseImpl) List(ctx context.Context, filter *entities.ElevationFilter) ([]*entities.ElevationGrant, int, error) {
+	return u.repo.List(ctx, filter)
+}
+
+func (u *elevationUsecaseImpl) Approve(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ElevationGrant, error) {
+	g, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkReviewer(ctx, g, reviewer); err != nil {
+		return nil, err
+	}
+	// The grant lasts its full duration from approval
+	now := time.Now()
+	expires := now.Add(g.Duration())
+	g.Status = entities.ElevationActive
+	g.ApprovedBy = reviewer.UserID
+	g.ApprovedByName = reviewer.Username
+	g.ReviewComment = comment
+	g.ReviewedAt = &now
+	g.ExpiresAt = &expires
+	if err := u.transition(ctx, g, entities.ElevationPending, ErrElevationClosed); err != nil {
+		return nil, err
+	}
+	u.record(ctx, reviewer, "elevation.approve", g)
+	return g, nil
+}
+
+func (u *elevationUsecaseImpl) Reject(ctx context.Context, id uuid.UUID, reviewer ApprovalActor, comment string) (*entities.ElevationGrant, error) {
+	g, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkReviewer(ctx, g, reviewer); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	g.Status = entities.ElevationRejected
+	g.ApprovedBy = reviewer.UserID
+	g.ApprovedByName = reviewer.Username
+	g.ReviewComment = comment
+	g.ReviewedAt = &now
+	if err := u.transition(ctx, g, entities.ElevationPending, ErrElevationClosed); err != nil {
+		return nil, err
+	}
+	u.record(ctx, reviewer, "elevation.reject", g)
+	return g, nil
+}
+
+func (u *elevationUsecaseImpl) Revoke(ctx context.Context, id uuid.UUID, actor ApprovalActor, comment string) (*entities.ElevationGrant, error) {
+	g, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrElevationNotFound
+	}
+	if g.UserID != actor.UserID {
+		ok, _, err := repositories.GroupsPermissionScope(ctx, u.perms, actor.Groups, "portal", "approve_elevations")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrElevationNotAuthorized
+		}
+	}
+	from := g.Status
+	action := "elevation.revoke"
+	switch from {
+	case entities.ElevationPending:
+		g.Status = entities.ElevationCancelled
+		action = "elevation.cancel"
+	case entities.ElevationActive:
+		now := time.Now()
+		g.Status = entities.ElevationRevoked
+		g.EndedAt = &now
+	default:
+		return nil, ErrElevationNotActive
+	}
+	if comment != "" {
+		g.ReviewComment = comment
+	}
+	if err := u.transition(ctx, g, from, ErrElevationNotActive); err != nil {
+		return nil, err
+	}
+	u.record(ctx, actor, action, g)
+	return g, nil
+}
+
+func (u *elevationUsecaseImpl) Sweep(ctx context.Context) (int, error) {
+	now := time.Now()
+	ended, err := u.repo.ExpireDue(ctx, now, now.Add(-u.policy.PendingTTL))
+	if err != nil {
+		return 0, err
+	}
+	for _, g := range ended {
+		// Expiry is attributed to the elevated user, with no source address
+		u.record(ctx, ApprovalActor{UserID: g.UserID, Username: g.Username}, "elevation.expire", g)
+	}
+	return len(ended), nil
+}
+
+func (u *elevationUsecaseImpl) pending(ctx context.Context, id uuid.UUID) (*entities.ElevationGrant, error) {
+	g, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrElevationNotFound
+	}
+	if g.Status != entities.ElevationPending {
+		return nil, ErrElevationClosed
+	}
+	return g, nil
+}
+
+func (u *elevationUsecaseImpl) transition(ctx context.Context, g *entities.ElevationGrant, from string, stale error) error {
+	ok, err := u.repo.Transition(ctx, g, from)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return stale
+	}
+	return nil
+}
+
+// checkReviewer requires another user who could grant the access
+// permanently: the holder of the permission over at least the requested
+// VPN groups, or for a group a user who manages portal users.
+func (u *elevationUsecaseImpl) checkReviewer(ctx context.Context, g *entities.ElevationGrant, reviewer ApprovalActor) error {
+	if reviewer.UserID == uuid.Nil || reviewer.UserID == g.UserID {
+		return ErrElevationSelfReview
+	}
+	resource, action := "portal", "manage_users"
+	if g.Permission != "" {
+		parts := strings.SplitN(g.Permission, ".", 2)
+		resource, action = parts[0], parts[1]
+	}
+	ok, scope, err := repositories.GroupsPermissionScope(ctx, u.perms, reviewer.Groups, resource, action)
+	if err != nil {
+		return err
+	}
+	if !ok || (g.Permission != "" && !scopeCovers(scope, g.VPNGroupScope)) {
+		return ErrElevationNotAuthorized
+	}
+	return nil
+}
+
+func (u *elevationUsecaseImpl) record(ctx context.Context, actor ApprovalActor, action string, g *entities.ElevationGrant) {
+	target := g.Permission
+	if target == "" {
+		target = "group " + g.GroupName
+	}
+	resource := fmt.Sprintf("%s: %s for %dm", g.Username, target, g.DurationMinutes)
+	recordActorAudit(ctx, u.audit, actor, action, "elevation", resource, true)
+}
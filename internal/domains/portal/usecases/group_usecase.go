@@ -13,7 +13,9 @@ type GroupUsecase interface {
 	GetByName(ctx context.Context, name string) (*entities.PortalGroup, error)
 	Update(ctx context.Context, g *entities.PortalGroup) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	UpdatePermissions(ctx context.Context, id uuid.UUID, permIDs []uuid.UUID) error
+	// UpdatePermissions replaces the group's grants. scopes optionally limits
+	// openvpn grants to VPN group name patterns, nil keeps existing scopes.
+	UpdatePermissions(ctx context.Context, id uuid.UUID, permIDs []uuid.UUID, scopes map[uuid.UUID][]string) error
 	GetPermissions(ctx context.Context, id uuid.UUID) ([]*entities.Permission, error)
 	ListPermissions(ctx context.Context) ([]*entities.Permission, error)
 }
@@ -3,6 +3,8 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -71,7 +73,14 @@ func (g *groupUsecaseImpl) Update(ctx context.Context, gr *entities.PortalGroup)
 		return fmt.Errorf("group already exists")
 	}
 	gr.UpdatedAt = time.Now()
-	return g.repo.Update(ctx, gr)
+	if err := g.repo.Update(ctx, gr); err != nil {
+		return err
+	}
+	// Cached grants are keyed by group name
+	if existing.Name != gr.Name {
+		g.invalidatePermissions(ctx)
+	}
+	return nil
 }
 
 func (g *groupUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
@@ -82,10 +91,14 @@ func (g *groupUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	if existing == nil {
 		return fmt.Errorf("group not found")
 	}
-	return g.repo.Delete(ctx, id)
+	if err := g.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	g.invalidatePermissions(ctx)
+	return nil
 }
 
-func (g *groupUsecaseImpl) UpdatePermissions(ctx context.Context, id uuid.UUID, permIDs []uuid.UUID) error {
+func (g *groupUsecaseImpl) UpdatePermissions(ctx context.Context, id uuid.UUID, permIDs []uuid.UUID, scopes map[uuid.UUID][]string) error {
 	if g.permRepo == nil {
 		return nil
 	}
@@ -96,6 +109,7 @@ func (g *groupUsecaseImpl) UpdatePermissions(ctx context.Context, id uuid.UUID,
 	if grp == nil {
 		return fmt.Errorf("group not found")
 	}
+	granted := make(map[uuid.UUID]*entities.Permission, len(permIDs))
 	for _, pid := range permIDs {
 		p, err := g.permRepo.GetByID(ctx, pid)
 		if err != nil {
@@ -104,8 +118,43 @@ func (g *groupUsecaseImpl) UpdatePermissions(ctx context.Context, id uuid.UUID,
 		if p == nil {
 			return fmt.Errorf("permission not found")
 		}
+		granted[pid] = p
+	}
+	if scopes != nil {
+		cleaned := make(map[uuid.UUID][]string, len(scopes))
+		for pid, patterns := range scopes {
+			p, ok := granted[pid]
+			if !ok {
+				return fmt.Errorf("vpn group scope given for a permission that is not granted")
+			}
+			if len(patterns) == 0 {
+				continue
+			}
+			if err := validateVPNGroupScope(p.Resource, patterns); err != nil {
+				return err
+			}
+			cleaned[pid] = patterns
+		}
+		scopes = cleaned
+	}
+	return g.permRepo.SetForGroup(ctx, id, permIDs, scopes)
+}
+
+// validateVPNGroupScope checks the VPN group patterns limiting a permission
+// of resource.
+func validateVPNGroupScope(resource string, patterns []string) error {
+	if resource != "openvpn" {
+		return fmt.Errorf("vpn group scope only applies to openvpn permissions")
+	}
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("vpn group scope patterns cannot be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid vpn group scope pattern %q", pattern)
+		}
 	}
-	return g.permRepo.SetForGroup(ctx, id, permIDs)
+	return nil
 }
 
 func (g *groupUsecaseImpl) GetPermissions(ctx context.Context, id uuid.UUID) ([]*entities.Permission, error) {
@@ -121,3 +170,11 @@ func (g *groupUsecaseImpl) ListPermissions(ctx context.Context) ([]*entities.Per
 	}
 	return g.permRepo.List(ctx)
 }
+
+// invalidatePermissions clears cached grants after group changes that do not
+// go through the permission repository.
+func (g *groupUsecaseImpl) invalidatePermissions(ctx context.Context) {
+	if c, ok := g.permRepo.(repositories.PermissionCache); ok {
+		c.InvalidatePermissions(ctx)
+	}
+}
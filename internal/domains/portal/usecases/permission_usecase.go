@@ -12,4 +12,6 @@ type PermissionUsecase interface {
 	Create(ctx context.Context, p *entities.Permission) error
 	Update(ctx context.Context, p *entities.Permission) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	CacheStats() entities.PermissionCacheStats
+	InvalidateCache(ctx context.Context)
 }
@@ -53,3 +53,18 @@ func (u *permissionUsecaseImpl) Update(ctx context.Context, p *entities.Permissi
 func (u *permissionUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	return u.repo.Delete(ctx, id)
 }
+
+// CacheStats reports the permission cache counters, or a disabled cache when
+// the repository does not cache grants.
+func (u *permissionUsecaseImpl) CacheStats() entities.PermissionCacheStats {
+	if c, ok := u.repo.(repositories.PermissionCache); ok {
+		return c.PermissionCacheStats()
+	}
+	return entities.PermissionCacheStats{}
+}
+
+func (u *permissionUsecaseImpl) InvalidateCache(ctx context.Context) {
+	if c, ok := u.repo.(repositories.PermissionCache); ok {
+		c.InvalidatePermissions(ctx)
+	}
+}
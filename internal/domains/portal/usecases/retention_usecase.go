@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"system-portal/internal/domains/portal/entities"
+)
+
+// ArchiveSink stores retention archives, such as a directory or an object
+// store. Put must not overwrite an existing archive.
+type ArchiveSink interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+}
+
+// RetentionPolicy sets how long rows are kept. A zero duration keeps rows
+// forever.
+type RetentionPolicy struct {
+	// AuditDefault applies to resource types without their own entry
+	AuditDefault       time.Duration
+	AuditResourceTypes map[string]time.Duration
+	Sessions           time.Duration
+	// RestoreHold keeps restored audit entries before they expire again
+	RestoreHold time.Duration
+	// BatchSize is the number of rows per archive file
+	BatchSize int
+}
+
+// RetentionUsecase archives and purges expired audit entries and sessions,
+// and restores archived audit entries for investigations.
+type RetentionUsecase interface {
+	// Run archives and purges expired rows. It is skipped while another
+	// instance is running it.
+	Run(ctx context.Context) (*entities.RetentionResult, error)
+	ListArchives(ctx context.Context, source string) ([]*entities.AuditArchive, error)
+	// Restore re-imports an audit archive and returns the entries added back
+	Restore(ctx context.Context, name string) (int, error)
+}
@@ -0,0 +1,250 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"system-portal/internal/domains/portal/entities"
+	"system-portal/internal/domains/portal/repositories"
+)
+
+var (
+	ErrArchiveNotFound      = errors.New("archive not found")
+	ErrArchiveNotRestorable = errors.New("only audit log archives can be restored")
+	ErrArchiveCorrupt       = errors.New("archive does not match its recorded digest")
+)
+
+type retentionUsecaseImpl struct {
+	archives repositories.AuditArchiveRepository
+	audit    repositories.AuditRepository
+	sink     ArchiveSink
+	policy   RetentionPolicy
+}
+
+func NewRetentionUsecase(archives repositories.AuditArchiveRepository, audit repositories.AuditRepository, sink ArchiveSink, policy RetentionPolicy) RetentionUsecase {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 5000
+	}
+	return &retentionUsecaseImpl{archives: archives, audit: audit, sink: sink, policy: policy}
+}
+
+func (u *retentionUsecaseImpl) Run(ctx context.Context) (*entities.RetentionResult, error) {
+	result := &entities.RetentionResult{}
+	// Every replica runs the job; only one may select and purge at a time
+	release, ok, err := u.archives.TryLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		result.Skipped = true
+		return result, nil
+	}
+	defer release()
+	now := time.Now()
+	batch := 0
+	if cutoffs, ok := u.auditCutoffs(now); ok {
+		for {
+			logs, err := u.archives.ExpiredAuditLogs(ctx, cutoffs, u.policy.BatchSize)
+			if err != nil {
+				return result, err
+			}
+			if len(logs) == 0 {
+				break
+			}
+			batch++
+			archive, err := u.archiveAuditLogs(ctx, now, batch, logs)
+			if err != nil {
+				return result, err
+			}
+			result.AuditLogs += len(logs)
+			result.Archives = append(result.Archives, archive)
+			if len(logs) < u.policy.BatchSize {
+				break
+			}
+		}
+	}
+	if u.policy.Sessions > 0 {
+		cutoff := now.Add(-u.policy.Sessions)
+		for {
+			sessions, err := u.archives.ExpiredSessions(ctx, cutoff, u.policy.BatchSize)
+			if err != nil {
+				return result, err
+			}
+			if len(sessions) == 0 {
+				break
+			}
+			batch++
+			archive, err := u.archiveSessions(ctx, now, batch, sessions)
+			if err != nil {
+				return result, err
+			}
+			result.UserSessions += len(sessions)
+			result.Archives = append(result.Archives, archive)
+			if len(sessions) < u.policy.BatchSize {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// auditCutoffs reports false when every audit entry is kept forever.
+func (u *retentionUsecaseImpl) auditCutoffs(now time.Time) (entities.RetentionCutoffs, bool) {
+	c := entities.RetentionCutoffs{
+		ResourceTypes:     map[string]*time.Time{},
+		HoldRestoredUntil: now.Add(-u.policy.RestoreHold),
+	}
+	expires := false
+	if u.policy.AuditDefault > 0 {
+		t := now.Add(-u.policy.AuditDefault)
+		c.Default = &t
+		expires = true
+	}
+	for resourceType, keep := range u.policy.AuditResourceTypes {
+		if keep <= 0 {
+			c.ResourceTypes[resourceType] = nil
+			continue
+		}
+		t := now.Add(-keep)
+		c.ResourceTypes[resourceType] = &t
+		expires = true
+	}
+	return c, expires
+}
+
+func (u *retentionUsecaseImpl) archiveAuditLogs(ctx context.Context, now time.Time, batch int, logs []*entities.AuditLog) (*entities.AuditArchive, error) {
+	archive := newArchive(entities.ArchiveSourceAuditLogs, now, batch, len(logs))
+	records := make([]interface{}, 0, len(logs))
+	for _, a := range logs {
+		records = append(records, newAuditRecord(a))
+		archive.Span(a.CreatedAt)
+		if archive.MinSeq == 0 || a.Seq < archive.MinSeq {
+			archive.MinSeq = a.Seq
+		}
+		if a.Seq > archive.MaxSeq {
+			archive.MaxSeq = a.Seq
+		}
+	}
+	if err := u.store(ctx, archive, records); err != nil {
+		return nil, err
+	}
+	if err := u.archives.PurgeAuditLogs(ctx, archive, logs); err != nil {
+		return nil, err
+	}
+	u.record(ctx, "audit.archive", archive)
+	return archive, nil
+}
+
+func (u *retentionUsecaseImpl) archiveSessions(ctx context.Context, now time.Time, batch int, sessions []*entities.ArchivedSession) (*entities.AuditArchive, error) {
+	archive := newArchive(entities.ArchiveSourceUserSessions, now, batch, len(sessions))
+	records := make([]interface{}, 0, len(sessions))
+	ids := make([]uuid.UUID, 0, len(sessions))
+	for _, s := range sessions {
+		records = append(records, s.Data)
+		ids = append(ids, s.ID)
+		archive.Span(s.CreatedAt)
+	}
+	if err := u.store(ctx, archive, records); err != nil {
+		return nil, err
+	}
+	if err := u.archives.PurgeSessions(ctx, archive, ids); err != nil {
+		return nil, err
+	}
+	u.record(ctx, "audit.archive", archive)
+	return archive, nil
+}
+
+func newArchive(source string, now time.Time, batch, rows int) *entities.AuditArchive {
+	return &entities.AuditArchive{
+		ID:        uuid.New(),
+		Name:      fmt.Sprintf("%s-%s-%03d.jsonl.gz", source, now.UTC().Format("20060102T150405Z"), batch),
+		Source:    source,
+		Rows:      rows,
+		CreatedAt: now,
+	}
+}
+
+// store writes records as gzipped JSON lines to the sink before anything is
+// deleted, and keeps the file's digest for restores.
+func (u *retentionUsecaseImpl) store(ctx context.Context, archive *entities.AuditArchive, records []interface{}) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	archive.SHA256 = hex.EncodeToString(sum[:])
+	if err := u.sink.Put(ctx, archive.Name, buf.Bytes()); err != nil {
+		return fmt.Errorf("write archive %s: %w", archive.Name, err)
+	}
+	return nil
+}
+
+func (u *retentionUsecaseImpl) ListArchives(ctx context.Context, source string) ([]*entities.AuditArchive, error) {
+	return u.archives.List(ctx, source)
+}
+
+func (u *retentionUsecaseImpl) Restore(ctx context.Context, name string) (int, error) {
+	archive, err := u.archives.GetByName(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if archive == nil {
+		return 0, ErrArchiveNotFound
+	}
+	if archive.Source != entities.ArchiveSourceAuditLogs {
+		return 0, ErrArchiveNotRestorable
+	}
+	data, err := u.sink.Get(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("read archive %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != archive.SHA256 {
+		return 0, ErrArchiveCorrupt
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	var logs []*entities.AuditLog
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for scanner.Scan() {
+		var r auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return 0, err
+		}
+		logs = append(logs, r.entry())
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	n, err := u.archives.RestoreAuditLogs(ctx, archive, logs, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	u.record(ctx, "audit.restore", archive)
+	return n, nil
+}
+
+// record audits retention itself, which runs without a user.
+func (u *retentionUsecaseImpl) record(ctx context.Context, action string, archive *entities.AuditArchive) {
+	resource := fmt.Sprintf("%s: %d %s rows", archive.Name, archive.Rows, archive.Source)
+	recordActorAudit(ctx, u.audit, ApprovalActor{Username: "system"}, action, "audit", resource, true)
+}
@@ -12,5 +12,7 @@ type UserUsecase interface {
 	List(ctx context.Context, filter *entities.UserFilter) ([]*entities.PortalUser, int, error)
 	Get(ctx context.Context, id uuid.UUID) (*entities.PortalUser, error)
 	Update(ctx context.Context, u *entities.PortalUser) error
+	// SetGroups replaces the user's memberships with primary and groupIDs
+	SetGroups(ctx context.Context, id, primary uuid.UUID, groupIDs []uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -27,8 +27,12 @@ func (u *userUsecaseImpl) Create(ctx context.Context, user *entities.PortalUser)
 	if existing, _ := u.repo.GetByEmail(ctx, user.Email); existing != nil {
 		return fmt.Errorf("email already exists")
 	}
-	if g, _ := u.groupRepo.GetByID(ctx, user.GroupID); g == nil {
-		return fmt.Errorf("group not found")
+	if err := u.checkGroups(ctx, append([]uuid.UUID{user.GroupID}, user.GroupIDs...)); err != nil {
+		return err
+	}
+	if user.AuthSource == entities.AuthSourceLDAP || user.AuthSource == entities.AuthSourceOIDC {
+		// external users never have a local password
+		user.Password = ""
 	}
 	if user.Password != "" {
 		hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -37,7 +41,14 @@ func (u *userUsecaseImpl) Create(ctx context.Context, user *entities.PortalUser)
 		}
 		user.Password = string(hash)
 	}
-	return u.repo.Create(ctx, user)
+	extra := user.GroupIDs
+	if err := u.repo.Create(ctx, user); err != nil {
+		return err
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+	return u.repo.SetGroups(ctx, user.ID, user.GroupID, extra)
 }
 
 func (u *userUsecaseImpl) List(ctx context.Context, filter *entities.UserFilter) ([]*entities.PortalUser, int, error) {
@@ -59,11 +70,13 @@ func (u *userUsecaseImpl) Update(ctx context.Context, user *entities.PortalUser)
 	// username and email should not be updated; keep existing values
 	user.Username = existing.Username
 	user.Email = existing.Email
+	user.AuthSource = existing.AuthSource
+	user.ExternalID = existing.ExternalID
 
 	if g, _ := u.groupRepo.GetByID(ctx, user.GroupID); g == nil {
 		return fmt.Errorf("group not found")
 	}
-	if user.Password != "" {
+	if user.Password != "" && (user.AuthSource == "" || user.AuthSource == entities.AuthSourceLocal) {
 		hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return err
@@ -85,3 +98,31 @@ func (u *userUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 	return u.repo.Delete(ctx, id)
 }
+
+func (u *userUsecaseImpl) SetGroups(ctx context.Context, id, primary uuid.UUID, groupIDs []uuid.UUID) error {
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("user not found")
+	}
+	if err := u.checkGroups(ctx, append([]uuid.UUID{primary}, groupIDs...)); err != nil {
+		return err
+	}
+	return u.repo.SetGroups(ctx, id, primary, groupIDs)
+}
+
+// checkGroups makes sure every group exists.
+func (u *userUsecaseImpl) checkGroups(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		g, err := u.groupRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if g == nil {
+			return fmt.Errorf("group not found")
+		}
+	}
+	return nil
+}
@@ -11,12 +11,28 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Redis    RedisConfig    `mapstructure:"redis"` // NEW: Redis configuration
-	Security SecurityConfig `mapstructure:"security"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Logger     LoggerConfig     `mapstructure:"logger"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	Redis      RedisConfig      `mapstructure:"redis"` // NEW: Redis configuration
+	Security   SecurityConfig   `mapstructure:"security"`
+	Validation ValidationConfig `mapstructure:"validation"`
+}
+
+// ValidationConfig holds input validation rules
+type ValidationConfig struct {
+	MacAddressFormats []string             `mapstructure:"macAddressFormats"`
+	Password          PasswordPolicyConfig `mapstructure:"password"`
+}
+
+// PasswordPolicyConfig is the complexity required of local passwords
+type PasswordPolicyConfig struct {
+	MinLength           int  `mapstructure:"minLength"`
+	RequireUppercase    bool `mapstructure:"requireUppercase"`
+	RequireLowercase    bool `mapstructure:"requireLowercase"`
+	RequireNumbers      bool `mapstructure:"requireNumbers"`
+	RequireSpecialChars bool `mapstructure:"requireSpecialChars"`
 }
 
 type ServerConfig struct {
@@ -54,6 +70,12 @@ type JWTConfig struct {
 	RefreshPrivateKeyPath      string        `mapstructure:"refreshPrivateKeyPath"`
 	AccessTokenExpireDuration  time.Duration `mapstructure:"accessTokenExpireDuration"`
 	RefreshTokenExpireDuration time.Duration `mapstructure:"refreshTokenExpireDuration"`
+
+	// AccessKeysDir holds a rotating keyring managed by cmd/jwt-keys; when
+	// set it replaces AccessPrivateKeyPath
+	AccessKeysDir string `mapstructure:"accessKeysDir"`
+	// KeyReloadInterval is how often the keyring is re-read to pick up rotations
+	KeyReloadInterval time.Duration `mapstructure:"keyReloadInterval"`
 }
 
 // Redis configuration
@@ -69,9 +91,204 @@ type RedisConfig struct {
 
 // Security configuration including CORS settings
 type SecurityConfig struct {
-	EnableSecurityHeaders bool       `mapstructure:"enableSecurityHeaders"`
-	CORS                  CORSConfig `mapstructure:"cors"`
-	EncryptionKey         string     `mapstructure:"encryptionKey"`
+	EnableSecurityHeaders bool                  `mapstructure:"enableSecurityHeaders"`
+	CORS                  CORSConfig            `mapstructure:"cors"`
+	EncryptionKey         string                `mapstructure:"encryptionKey"`
+	Session               SessionConfig         `mapstructure:"session"`
+	MFA                   MFAConfig             `mapstructure:"mfa"`
+	OIDC                  OIDCConfig            `mapstructure:"oidc"`
+	Lockout               LockoutConfig         `mapstructure:"lockout"`
+	APITokens             APITokenConfig        `mapstructure:"apiTokens"`
+	PermissionCache       PermissionCacheConfig `mapstructure:"permissionCache"`
+	Approval              ApprovalConfig        `mapstructure:"approval"`
+	Elevation             ElevationConfig       `mapstructure:"elevation"`
+	Audit                 AuditConfig           `mapstructure:"audit"`
+}
+
+// AuditConfig controls the tamper evidence of the audit log
+type AuditConfig struct {
+	// CheckpointKey is a base64 Ed25519 seed used to sign chain checkpoints (empty disables them)
+	CheckpointKey string `mapstructure:"checkpointKey"`
+	// TrustedCheckpointKeys are base64 Ed25519 public keys of retired
+	// checkpoint keys whose checkpoints still verify
+	TrustedCheckpointKeys []string `mapstructure:"trustedCheckpointKeys"`
+	// CheckpointInterval is how often the chain head is signed
+	CheckpointInterval time.Duration        `mapstructure:"checkpointInterval"`
+	Retention          AuditRetentionConfig `mapstructure:"retention"`
+	Sinks              AuditSinksConfig     `mapstructure:"sinks"`
+}
+
+// AuditSinksConfig forwards every audit entry to external collectors in the
+// background; entries a sink keeps refusing are dead-lettered and retried
+type AuditSinksConfig struct {
+	// QueueSize is the number of entries each sink may have waiting
+	QueueSize int `mapstructure:"queueSize"`
+	// MaxAttempts is how often delivery is tried before dead-lettering
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+	// Timeout limits a single delivery attempt
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RedeliverInterval is how often dead letters are retried (0 disables)
+	RedeliverInterval time.Duration       `mapstructure:"redeliverInterval"`
+	Syslog            []SyslogSinkConfig  `mapstructure:"syslog"`
+	Webhooks          []WebhookSinkConfig `mapstructure:"webhooks"`
+}
+
+// SyslogSinkConfig is an RFC 5424 syslog receiver
+type SyslogSinkConfig struct {
+	Name string `mapstructure:"name"`
+	// Network is udp, tcp or tls
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	// Facility defaults to 13 (log audit)
+	Facility int `mapstructure:"facility"`
+	// Format of the message, jsonl or cef
+	Format             string `mapstructure:"format"`
+	CAFile             string `mapstructure:"caFile"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+// WebhookSinkConfig is an HTTP endpoint receiving one signed POST per entry
+type WebhookSinkConfig struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+	// Secret is the HMAC-SHA256 signing key (empty sends unsigned requests)
+	Secret  string            `mapstructure:"secret"`
+	Format  string            `mapstructure:"format"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// AuditRetentionConfig archives and deletes audit entries and sessions once
+// they are older than their retention period (0 keeps them forever)
+type AuditRetentionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often expired rows are archived
+	Interval time.Duration `mapstructure:"interval"`
+	// AuditLogs applies to resource types not listed in ResourceTypes
+	AuditLogs     time.Duration            `mapstructure:"auditLogs"`
+	ResourceTypes map[string]time.Duration `mapstructure:"resourceTypes"`
+	// Sessions applies to sessions that ended or expired
+	Sessions time.Duration `mapstructure:"sessions"`
+	// ArchiveDir receives the gzipped JSONL archives
+	ArchiveDir string `mapstructure:"archiveDir"`
+	// BatchSize is the number of rows per archive file
+	BatchSize int `mapstructure:"batchSize"`
+	// RestoreHold keeps restored entries before they are archived again
+	RestoreHold time.Duration `mapstructure:"restoreHold"`
+}
+
+// PermissionCacheConfig controls the in-memory cache of group permissions
+// used by the permission middleware
+type PermissionCacheConfig struct {
+	// TTL is how long a group's grants are trusted before re-reading Postgres (0 disables)
+	TTL time.Duration `mapstructure:"ttl"`
+	// Listen invalidates the cache on changes made by other replicas via LISTEN/NOTIFY
+	Listen bool `mapstructure:"listen"`
+}
+
+// ApprovalConfig selects destructive operations that are held until a second
+// portal user approves them
+type ApprovalConfig struct {
+	// Permissions whose guarded operations need approval, e.g. openvpn.delete_users
+	Permissions []string `mapstructure:"permissions"`
+	// TTL is how long a change request can be approved before it expires
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// ElevationConfig controls time-bound grants of a permission or group
+type ElevationConfig struct {
+	// MaxDuration is the longest elevation that can be requested
+	MaxDuration time.Duration `mapstructure:"maxDuration"`
+	// PendingTTL expires requests nobody reviewed in time
+	PendingTTL time.Duration `mapstructure:"pendingTTL"`
+	// SweepInterval is how often ended grants are expired and audited
+	SweepInterval time.Duration         `mapstructure:"sweepInterval"`
+	AutoApprove   []ElevationRuleConfig `mapstructure:"autoApprove"`
+}
+
+// ElevationRuleConfig grants matching elevation requests without a reviewer
+type ElevationRuleConfig struct {
+	// Permission or Group the rule applies to
+	Permission string `mapstructure:"permission"`
+	Group      string `mapstructure:"group"`
+	// VPNGroupScope limits a permission rule to requests within these VPN
+	// groups (empty means any scope, including unrestricted)
+	VPNGroupScope []string `mapstructure:"vpnGroupScope"`
+	// RequesterGroups limits the rule to members of these groups (empty means anyone)
+	RequesterGroups []string      `mapstructure:"requesterGroups"`
+	MaxDuration     time.Duration `mapstructure:"maxDuration"`
+}
+
+// APITokenConfig controls personal API tokens used by automation
+type APITokenConfig struct {
+	// MaxLifetime caps token expiry and is the default when none is requested (0 allows tokens that never expire)
+	MaxLifetime time.Duration `mapstructure:"maxLifetime"`
+}
+
+// LockoutConfig controls brute-force protection on /auth/login
+type LockoutConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxUserFailures locks a username after this many failures within Window (0 disables)
+	MaxUserFailures int `mapstructure:"maxUserFailures"`
+	// MaxIPFailures locks a source IP after this many failures within Window (0 disables)
+	MaxIPFailures int `mapstructure:"maxIPFailures"`
+	// Window is how long a failure counts towards the thresholds
+	Window time.Duration `mapstructure:"window"`
+	// Duration is how long a lockout lasts unless an admin unlocks the account
+	Duration time.Duration `mapstructure:"duration"`
+	// BaseDelay is the wait after the first failure; it doubles with each further failure up to MaxDelay
+	BaseDelay time.Duration `mapstructure:"baseDelay"`
+	MaxDelay  time.Duration `mapstructure:"maxDelay"`
+}
+
+// OIDCConfig enables portal single sign-on through an OpenID Connect provider
+type OIDCConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	IssuerURL    string   `mapstructure:"issuerURL"`
+	ClientID     string   `mapstructure:"clientID"`
+	ClientSecret string   `mapstructure:"clientSecret"`
+	RedirectURL  string   `mapstructure:"redirectURL"`
+	Scopes       []string `mapstructure:"scopes"`
+	// UsernameClaim names the ID token claim used as portal username
+	UsernameClaim string `mapstructure:"usernameClaim"`
+	// GroupsClaim names the claim holding the user's IdP groups; dotted
+	// names address nested claims such as "realm_access.roles"
+	GroupsClaim   string             `mapstructure:"groupsClaim"`
+	GroupMappings []OIDCGroupMapping `mapstructure:"groupMappings"`
+	// PostLoginRedirectURL receives the tokens in the URL fragment after the
+	// callback; when empty the callback responds with JSON
+	PostLoginRedirectURL string `mapstructure:"postLoginRedirectURL"`
+	// StateTTL is how long the user may take at the IdP
+	StateTTL time.Duration `mapstructure:"stateTTL"`
+	// TrustIdPMFA skips the portal's MFA challenge when the ID token's amr
+	// claim holds one of MFAMethods or its acr one of MFAACRValues
+	TrustIdPMFA  bool     `mapstructure:"trustIdPMFA"`
+	MFAMethods   []string `mapstructure:"mfaMethods"`
+	MFAACRValues []string `mapstructure:"mfaACRValues"`
+}
+
+// OIDCGroupMapping maps a value of the groups claim to a portal group name.
+// The first matching mapping wins.
+type OIDCGroupMapping struct {
+	Claim string `mapstructure:"claim"`
+	Group string `mapstructure:"group"`
+}
+
+// MFAConfig controls TOTP second factor for portal logins
+type MFAConfig struct {
+	// Issuer is shown as the account label in authenticator apps
+	Issuer string `mapstructure:"issuer"`
+	// ChallengeTTL is how long the second step of a login may take
+	ChallengeTTL time.Duration `mapstructure:"challengeTTL"`
+}
+
+// SessionConfig controls server-side validation of login sessions
+type SessionConfig struct {
+	// IdleTimeout revokes a session after this long without requests (0 disables)
+	IdleTimeout time.Duration `mapstructure:"idleTimeout"`
+	// CacheTTL is how long a resolved session is trusted before re-reading Postgres
+	CacheTTL time.Duration `mapstructure:"cacheTTL"`
 }
 
 type CORSConfig struct {
@@ -133,6 +350,8 @@ func setDefaults() {
 	viper.SetDefault("jwt.refreshPrivateKeyPath", "")
 	viper.SetDefault("jwt.accessTokenExpireDuration", time.Hour)
 	viper.SetDefault("jwt.refreshTokenExpireDuration", 24*time.Hour)
+	viper.SetDefault("jwt.accessKeysDir", "")
+	viper.SetDefault("jwt.keyReloadInterval", time.Minute)
 
 	// Legacy HMAC defaults (for backward compatibility)
 	viper.SetDefault("jwt.secret", "default-hmac-secret-change-in-production")
@@ -162,4 +381,50 @@ func setDefaults() {
 	viper.SetDefault("security.cors.allowedHeaders", []string{"Authorization", "Content-Type"})
 	viper.SetDefault("security.cors.allowCredentials", true)
 	viper.SetDefault("security.encryptionKey", "")
+	viper.SetDefault("security.session.idleTimeout", 30*time.Minute)
+	viper.SetDefault("security.session.cacheTTL", 30*time.Second)
+	viper.SetDefault("security.mfa.issuer", "System Portal")
+	viper.SetDefault("security.mfa.challengeTTL", 5*time.Minute)
+	viper.SetDefault("security.oidc.enabled", false)
+	viper.SetDefault("security.oidc.scopes", []string{"openid", "profile", "email"})
+	viper.SetDefault("security.oidc.usernameClaim", "preferred_username")
+	viper.SetDefault("security.oidc.groupsClaim", "groups")
+	viper.SetDefault("security.oidc.stateTTL", 10*time.Minute)
+	viper.SetDefault("security.oidc.trustIdPMFA", false)
+	viper.SetDefault("security.oidc.mfaMethods", []string{"mfa", "otp", "hwk"})
+	viper.SetDefault("security.oidc.mfaACRValues", []string{})
+	viper.SetDefault("security.lockout.enabled", true)
+	viper.SetDefault("security.lockout.maxUserFailures", 5)
+	viper.SetDefault("security.lockout.maxIPFailures", 20)
+	viper.SetDefault("security.lockout.window", 15*time.Minute)
+	viper.SetDefault("security.lockout.duration", 15*time.Minute)
+	viper.SetDefault("security.lockout.baseDelay", time.Second)
+	viper.SetDefault("security.lockout.maxDelay", 30*time.Second)
+	viper.SetDefault("security.apiTokens.maxLifetime", 365*24*time.Hour)
+	viper.SetDefault("security.permissionCache.ttl", 5*time.Minute)
+	viper.SetDefault("security.permissionCache.listen", true)
+	viper.SetDefault("security.approval.permissions", []string{})
+	viper.SetDefault("security.approval.ttl", 72*time.Hour)
+	viper.SetDefault("security.elevation.maxDuration", 4*time.Hour)
+	viper.SetDefault("security.elevation.pendingTTL", 24*time.Hour)
+	viper.SetDefault("security.elevation.sweepInterval", time.Minute)
+	viper.SetDefault("security.audit.checkpointKey", "")
+	viper.SetDefault("security.audit.trustedCheckpointKeys", []string{})
+	viper.SetDefault("security.audit.checkpointInterval", time.Hour)
+	viper.SetDefault("security.audit.retention.enabled", false)
+	viper.SetDefault("security.audit.retention.interval", 24*time.Hour)
+	viper.SetDefault("security.audit.retention.auditLogs", 365*24*time.Hour)
+	viper.SetDefault("security.audit.retention.sessions", 90*24*time.Hour)
+	viper.SetDefault("security.audit.retention.archiveDir", "./archives")
+	viper.SetDefault("security.audit.retention.batchSize", 5000)
+	viper.SetDefault("security.audit.retention.restoreHold", 30*24*time.Hour)
+	viper.SetDefault("security.audit.sinks.queueSize", 1000)
+	viper.SetDefault("security.audit.sinks.maxAttempts", 5)
+	viper.SetDefault("security.audit.sinks.initialBackoff", time.Second)
+	viper.SetDefault("security.audit.sinks.maxBackoff", 30*time.Second)
+	viper.SetDefault("security.audit.sinks.timeout", 5*time.Second)
+	viper.SetDefault("security.audit.sinks.redeliverInterval", 5*time.Minute)
+
+	// Validation defaults
+	viper.SetDefault("validation.password.minLength", 8)
 }
@@ -0,0 +1,64 @@
+// Package archive stores retention archives outside the database.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSink keeps archive files in a local directory.
+type DirSink struct{ dir string }
+
+// NewDirSink returns a sink writing to dir, which is created on first use.
+func NewDirSink(dir string) *DirSink { return &DirSink{dir: dir} }
+
+// Put writes a new archive. The file only appears under its name once it is
+// complete, and existing archives are never overwritten.
+func (s *DirSink) Put(ctx context.Context, name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("archive %s already exists", name)
+	}
+	f, err := os.CreateTemp(s.dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get reads an archive.
+func (s *DirSink) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (s *DirSink) path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid archive name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,170 @@
+// Package auditsink forwards audit entries to external collectors.
+package auditsink
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"system-portal/internal/domains/portal/entities"
+)
+
+// Syslog severities used for successful and failed operations.
+const (
+	syslogWarning = 4
+	syslogInfo    = 6
+)
+
+// syslogEnterpriseID names the structured data element of audit messages.
+const syslogEnterpriseID = "audit@32473"
+
+// SyslogConfig describes an RFC 5424 syslog receiver.
+type SyslogConfig struct {
+	Name string
+	// Network is udp, tcp or tls
+	Network string
+	Address string
+	// Facility defaults to 13 (log audit)
+	Facility int
+	// Format of the message part, jsonl or cef
+	Format string
+	// CAFile verifies the receiver's certificate for tls
+	CAFile             string
+	InsecureSkipVerify bool
+}
+
+// Syslog sends each entry as one RFC 5424 message. TCP and TLS use octet
+// counting framing (RFC 6587); the connection is reopened after an error.
+type Syslog struct {
+	cfg      SyslogConfig
+	tls      *tls.Config
+	hostname string
+	procID   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewSyslog(cfg SyslogConfig) (*Syslog, error) {
+	switch cfg.Network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("syslog sink %s: network must be udp, tcp or tls", cfg.Name)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog sink %s: address is required", cfg.Name)
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = 13
+	}
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return nil, fmt.Errorf("syslog sink %s: facility must be 0 to 23", cfg.Name)
+	}
+	s := &Syslog{cfg: cfg, hostname: "-", procID: fmt.Sprint(os.Getpid())}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		s.hostname = h
+	}
+	if cfg.Network == "tls" {
+		host, _, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("syslog sink %s: %w", cfg.Name, err)
+		}
+		s.tls = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.InsecureSkipVerify}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("syslog sink %s: %w", cfg.Name, err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("syslog sink %s: no certificates in %s", cfg.Name, cfg.CAFile)
+			}
+			s.tls.RootCAs = pool
+		}
+	}
+	return s, nil
+}
+
+func (s *Syslog) Name() string   { return s.cfg.Name }
+func (s *Syslog) Format() string { return s.cfg.Format }
+
+func (s *Syslog) Send(ctx context.Context, a *entities.AuditLog, body []byte) error {
+	msg := s.message(a, body)
+	if s.cfg.Network != "udp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	} else {
+		_ = s.conn.SetWriteDeadline(time.Time{})
+	}
+	if _, err := s.conn.Write(msg); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *Syslog) dial(ctx context.Context) (net.Conn, error) {
+	if s.tls != nil {
+		d := &tls.Dialer{Config: s.tls}
+		return d.DialContext(ctx, "tcp", s.cfg.Address)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, s.cfg.Network, s.cfg.Address)
+}
+
+// message formats the RFC 5424 header, the entry's identifiers as
+// structured data and body as the message.
+func (s *Syslog) message(a *entities.AuditLog, body []byte) []byte {
+	severity := syslogInfo
+	if !a.Success {
+		severity = syslogWarning
+	}
+	sd := fmt.Sprintf(`[%s id="%s" seq="%d" user="%s" action="%s" success="%t"]`, syslogEnterpriseID,
+		a.ID, a.Seq, sdEscape(a.Username), sdEscape(a.Action), a.Success)
+	header := fmt.Sprintf("<%d>1 %s %s system-portal %s %s %s ",
+		s.cfg.Facility*8+severity,
+		a.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		headerField(s.hostname, 255), s.procID, headerField(a.Action, 32), sd)
+	return append([]byte(header), body...)
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func sdEscape(v string) string { return sdEscaper.Replace(v) }
+
+// headerField makes v a valid header field: printable ASCII without
+// spaces, at most max characters, or "-" when empty.
+func headerField(v string, max int) string {
+	var b strings.Builder
+	for _, r := range v {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if len(out) > max {
+		out = out[:max]
+	}
+	if out == "" {
+		return "-"
+	}
+	return out
+}
@@ -24,6 +24,12 @@ import (
 	"system-portal/pkg/logger"
 )
 
+// streamingRoutes write their response as it is produced and may run for
+// longer than the request timeout.
+var streamingRoutes = map[string]bool{
+	"/api/portal/audit/logs/export": true,
+}
+
 type RouterConfig struct {
 	Port            string // ✅ Add missing Port
 	Mode            string
@@ -70,6 +76,7 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	// Global middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(r.corsMiddleware.Handler())
 	router.Use(r.corsMiddleware.SecurityHeaders())
 	router.Use(r.validationMiddleware.StrictJSONBinding())
@@ -77,13 +84,21 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		router.Use(r.auditMiddleware.Handler())
 	}
 
-	// Timeout middleware
-	router.Use(timeout.New(
+	// Timeout middleware. It buffers the whole response, so streaming
+	// routes bypass it
+	withTimeout := timeout.New(
 		timeout.WithTimeout(r.config.TimeoutDuration),
 		timeout.WithHandler(func(c *gin.Context) {
 			c.Next()
 		}),
-	))
+	)
+	router.Use(func(c *gin.Context) {
+		if streamingRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+		withTimeout(c)
+	})
 
 	// Health check and API info
 	r.setupSystemRoutes(router)
@@ -107,3 +107,70 @@ func (c *Client) Authenticate(username, password string) error {
 
 	return nil
 }
+
+// UserEntry holds the directory attributes used to provision portal users.
+type UserEntry struct {
+	DN          string
+	Username    string
+	Email       string
+	DisplayName string
+	MemberOf    []string
+}
+
+// AuthenticateUser binds as the user and returns their directory entry,
+// including the DNs of the groups they are a direct member of.
+func (c *Client) AuthenticateUser(username, password string) (*UserEntry, error) {
+	// an empty password would be treated as an unauthenticated bind and succeed
+	if password == "" {
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	conn, err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	searchRequest := ldap.NewSearchRequest(
+		c.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0, 0, false,
+		fmt.Sprintf("(&(objectClass=user)(sAMAccountName=%s)(!(userAccountControl:1.2.840.113556.1.4.803:=2)))", ldap.EscapeFilter(username)),
+		[]string{"dn", "sAMAccountName", "mail", "userPrincipalName", "displayName", "memberOf"},
+		nil,
+	)
+
+	searchResult, err := conn.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("LDAP search failed: %w", err)
+	}
+
+	if len(searchResult.Entries) != 1 {
+		return nil, fmt.Errorf("user not found or multiple users found")
+	}
+
+	entry := searchResult.Entries[0]
+	err = conn.Bind(entry.DN, password)
+	if err != nil {
+		if ldapErr, ok := err.(*ldap.Error); ok && ldapErr.ResultCode == ldap.LDAPResultInvalidCredentials {
+			return nil, fmt.Errorf("invalid credentials")
+		}
+		return nil, fmt.Errorf("LDAP authentication failed: %w", err)
+	}
+
+	user := &UserEntry{
+		DN:          entry.DN,
+		Username:    entry.GetAttributeValue("sAMAccountName"),
+		Email:       entry.GetAttributeValue("mail"),
+		DisplayName: entry.GetAttributeValue("displayName"),
+		MemberOf:    entry.GetAttributeValues("memberOf"),
+	}
+	if user.Username == "" {
+		user.Username = username
+	}
+	if user.Email == "" {
+		user.Email = entry.GetAttributeValue("userPrincipalName")
+	}
+	return user, nil
+}
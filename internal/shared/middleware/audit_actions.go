@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// auditActions maps "METHOD route" to the semantic action recorded in the
+// audit log. "{action}" is filled from the :action path parameter or the
+// top-level "action" field of the request body.
+var auditActions = map[string]string{
+	"POST /auth/login":              "auth.login",
+	"POST /auth/login/mfa":          "auth.login_mfa",
+	"POST /auth/login/mfa/enroll":   "auth.login_mfa_enroll",
+	"POST /auth/refresh":            "auth.refresh",
+	"POST /auth/logout":             "auth.logout",
+	"PUT /auth/me/password":         "auth.password.change",
+	"DELETE /auth/sessions":         "auth.session.revoke_others",
+	"DELETE /auth/sessions/:id":     "auth.session.revoke",
+	"POST /auth/mfa/enroll":         "auth.mfa.enroll",
+	"POST /auth/mfa/enroll/verify":  "auth.mfa.verify",
+	"POST /auth/mfa/recovery-codes": "auth.mfa.recovery_codes",
+	"POST /auth/mfa/disable":        "auth.mfa.disable",
+	"POST /auth/tokens":             "auth.token.create",
+	"DELETE /auth/tokens/:id":       "auth.token.revoke",
+
+	"POST /api/openvpn/users":                      "openvpn.user.create",
+	"PUT /api/openvpn/users/:username":             "openvpn.user.update",
+	"PUT /api/openvpn/users/:username/:action":     "openvpn.user.{action}",
+	"DELETE /api/openvpn/users/:username":          "openvpn.user.delete",
+	"POST /api/openvpn/users/:username/disconnect": "openvpn.user.disconnect",
+	"POST /api/openvpn/groups":                     "openvpn.group.create",
+	"PUT /api/openvpn/groups/:groupName":           "openvpn.group.update",
+	"PUT /api/openvpn/groups/:groupName/:action":   "openvpn.group.{action}",
+	"DELETE /api/openvpn/groups/:groupName":        "openvpn.group.delete",
+	"POST /api/openvpn/bulk/users/create":          "openvpn.user.bulk_create",
+	"POST /api/openvpn/bulk/users/import":          "openvpn.user.import",
+	"POST /api/openvpn/bulk/users/actions":         "openvpn.user.bulk_{action}",
+	"POST /api/openvpn/bulk/users/extend":          "openvpn.user.bulk_extend",
+	"POST /api/openvpn/bulk/users/disconnect":      "openvpn.user.bulk_disconnect",
+	"POST /api/openvpn/bulk/groups/create":         "openvpn.group.bulk_create",
+	"POST /api/openvpn/bulk/groups/actions":        "openvpn.group.bulk_{action}",
+	"POST /api/openvpn/bulk/groups/import":         "openvpn.group.import",
+
+	"POST /api/portal/users":                           "portal.user.create",
+	"PUT /api/portal/users/:id":                        "portal.user.update",
+	"DELETE /api/portal/users/:id":                     "portal.user.delete",
+	"PUT /api/portal/users/:id/activate":               "portal.user.activate",
+	"PUT /api/portal/users/:id/deactivate":             "portal.user.deactivate",
+	"PUT /api/portal/users/:id/reset-password":         "portal.user.reset_password",
+	"PUT /api/portal/users/:id/unlock":                 "portal.user.unlock",
+	"PUT /api/portal/users/:id/groups":                 "portal.user.set_groups",
+	"DELETE /api/portal/users/:id/sessions":            "portal.user.revoke_sessions",
+	"DELETE /api/portal/users/:id/sessions/:sessionId": "portal.user.revoke_session",
+	"POST /api/portal/groups":                          "portal.group.create",
+	"PUT /api/portal/groups/:id":                       "portal.group.update",
+	"DELETE /api/portal/groups/:id":                    "portal.group.delete",
+	"PUT /api/portal/groups/:id/permissions":           "portal.group.set_permissions",
+	"POST /api/portal/permissions":                     "portal.permission.create",
+	"PUT /api/portal/permissions/:id":                  "portal.permission.update",
+	"DELETE /api/portal/permissions/:id":               "portal.permission.delete",
+	"DELETE /api/portal/permissions/cache":             "portal.permission.flush_cache",
+	"POST /api/portal/approvals/:id/approve":           "portal.approval.approve",
+	"POST /api/portal/approvals/:id/reject":            "portal.approval.reject",
+	"POST /api/portal/approvals/:id/cancel":            "portal.approval.cancel",
+	"POST /api/portal/elevations":                      "portal.elevation.request",
+	"POST /api/portal/elevations/:id/revoke":           "portal.elevation.revoke",
+	"POST /api/portal/elevations/:id/approve":          "portal.elevation.approve",
+	"POST /api/portal/elevations/:id/reject":           "portal.elevation.reject",
+
+	"POST /api/portal/connections/openvpn":                   "config.openvpn.create",
+	"PUT /api/portal/connections/openvpn":                    "config.openvpn.update",
+	"DELETE /api/portal/connections/openvpn":                 "config.openvpn.delete",
+	"POST /api/portal/connections/openvpn/test":              "config.openvpn.test",
+	"POST /api/portal/connections/ldap":                      "config.ldap.create",
+	"PUT /api/portal/connections/ldap":                       "config.ldap.update",
+	"DELETE /api/portal/connections/ldap":                    "config.ldap.delete",
+	"POST /api/portal/connections/ldap/test":                 "config.ldap.test",
+	"POST /api/portal/connections/ldap/group-mappings":       "config.ldap_mapping.create",
+	"PUT /api/portal/connections/ldap/group-mappings/:id":    "config.ldap_mapping.update",
+	"DELETE /api/portal/connections/ldap/group-mappings/:id": "config.ldap_mapping.delete",
+}
+
+// auditActionValues are the values "{action}" may take; anything else is
+// recorded as "unknown" so callers cannot choose the action logged.
+var auditActionValues = map[string]bool{
+	"enable":          true,
+	"disable":         true,
+	"reset_otp":       true,
+	"change_password": true,
+}
+
+// auditAction names the operation behind a request. Unknown routes fall
+// back to the first path segment after /api and the lower-cased method.
+func auditAction(method, route, actionParam string, body []byte) string {
+	name, ok := auditActions[method+" "+route]
+	if !ok {
+		return auditResource(route) + "." + strings.ToLower(method)
+	}
+	if !strings.Contains(name, "{action}") {
+		return name
+	}
+	action := actionParam
+	if action == "" && len(body) > 0 {
+		var b struct {
+			Action string `json:"action"`
+		}
+		_ = json.Unmarshal(body, &b)
+		action = b.Action
+	}
+	action = strings.ReplaceAll(strings.ToLower(action), "-", "_")
+	if !auditActionValues[action] {
+		action = "unknown"
+	}
+	return strings.Replace(name, "{action}", action, 1)
+}
+
+// auditResource is the domain a route belongs to, e.g. "openvpn" for
+// /api/openvpn/users or "auth" for /auth/login.
+func auditResource(route string) string {
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	if len(parts) > 1 && parts[0] == "api" {
+		return parts[1]
+	}
+	if parts[0] == "" {
+		return "unknown"
+	}
+	return parts[0]
+}
@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,9 +14,11 @@ import (
 	portalrepos "system-portal/internal/domains/portal/repositories"
 	"system-portal/internal/domains/portal/usecases"
 	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
 )
 
-// AuditMiddleware logs simple request information.
+// AuditMiddleware logs every request and records state-changing API calls
+// with their semantic action, parameters, redacted body and outcome.
 type AuditMiddleware struct {
 	uc     usecases.AuditUsecase
 	users  portalrepos.UserRepository
@@ -24,33 +29,53 @@ func NewAuditMiddleware(u usecases.AuditUsecase, userRepo portalrepos.UserReposi
 	return &AuditMiddleware{uc: u, users: userRepo, groups: groupRepo}
 }
 
+// maxAuditBody bounds how much of a request body is kept for the audit log.
+const maxAuditBody = 64 << 10
+
 func (a *AuditMiddleware) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		audited := a.uc != nil && isAuditedMethod(c.Request.Method)
+		var body []byte
+		if audited {
+			body = captureBody(c)
+		}
 		c.Next()
 		duration := time.Since(start)
+		requestID := c.GetString("requestID")
 		logger.Log.WithFields(map[string]interface{}{
-			"method":   c.Request.Method,
-			"path":     c.Request.URL.Path,
-			"status":   c.Writer.Status(),
-			"duration": duration.String(),
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"duration":   duration.String(),
+			"request_id": requestID,
 		}).Info("request handled")
 
-		if a.uc != nil && (c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPut || c.Request.Method == http.MethodDelete) {
-			resourceType := ""
-			full := strings.Trim(c.FullPath(), "/")
-			if full != "" {
-				parts := strings.Split(full, "/")
-				resourceType = parts[0]
+		if audited {
+			route := c.FullPath()
+			var params map[string]string
+			if len(c.Params) > 0 {
+				params = make(map[string]string, len(c.Params))
+				for _, p := range c.Params {
+					params[p.Key] = truncate(p.Value, 200)
+				}
 			}
 			logEntry := &entities.AuditLog{
 				ID:           uuid.New(),
-				Action:       c.Request.Method,
-				ResourceType: resourceType,
-				ResourceName: c.FullPath(),
+				Action:       truncate(auditAction(c.Request.Method, route, c.Param("action"), body), 100),
+				ResourceType: truncate(auditResource(route), 50),
+				ResourceName: truncate(c.Request.URL.Path, 200),
 				IPAddress:    c.ClientIP(),
 				Success:      c.Writer.Status() < 400,
 				CreatedAt:    time.Now(),
+				Method:       c.Request.Method,
+				Route:        truncate(route, 200),
+				PathParams:   params,
+				RequestBody:  utils.RedactJSON(body),
+				StatusCode:   c.Writer.Status(),
+				DurationMs:   duration.Milliseconds(),
+				UserAgent:    truncate(c.Request.UserAgent(), 500),
+				RequestID:    requestID,
 			}
 
 			if uid, ok := c.Get("userID"); ok {
@@ -60,7 +85,7 @@ func (a *AuditMiddleware) Handler() gin.HandlerFunc {
 			}
 			if uname, ok := c.Get("username"); ok {
 				if name, ok := uname.(string); ok {
-					logEntry.Username = name
+					logEntry.Username = truncate(name, 50)
 					if logEntry.UserID == uuid.Nil && a.users != nil {
 						if usr, err := a.users.GetByUsername(c.Request.Context(), name); err == nil && usr != nil {
 							logEntry.UserID = usr.ID
@@ -75,7 +100,7 @@ func (a *AuditMiddleware) Handler() gin.HandlerFunc {
 			}
 			if group, ok := c.Get("role"); ok && logEntry.UserGroup == "" {
 				if g, ok := group.(string); ok {
-					logEntry.UserGroup = g
+					logEntry.UserGroup = truncate(g, 50)
 				}
 			}
 
@@ -85,3 +110,44 @@ func (a *AuditMiddleware) Handler() gin.HandlerFunc {
 		}
 	}
 }
+
+func isAuditedMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// captureBody reads a JSON request body for the audit log and restores it
+// for the handler. Larger or non-JSON bodies, such as CSV imports, are not
+// kept.
+func captureBody(c *gin.Context) []byte {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return nil
+	}
+	if c.Request.ContentLength > maxAuditBody {
+		return nil
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBody+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+	if err != nil || len(raw) > maxAuditBody {
+		return nil
+	}
+	return raw
+}
+
+// truncate makes s storable in a VARCHAR(n) column: invalid UTF-8 and NUL,
+// which PostgreSQL rejects, are replaced, and s is cut to at most n bytes
+// on a character boundary. An entry that fails to insert would be lost.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	s = strings.ReplaceAll(s, "\x00", "\uFFFD")
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
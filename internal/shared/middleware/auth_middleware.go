@@ -2,24 +2,39 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
+	authrepos "system-portal/internal/domains/auth/repositories"
+	authusecases "system-portal/internal/domains/auth/usecases"
+	vpnentities "system-portal/internal/domains/openvpn/entities"
 	http "system-portal/internal/shared/response"
 	"system-portal/pkg/jwt"
+	"system-portal/pkg/logger"
+	"system-portal/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT access tokens.
+// AuthMiddleware validates JWT access tokens and their server-side sessions,
+// as well as personal API tokens.
 type AuthMiddleware struct {
-	jwtService *jwt.RSAService
+	jwtService  *jwt.RSAService
+	sessions    authrepos.SessionRepository
+	apiTokens   authusecases.APITokenUsecase
+	idleTimeout time.Duration
 }
 
-// NewAuthMiddleware creates a new middleware instance.
-func NewAuthMiddleware(jwtService *jwt.RSAService) *AuthMiddleware {
-	return &AuthMiddleware{jwtService: jwtService}
+// NewAuthMiddleware creates a new middleware instance. When sessions is nil
+// only the token signature and expiry are checked; when apiTokens is nil
+// API tokens are rejected.
+func NewAuthMiddleware(jwtService *jwt.RSAService, sessions authrepos.SessionRepository, apiTokens authusecases.APITokenUsecase, idleTimeout time.Duration) *AuthMiddleware {
+	return &AuthMiddleware{jwtService: jwtService, sessions: sessions, apiTokens: apiTokens, idleTimeout: idleTimeout}
 }
 
-// RequireAuth ensures a valid Bearer token is provided.
+// RequireAuth ensures a valid Bearer token is provided and that the session
+// it belongs to is still active. The caller's groups are stored as "groups"
+// and their primary group as "role". Personal API tokens are accepted as well;
+// their permission scope is enforced by PermissionMiddleware.
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
@@ -30,6 +45,10 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		token := strings.TrimPrefix(header, "Bearer ")
+		if strings.HasPrefix(token, authusecases.APITokenPrefix) {
+			m.authenticateAPIToken(c, token)
+			return
+		}
 		claims, err := m.jwtService.ValidateAccessToken(token)
 		if err != nil {
 			http.RespondWithUnauthorized(c, "invalid token")
@@ -37,8 +56,92 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		if m.sessions != nil {
+			ctx := c.Request.Context()
+			sess, err := m.sessions.GetByTokenHash(ctx, utils.HashString(token))
+			if err != nil {
+				logger.Log.WithError(err).Error("failed to resolve session")
+				http.RespondWithInternalError(c, "failed to resolve session")
+				c.Abort()
+				return
+			}
+			now := time.Now()
+			if sess == nil || !sess.IsActive || now.After(sess.ExpiresAt) {
+				http.RespondWithUnauthorized(c, "session revoked or expired")
+				c.Abort()
+				return
+			}
+			if m.idleTimeout > 0 && now.Sub(sess.LastActivity) > m.idleTimeout {
+				logger.Log.WithField("sessionID", sess.ID).Info("session idle timeout")
+				if err := m.sessions.Deactivate(ctx, sess.ID); err != nil {
+					logger.Log.WithError(err).Warn("failed to deactivate idle session")
+				}
+				http.RespondWithUnauthorized(c, "session expired")
+				c.Abort()
+				return
+			}
+			// tokens stamped with an older membership must be refreshed so
+			// they do not keep the permissions of groups the user left
+			if claims.GroupsVersion != 0 && claims.GroupsVersion != sess.GroupsVersion {
+				http.RespondWithUnauthorized(c, "group membership changed")
+				c.Abort()
+				return
+			}
+			if err := m.sessions.TouchActivity(ctx, sess.ID, now); err != nil {
+				logger.Log.WithError(err).Warn("failed to update session activity")
+			}
+			c.Set("userID", sess.UserID)
+			c.Set("sessionID", sess.ID)
+		}
+
+		groups := claims.Groups
+		if len(groups) == 0 && claims.Role != "" {
+			groups = []string{claims.Role}
+		}
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("groups", groups)
+		attachChangeActor(c, claims.Username)
 		c.Next()
 	}
 }
+
+// authenticateAPIToken resolves a personal API token. The request runs as
+// the token owner, limited to the token's permissions.
+func (m *AuthMiddleware) authenticateAPIToken(c *gin.Context, token string) {
+	if m.apiTokens == nil {
+		http.RespondWithUnauthorized(c, "invalid token")
+		c.Abort()
+		return
+	}
+	t, err := m.apiTokens.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		logger.Log.WithError(err).Error("failed to resolve api token")
+		http.RespondWithInternalError(c, "failed to resolve api token")
+		c.Abort()
+		return
+	}
+	if t == nil {
+		http.RespondWithUnauthorized(c, "api token revoked or expired")
+		c.Abort()
+		return
+	}
+	c.Set("userID", t.UserID)
+	c.Set("username", t.Username)
+	c.Set("role", t.Group)
+	c.Set("groups", t.Groups)
+	c.Set("apiTokenID", t.ID)
+	c.Set("apiTokenPermissions", t.Permissions)
+	attachChangeActor(c, t.Username)
+	c.Next()
+}
+
+// attachChangeActor attributes VPN user and group changes made while
+// serving the request, including approved changes, to the caller.
+func attachChangeActor(c *gin.Context, username string) {
+	ctx := vpnentities.WithChangeActor(c.Request.Context(), vpnentities.ChangeActor{
+		Username:  username,
+		RequestID: c.GetString("requestID"),
+	})
+	c.Request = c.Request.WithContext(ctx)
+}
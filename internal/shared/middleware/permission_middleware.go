@@ -3,21 +3,33 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	vpnentities "system-portal/internal/domains/openvpn/entities"
 	portalrepos "system-portal/internal/domains/portal/repositories"
+	"system-portal/pkg/logger"
 )
 
 // PermissionMiddleware performs RBAC checks using group permissions.
 type PermissionMiddleware struct {
-	perms  portalrepos.PermissionRepository
-	groups portalrepos.GroupRepository
+	perms      portalrepos.PermissionRepository
+	groups     portalrepos.GroupRepository
+	elevations portalrepos.ElevationRepository
 }
 
-func NewPermissionMiddleware(p portalrepos.PermissionRepository, g portalrepos.GroupRepository) *PermissionMiddleware {
-	return &PermissionMiddleware{perms: p, groups: g}
+// NewPermissionMiddleware checks group grants, and active elevation grants
+// when e is not nil.
+func NewPermissionMiddleware(p portalrepos.PermissionRepository, g portalrepos.GroupRepository, e portalrepos.ElevationRepository) *PermissionMiddleware {
+	return &PermissionMiddleware{perms: p, groups: g, elevations: e}
 }
 
+// RequirePermission allows the request when one of the caller's groups
+// holds perm, or an active elevation grant of the caller does. A grant
+// limited to certain VPN groups is attached to the request context, where
+// the openvpn usecases enforce it; with several grants the caller gets the
+// union of their scopes.
 func (m *PermissionMiddleware) RequirePermission(perm string) gin.HandlerFunc {
 	parts := strings.SplitN(perm, ".", 2)
 	if len(parts) != 2 {
@@ -25,27 +37,159 @@ func (m *PermissionMiddleware) RequirePermission(perm string) gin.HandlerFunc {
 	}
 	resource, action := parts[0], parts[1]
 	return func(c *gin.Context) {
-		role := c.GetString("role")
-		if role == "" {
+		groups := callerGroups(c)
+		if len(groups) == 0 {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": gin.H{"code": "FORBIDDEN", "message": "forbidden", "status": http.StatusForbidden}})
 			return
 		}
-		allowed, err := m.perms.HasGroupPermission(c.Request.Context(), role, resource, action)
+		if !tokenAllows(c, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": gin.H{"code": "FORBIDDEN", "message": "permission not granted to api token", "status": http.StatusForbidden}})
+			return
+		}
+		allowed, scope, err := m.check(c, groups, resource, action)
 		if err != nil || !allowed {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": gin.H{"code": "FORBIDDEN", "message": "insufficient permissions", "status": http.StatusForbidden}})
 			return
 		}
+		attachScope(c, scope)
 		c.Next()
 	}
 }
 
+// PermittedKey is the gin context key ProbePermission sets when the caller
+// holds perm.
+func PermittedKey(perm string) string { return "permitted." + perm }
+
+// ProbePermission never rejects a request. When the caller holds perm it
+// sets PermittedKey(perm) and attaches the grant's VPN group scope as
+// RequirePermission does, so handlers can show more to those who hold it.
+func (m *PermissionMiddleware) ProbePermission(perm string) gin.HandlerFunc {
+	parts := strings.SplitN(perm, ".", 2)
+	if len(parts) != 2 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	resource, action := parts[0], parts[1]
+	return func(c *gin.Context) {
+		groups := callerGroups(c)
+		if len(groups) > 0 && tokenAllows(c, perm) {
+			allowed, scope, err := m.check(c, groups, resource, action)
+			if err != nil {
+				logger.Log.WithError(err).Warn("failed to check permission")
+			}
+			if err == nil && allowed {
+				c.Set(PermittedKey(perm), true)
+				attachScope(c, scope)
+			}
+		}
+		c.Next()
+	}
+}
+
+// check reports whether groups or the caller's elevation grants hold the
+// permission, and the VPN group scope it is limited to.
+func (m *PermissionMiddleware) check(c *gin.Context, groups []string, resource, action string) (bool, []string, error) {
+	allowed, scope, err := portalrepos.GroupsPermissionScope(c.Request.Context(), m.perms, groups, resource, action)
+	if err == nil && (!allowed || len(scope) > 0) {
+		allowed, scope = m.elevate(c, resource, action, allowed, scope)
+	}
+	return allowed, scope, err
+}
+
+func attachScope(c *gin.Context, scope []string) {
+	if len(scope) > 0 {
+		ctx := vpnentities.WithGroupScope(c.Request.Context(), vpnentities.GroupScope(scope))
+		c.Request = c.Request.WithContext(ctx)
+	}
+}
+
+// elevate widens a group check with the caller's active elevation grants,
+// either of the permission itself or of a group holding it. Elevation is
+// given to people, so requests made with API tokens do not get it.
+func (m *PermissionMiddleware) elevate(c *gin.Context, resource, action string, allowed bool, scope []string) (bool, []string) {
+	if m.elevations == nil {
+		return allowed, scope
+	}
+	if _, isToken := c.Get("apiTokenID"); isToken {
+		return allowed, scope
+	}
+	v, _ := c.Get("userID")
+	userID, _ := v.(uuid.UUID)
+	if userID == uuid.Nil {
+		return allowed, scope
+	}
+	ctx := c.Request.Context()
+	grants, err := m.elevations.ListActive(ctx, userID, time.Now())
+	if err != nil {
+		logger.Log.WithError(err).Warn("failed to load elevation grants")
+		return allowed, scope
+	}
+	perm := resource + "." + action
+	for _, g := range grants {
+		ok, s := false, []string(nil)
+		switch {
+		case g.Permission == perm:
+			ok, s = true, g.VPNGroupScope
+		case g.GroupName != "":
+			if ok, s, err = m.perms.GroupPermissionScope(ctx, g.GroupName, resource, action); err != nil {
+				logger.Log.WithError(err).Warn("failed to check elevated group")
+				continue
+			}
+		}
+		if !ok {
+			continue
+		}
+		if len(s) == 0 {
+			return true, nil
+		}
+		allowed = true
+		scope = append(scope, s...)
+	}
+	return allowed, scope
+}
+
+// RequireGroup restricts a route to members of group. API tokens carry a
+// permission scope rather than group membership, so they are rejected.
 func RequireGroup(group string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role := c.GetString("role")
-		if strings.ToLower(role) != strings.ToLower(group) {
+		_, isToken := c.Get("apiTokenID")
+		member := false
+		for _, g := range callerGroups(c) {
+			member = member || strings.EqualFold(g, group)
+		}
+		if isToken || !member {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": gin.H{"code": "FORBIDDEN", "message": "forbidden", "status": http.StatusForbidden}})
 			return
 		}
 		c.Next()
 	}
 }
+
+// callerGroups returns the groups set by AuthMiddleware, falling back to the
+// primary group alone.
+func callerGroups(c *gin.Context) []string {
+	if v, ok := c.Get("groups"); ok {
+		if groups, _ := v.([]string); len(groups) > 0 {
+			return groups
+		}
+	}
+	if role := c.GetString("role"); role != "" {
+		return []string{role}
+	}
+	return nil
+}
+
+// tokenAllows reports whether a request made with an API token may use perm.
+// Requests authenticated otherwise are not limited.
+func tokenAllows(c *gin.Context, perm string) bool {
+	v, ok := c.Get("apiTokenPermissions")
+	if !ok {
+		return true
+	}
+	granted, _ := v.([]string)
+	for _, p := range granted {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
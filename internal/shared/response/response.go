@@ -1,9 +1,12 @@
 package response
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"system-portal/internal/shared/errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -192,3 +195,21 @@ func RespondWithConflict(c *gin.Context, message string) {
 
 	c.JSON(http.StatusConflict, response)
 }
+
+// RespondWithTooManyRequests sends a rate limit error response with a
+// Retry-After header
+func RespondWithTooManyRequests(c *gin.Context, message string, retryAfter time.Duration) {
+	response := ErrorResponse{}
+	response.Error.Code = "TOO_MANY_REQUESTS"
+	response.Error.Message = message
+	response.Error.Status = http.StatusTooManyRequests
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.Header("Cache-Control", "no-store")
+
+	c.JSON(http.StatusTooManyRequests, response)
+}
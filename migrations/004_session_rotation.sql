@@ -0,0 +1,8 @@
+-- Refresh token rotation: sessions created by refreshing share a family so
+-- reuse of a rotated refresh token can revoke every descendant session
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP WITH TIME ZONE;
+UPDATE user_sessions SET family_id = id WHERE family_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_refresh_token_hash ON user_sessions(refresh_token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_family_id ON user_sessions(family_id);
@@ -0,0 +1,20 @@
+-- Where a portal user's password is checked: 'local' (bcrypt hash) or 'ldap'
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auth_source VARCHAR(20) DEFAULT 'local';
+-- Distinguished name of the directory entry an LDAP user is linked to
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id TEXT;
+-- Directory-provisioned users have no local password
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+-- Map AD group membership (memberOf) to a portal group. When a user is in
+-- several mapped groups the mapping with the lowest priority wins.
+CREATE TABLE IF NOT EXISTS ldap_group_mappings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ldap_group_dn TEXT NOT NULL,
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    priority INT NOT NULL DEFAULT 100,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ldap_group_mappings_dn ON ldap_group_mappings(LOWER(ldap_group_dn));
+CREATE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id);
@@ -0,0 +1,15 @@
+-- Pending OpenID Connect logins, one row per redirect to the IdP, consumed
+-- by the callback
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    state_hash TEXT NOT NULL UNIQUE,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
+
+-- OIDC users are linked by issuer and subject rather than username
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_auth_source_external_id ON users(auth_source, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Failed login tracking for brute-force protection. scope is 'user'
+-- (lower-cased username, whether or not it exists) or 'ip'.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope VARCHAR(10) NOT NULL,
+    key TEXT NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- progressive delay: no attempt is accepted before this time
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    -- temporary lockout once the failure threshold is reached
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
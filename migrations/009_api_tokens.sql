@@ -0,0 +1,23 @@
+-- Personal API tokens for automation. Only a SHA-256 hash of the token is
+-- stored, prefix is kept so users can tell their tokens apart.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    prefix VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- The permissions a token is limited to, always a subset of its owner's
+-- group permissions at creation time.
+CREATE TABLE IF NOT EXISTS api_token_permissions (
+    token_id UUID REFERENCES api_tokens(id) ON DELETE CASCADE,
+    permission_id UUID REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (token_id, permission_id)
+);
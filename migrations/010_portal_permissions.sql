@@ -0,0 +1,34 @@
+-- Permissions for the portal admin API, checked per route instead of
+-- requiring membership of the admin group
+INSERT INTO permissions (resource, action, description) VALUES
+    ('portal', 'view_groups', 'View portal groups and the permission catalog'),
+    ('portal', 'manage_groups', 'Manage portal groups and their permissions'),
+    ('portal', 'manage_permissions', 'Manage the permission catalog'),
+    ('audit', 'view_logs', 'View audit logs and statistics'),
+    ('audit', 'export_logs', 'Export audit logs'),
+    ('config', 'view_connections', 'View OpenVPN and LDAP connection settings'),
+    ('config', 'manage_connections', 'Manage OpenVPN and LDAP connection settings'),
+    ('dashboard', 'view_stats', 'View dashboard statistics and charts')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Admins keep full access to the portal API
+INSERT INTO group_permissions (group_id, permission_id)
+SELECT g.id, p.id FROM groups g
+JOIN permissions p ON p.resource IN ('portal', 'audit', 'config', 'dashboard')
+WHERE g.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- Read-only access to the audit trail
+INSERT INTO groups (name, display_name, description)
+VALUES ('auditor', 'Auditor', 'Read-only access to audit logs')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO group_permissions (group_id, permission_id)
+SELECT g.id, p.id FROM groups g
+JOIN permissions p ON (p.resource, p.action) IN (
+    ( 'audit', 'view_logs' ),
+    ( 'audit', 'export_logs' ),
+    ( 'dashboard', 'view_stats' )
+)
+WHERE g.name = 'auditor'
+ON CONFLICT DO NOTHING;
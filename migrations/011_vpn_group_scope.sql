@@ -0,0 +1,3 @@
+-- Grants on openvpn permissions can be limited to VPN groups whose names
+-- match one of these patterns. NULL means the grant covers every VPN group
+ALTER TABLE group_permissions ADD COLUMN IF NOT EXISTS vpn_group_scope TEXT[];
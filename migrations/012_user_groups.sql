@@ -0,0 +1,19 @@
+-- Portal users can belong to several groups. users.group_id remains the
+-- primary group, shown as the user's role, and is always a member as well
+CREATE TABLE IF NOT EXISTS user_groups (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_groups_group_id ON user_groups(group_id);
+
+-- Every user is a member of their primary group
+INSERT INTO user_groups (user_id, group_id)
+SELECT id, group_id FROM users WHERE group_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- groups_version is bumped whenever memberships change so access tokens
+-- issued for the old memberships stop being accepted
+ALTER TABLE users ADD COLUMN IF NOT EXISTS groups_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,41 @@
+-- Operations guarded by a permission listed in security.approval.permissions
+-- are held as change requests until a second user approves them
+CREATE TABLE IF NOT EXISTS change_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    operation VARCHAR(100) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    target VARCHAR(200),
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    requested_by_name VARCHAR(50) NOT NULL,
+    requester_group VARCHAR(50),
+    requester_scope TEXT[],
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by_name VARCHAR(50),
+    review_comment TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    result JSONB,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    executed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_requests_status ON change_requests(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_change_requests_requested_by ON change_requests(requested_by);
+
+INSERT INTO permissions (resource, action, description) VALUES
+    ('portal', 'view_approvals', 'View change requests awaiting approval'),
+    ('portal', 'approve_changes', 'Approve or reject change requests of other users')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO group_permissions (group_id, permission_id)
+SELECT g.id, p.id FROM groups g
+JOIN permissions p ON (p.resource, p.action) IN (
+    ( 'portal', 'view_approvals' ),
+    ( 'portal', 'approve_changes' )
+)
+WHERE g.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,37 @@
+-- Time-bound elevation of a portal user to a permission or group, requested
+-- with a justification and active from approval until expires_at
+CREATE TABLE IF NOT EXISTS elevation_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(50) NOT NULL,
+    permission VARCHAR(100),
+    group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+    group_name VARCHAR(50),
+    vpn_group_scope TEXT[] NOT NULL DEFAULT '{}',
+    justification TEXT NOT NULL,
+    duration_minutes INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    auto_approved BOOLEAN NOT NULL DEFAULT FALSE,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_by_name VARCHAR(50),
+    review_comment TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((permission IS NULL) <> (group_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_elevation_grants_user_status ON elevation_grants(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_elevation_grants_status_expires ON elevation_grants(status, expires_at);
+
+INSERT INTO permissions (resource, action, description) VALUES
+    ('portal', 'approve_elevations', 'Approve, reject and revoke temporary elevation of other users')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO group_permissions (group_id, permission_id)
+SELECT g.id, p.id FROM groups g
+JOIN permissions p ON p.resource = 'portal' AND p.action = 'approve_elevations'
+WHERE g.name = 'admin'
+ON CONFLICT DO NOTHING;
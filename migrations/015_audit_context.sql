@@ -0,0 +1,12 @@
+-- Request context of audited API calls. action holds a semantic name such
+-- as openvpn.user.disable and resource_name the resolved target
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS http_method VARCHAR(10);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS route VARCHAR(200);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS path_params JSONB;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_body JSONB;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS status_code INTEGER;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS duration_ms INTEGER;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS user_agent VARCHAR(500);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs(request_id);
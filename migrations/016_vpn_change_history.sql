@@ -0,0 +1,27 @@
+-- Before and after snapshots of every change made to an OpenVPN user or
+-- group, with the changed fields, for per-entity compliance timelines
+CREATE TABLE IF NOT EXISTS vpn_change_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type VARCHAR(10) NOT NULL CHECK (entity_type IN ('user', 'group')),
+    entity_name VARCHAR(255) NOT NULL,
+    group_name VARCHAR(255) NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    before_state JSONB,
+    after_state JSONB,
+    changes JSONB NOT NULL DEFAULT '[]',
+    actor VARCHAR(255),
+    request_id VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vpn_change_history_entity ON vpn_change_history(entity_type, entity_name, created_at DESC);
+
+INSERT INTO permissions (resource, action, description) VALUES
+    ('openvpn', 'view_history', 'View the change history of OpenVPN users and groups')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO group_permissions (group_id, permission_id)
+SELECT g.id, p.id FROM groups g
+JOIN permissions p ON p.resource = 'openvpn' AND p.action = 'view_history'
+WHERE g.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,24 @@
+-- Hash chain over audit entries. Each chained row stores a hash of its
+-- content and of the previous row's hash, in seq order. Rows written before
+-- this migration have no hash and precede the chain
+CREATE SEQUENCE IF NOT EXISTS audit_logs_seq_seq;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS seq BIGINT NOT NULL DEFAULT nextval('audit_logs_seq_seq');
+ALTER SEQUENCE audit_logs_seq_seq OWNED BY audit_logs.seq;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_seq ON audit_logs(seq);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_chain_head ON audit_logs(seq DESC) WHERE hash IS NOT NULL;
+
+-- Signed statements of the chain head, for export to an external system
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    key_id VARCHAR(32) NOT NULL,
+    public_key TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_seq ON audit_checkpoints(seq);
@@ -0,0 +1,27 @@
+-- Retention moves expired audit entries and sessions to archive files.
+-- Purged chained entries leave a tombstone so the hash chain still links
+CREATE TABLE IF NOT EXISTS audit_archives (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    source VARCHAR(50) NOT NULL,
+    row_count INTEGER NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    from_time TIMESTAMP WITH TIME ZONE,
+    to_time TIMESTAMP WITH TIME ZONE,
+    min_seq BIGINT,
+    max_seq BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    restored_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS audit_log_tombstones (
+    seq BIGINT PRIMARY KEY,
+    id UUID NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    archive_id UUID REFERENCES audit_archives(id) ON DELETE SET NULL
+);
+
+-- Entries restored from an archive are kept for a while before retention
+-- purges them again
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS restored_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,15 @@
+-- Audit entries a forwarding sink (syslog or webhook) still failed to take
+-- after every retry. They are redelivered later and removed once accepted
+CREATE TABLE IF NOT EXISTS audit_dead_letters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sink VARCHAR(100) NOT NULL,
+    audit_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Redelivery takes the least recently tried letters of the configured sinks
+CREATE INDEX IF NOT EXISTS idx_audit_dead_letters_retry ON audit_dead_letters(sink, last_attempt_at);
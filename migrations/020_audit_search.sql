@@ -0,0 +1,21 @@
+-- Audit search: a full-text document per entry (actor, action, target and
+-- the string values of its path parameters and request body), trigram
+-- matching on resource names and composite indexes for keyset pagination
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS search TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple'::regconfig,
+        COALESCE(username, '') || ' ' || COALESCE(user_group, '') || ' ' ||
+        COALESCE(action, '') || ' ' || COALESCE(resource_type, '') || ' ' ||
+        translate(COALESCE(resource_name, ''), '/', ' ') || ' ' || COALESCE(request_id, ''))
+    || jsonb_to_tsvector('simple'::regconfig, COALESCE(path_params, '{}'::jsonb), '["string"]')
+    || jsonb_to_tsvector('simple'::regconfig, COALESCE(request_body, '{}'::jsonb), '["string"]')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_search ON audit_logs USING GIN (search);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_name_trgm ON audit_logs USING GIN (resource_name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_seq ON audit_logs(created_at, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_username_seq ON audit_logs(username, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_seq ON audit_logs(action, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_type_seq ON audit_logs(resource_type, seq);
@@ -6,22 +6,38 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type RSAService struct {
-	privateKey        *rsa.PrivateKey
-	publicKey         *rsa.PublicKey
+	// mu guards the access keys, which can be swapped by UseKeyring
+	mu         sync.RWMutex
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+	accessKID  string
+	// accessKeys holds every key access tokens may be verified with by kid
+	accessKeys map[string]*rsa.PublicKey
+
 	refreshPrivateKey *rsa.PrivateKey
 	refreshPublicKey  *rsa.PublicKey
 	accessExpiry      time.Duration
 	refreshExpiry     time.Duration
 }
+
+// Claims identify the user a token was issued to. Role is the primary
+// group; Groups lists every group the user belonged to when the token was
+// issued and GroupsVersion stamps that membership so it can be checked for
+// changes. Tokens issued before multiple groups existed carry neither.
 type Claims struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	Username      string   `json:"username"`
+	Role          string   `json:"role"`
+	Groups        []string `json:"groups,omitempty"`
+	GroupsVersion int      `json:"gv,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -38,14 +54,14 @@ func NewRSAService(accessExpiry, refreshExpiry time.Duration) (*RSAService, erro
 		return nil, fmt.Errorf("failed to generate refresh RSA key: %w", err)
 	}
 
-	return &RSAService{
-		privateKey:        accessPrivateKey,
-		publicKey:         &accessPrivateKey.PublicKey,
+	s := &RSAService{
 		refreshPrivateKey: refreshPrivateKey,
 		refreshPublicKey:  &refreshPrivateKey.PublicKey,
 		accessExpiry:      accessExpiry,
 		refreshExpiry:     refreshExpiry,
-	}, nil
+	}
+	s.setAccessKey(accessPrivateKey)
+	return s, nil
 }
 
 // NewRSAServiceWithKeys creates a new JWT service with provided RSA keys
@@ -62,14 +78,92 @@ func NewRSAServiceWithKeys(privateKeyPEM, refreshPrivateKeyPEM string, accessExp
 		return nil, fmt.Errorf("failed to parse refresh private key: %w", err)
 	}
 
-	return &RSAService{
-		privateKey:        accessPrivateKey,
-		publicKey:         &accessPrivateKey.PublicKey,
+	s := &RSAService{
 		refreshPrivateKey: refreshPrivateKey,
 		refreshPublicKey:  &refreshPrivateKey.PublicKey,
 		accessExpiry:      accessExpiry,
 		refreshExpiry:     refreshExpiry,
-	}, nil
+	}
+	s.setAccessKey(accessPrivateKey)
+	return s, nil
+}
+
+// NewRSAServiceWithKeyring creates a JWT service that signs access tokens
+// with the keyring's active key and verifies them with any of its keys.
+func NewRSAServiceWithKeyring(k *Keyring, refreshPrivateKeyPEM string, accessExpiry, refreshExpiry time.Duration) (*RSAService, error) {
+	refreshPrivateKey, err := parseRSAPrivateKey(refreshPrivateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse refresh private key: %w", err)
+	}
+	s := &RSAService{
+		refreshPrivateKey: refreshPrivateKey,
+		refreshPublicKey:  &refreshPrivateKey.PublicKey,
+		accessExpiry:      accessExpiry,
+		refreshExpiry:     refreshExpiry,
+	}
+	if err := s.UseKeyring(k); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// setAccessKey makes key the only access token key.
+func (s *RSAService) setAccessKey(key *rsa.PrivateKey) {
+	kid := KeyID(&key.PublicKey)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.privateKey = key
+	s.publicKey = &key.PublicKey
+	s.accessKID = kid
+	s.accessKeys = map[string]*rsa.PublicKey{kid: &key.PublicKey}
+}
+
+// UseKeyring switches access token signing to the keyring's active key and
+// accepts tokens signed with any of its keys. It can be called again after
+// the keyring was rotated.
+func (s *RSAService) UseKeyring(k *Keyring) error {
+	active, ok := k.private[k.Active]
+	if !ok {
+		return fmt.Errorf("active key %q not found in keyring", k.Active)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(k.private))
+	for kid, key := range k.private {
+		keys[kid] = &key.PublicKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.privateKey = active
+	s.publicKey = &active.PublicKey
+	s.accessKID = k.Active
+	s.accessKeys = keys
+	return nil
+}
+
+// AccessKeyID returns the kid of the key currently signing access tokens.
+func (s *RSAService) AccessKeyID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.accessKID
+}
+
+// JWKS returns the public keys access tokens can be verified with, the
+// active key first.
+func (s *RSAService) JWKS() JSONWebKeySet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(s.accessKeys))}
+	kids := make([]string, 0, len(s.accessKeys))
+	for kid := range s.accessKeys {
+		if kid != s.accessKID {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+	kids = append([]string{s.accessKID}, kids...)
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, newJSONWebKey(kid, s.accessKeys[kid]))
+	}
+	return set
 }
 
 func parseRSAPrivateKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
@@ -96,11 +190,14 @@ func parseRSAPrivateKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
 	return privateKey, nil
 }
 
-func (s *RSAService) GenerateAccessToken(username, role string) (string, error) {
+func (s *RSAService) GenerateAccessToken(username, role string, groups []string, groupsVersion int) (string, error) {
 	claims := &Claims{
-		Username: username,
-		Role:     role,
+		Username:      username,
+		Role:          role,
+		Groups:        groups,
+		GroupsVersion: groupsVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -109,8 +206,12 @@ func (s *RSAService) GenerateAccessToken(username, role string) (string, error)
 		},
 	}
 
+	s.mu.RLock()
+	key, kid := s.privateKey, s.accessKID
+	s.mu.RUnlock()
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(s.privateKey)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
 }
 
 func (s *RSAService) GenerateRefreshToken(username, role string) (string, error) {
@@ -118,6 +219,7 @@ func (s *RSAService) GenerateRefreshToken(username, role string) (string, error)
 		Username: username,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -130,21 +232,42 @@ func (s *RSAService) GenerateRefreshToken(username, role string) (string, error)
 	return token.SignedString(s.refreshPrivateKey)
 }
 
+// AccessTokenTTL returns the lifetime of issued access tokens
+func (s *RSAService) AccessTokenTTL() time.Duration { return s.accessExpiry }
+
+// RefreshTokenTTL returns the lifetime of issued refresh tokens
+func (s *RSAService) RefreshTokenTTL() time.Duration { return s.refreshExpiry }
+
 func (s *RSAService) ValidateAccessToken(tokenString string) (*Claims, error) {
-	return s.validateToken(tokenString, s.publicKey)
+	return s.validateToken(tokenString, s.accessVerificationKey)
 }
 
 func (s *RSAService) ValidateRefreshToken(tokenString string) (*Claims, error) {
-	return s.validateToken(tokenString, s.refreshPublicKey)
+	return s.validateToken(tokenString, func(string) (*rsa.PublicKey, error) { return s.refreshPublicKey, nil })
+}
+
+// accessVerificationKey picks the key for a token's kid. Tokens issued
+// before kids were added are checked against the active key.
+func (s *RSAService) accessVerificationKey(kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if kid == "" {
+		return s.publicKey, nil
+	}
+	if key, ok := s.accessKeys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
 }
 
-func (s *RSAService) validateToken(tokenString string, publicKey *rsa.PublicKey) (*Claims, error) {
+func (s *RSAService) validateToken(tokenString string, keyFor func(kid string) (*rsa.PublicKey, error)) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Verify the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return publicKey, nil
+		kid, _ := token.Header["kid"].(string)
+		return keyFor(kid)
 	})
 
 	if err != nil {
@@ -160,6 +283,8 @@ func (s *RSAService) validateToken(tokenString string, publicKey *rsa.PublicKey)
 
 // GetPublicKeyPEM returns the public key in PEM format for external verification
 func (s *RSAService) GetAccessPublicKeyPEM() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.publicKeyToPEM(s.publicKey)
 }
 
@@ -183,6 +308,8 @@ func (s *RSAService) publicKeyToPEM(publicKey *rsa.PublicKey) (string, error) {
 
 // GetPrivateKeyPEM returns the private key in PEM format (use carefully!)
 func (s *RSAService) GetAccessPrivateKeyPEM() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.privateKeyToPEM(s.privateKey)
 }
 
@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestFile names the keyring manifest inside a key directory.
+const ManifestFile = "keyring.json"
+
+// KeyInfo describes one access token signing key of a keyring. Retired keys
+// no longer sign tokens but stay published until tokens signed with them
+// have expired.
+type KeyInfo struct {
+	KID       string     `json:"kid"`
+	File      string     `json:"file"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+}
+
+// Keyring is a directory of RSA private keys with one active signing key.
+// Keys that are neither active nor retired are staged: published in the
+// JWKS so verifiers can fetch them before they start signing tokens.
+type Keyring struct {
+	Active string    `json:"active"`
+	Keys   []KeyInfo `json:"keys"`
+
+	dir     string
+	private map[string]*rsa.PrivateKey
+}
+
+// LoadKeyring reads the manifest and keys from dir.
+func LoadKeyring(dir string) (*Keyring, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring manifest: %w", err)
+	}
+	k := &Keyring{dir: dir, private: map[string]*rsa.PrivateKey{}}
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring manifest: %w", err)
+	}
+	for _, info := range k.Keys {
+		pemData, err := os.ReadFile(filepath.Join(dir, info.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", info.KID, err)
+		}
+		key, err := parseRSAPrivateKey(string(pemData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", info.KID, err)
+		}
+		if kid := KeyID(&key.PublicKey); kid != info.KID {
+			return nil, fmt.Errorf("key file %s does not match kid %s", info.File, info.KID)
+		}
+		k.private[info.KID] = key
+	}
+	if _, ok := k.private[k.Active]; !ok {
+		return nil, fmt.Errorf("active key %q not found in keyring", k.Active)
+	}
+	return k, nil
+}
+
+// OpenKeyring loads the keyring in dir, returning an empty keyring when the
+// directory has no manifest yet.
+func OpenKeyring(dir string) (*Keyring, error) {
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); os.IsNotExist(err) {
+		return &Keyring{dir: dir, private: map[string]*rsa.PrivateKey{}}, nil
+	}
+	return LoadKeyring(dir)
+}
+
+// Generate adds a new staged key and returns its kid. The manifest is not
+// written until Save is called.
+func (k *Keyring) Generate() (string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	kid := KeyID(&key.PublicKey)
+	file := kid + ".pem"
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.MkdirAll(k.dir, 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(k.dir, file), data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write key: %w", err)
+	}
+	k.Keys = append(k.Keys, KeyInfo{KID: kid, File: file, CreatedAt: time.Now().UTC()})
+	k.private[kid] = key
+	return kid, nil
+}
+
+// Activate makes kid the signing key and retires the previous one.
+func (k *Keyring) Activate(kid string) error {
+	if _, ok := k.private[kid]; !ok {
+		return fmt.Errorf("unknown key %q", kid)
+	}
+	if kid == k.Active {
+		return nil
+	}
+	now := time.Now().UTC()
+	for i := range k.Keys {
+		switch k.Keys[i].KID {
+		case k.Active:
+			k.Keys[i].RetiredAt = &now
+		case kid:
+			k.Keys[i].RetiredAt = nil
+		}
+	}
+	k.Active = kid
+	return nil
+}
+
+// Prune deletes keys retired longer than retain ago and returns their kids.
+// retain should exceed the access token lifetime so no valid token loses
+// its verification key.
+func (k *Keyring) Prune(retain time.Duration) ([]string, error) {
+	cutoff := time.Now().Add(-retain)
+	var kept []KeyInfo
+	var pruned []string
+	for _, info := range k.Keys {
+		if info.RetiredAt == nil || info.RetiredAt.After(cutoff) {
+			kept = append(kept, info)
+			continue
+		}
+		if err := os.Remove(filepath.Join(k.dir, info.File)); err != nil && !os.IsNotExist(err) {
+			return pruned, err
+		}
+		delete(k.private, info.KID)
+		pruned = append(pruned, info.KID)
+	}
+	k.Keys = kept
+	return pruned, nil
+}
+
+// Save writes the manifest atomically so a running server never reads a
+// partial file.
+func (k *Keyring) Save() error {
+	sort.SliceStable(k.Keys, func(i, j int) bool { return k.Keys[i].CreatedAt.Before(k.Keys[j].CreatedAt) })
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(k.dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write keyring manifest: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(k.dir, ManifestFile))
+}
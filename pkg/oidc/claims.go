@@ -0,0 +1,54 @@
+package oidc
+
+import "strings"
+
+// Claims are the verified claims of an ID token.
+type Claims map[string]interface{}
+
+// lookup resolves a claim by name; dotted names address nested objects,
+// e.g. "realm_access.roles".
+func (c Claims) lookup(name string) (interface{}, bool) {
+	if v, ok := c[name]; ok {
+		return v, true
+	}
+	var cur interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// String returns a string claim or "" when it is missing or not a string.
+func (c Claims) String(name string) string {
+	v, _ := c.lookup(name)
+	s, _ := v.(string)
+	return s
+}
+
+// Strings returns a claim that is either a list of strings or a single
+// string (some providers send one group as a plain value).
+func (c Claims) Strings(name string) []string {
+	v, ok := c.lookup(name)
+	if !ok {
+		return nil
+	}
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces secrets removed by RedactJSON.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys are matched against lower-cased JSON keys with '_' and '-'
+// removed, so "bindPassword", "new_password" and "client-secret" all match.
+var sensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "otp", "totp", "mfacode",
+	"recoverycode", "privatekey", "apikey", "credential", "authorization",
+}
+
+// sensitiveExactKeys only match whole keys. The MFA endpoints send the TOTP
+// or recovery code as "code", while keys like "countryCode" are harmless.
+var sensitiveExactKeys = []string{"code"}
+
+// IsSensitiveKey reports whether a JSON key or header name holds a secret.
+func IsSensitiveKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, s := range sensitiveExactKeys {
+		if k == s {
+			return true
+		}
+	}
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactJSON masks the values of sensitive keys at any depth of a JSON
+// document and replaces NUL characters, which jsonb rejects. It returns nil
+// when raw is empty or not valid JSON.
+func RedactJSON(raw []byte) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return strings.ReplaceAll(t, "\x00", "\uFFFD")
+	case map[string]interface{}:
+		for k, val := range t {
+			if strings.Contains(k, "\x00") {
+				delete(t, k)
+				k = strings.ReplaceAll(k, "\x00", "\uFFFD")
+			}
+			if IsSensitiveKey(k) {
+				if val != nil && val != "" {
+					t[k] = RedactedValue
+				}
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxPasswordBytes is the longest password bcrypt can hash.
+const maxPasswordBytes = 72
+
+// PasswordPolicy describes the complexity required of local passwords.
+type PasswordPolicy struct {
+	MinLength           int
+	RequireUppercase    bool
+	RequireLowercase    bool
+	RequireNumbers      bool
+	RequireSpecialChars bool
+}
+
+// Check returns an error describing every requirement password misses.
+func (p PasswordPolicy) Check(password string) error {
+	var upper, lower, number, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			number = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			special = true
+		}
+	}
+
+	var missing []string
+	if n := len([]rune(password)); n < p.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		missing = append(missing, fmt.Sprintf("at most %d bytes", maxPasswordBytes))
+	}
+	if p.RequireUppercase && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireNumbers && !number {
+		missing = append(missing, "a number")
+	}
+	if p.RequireSpecialChars && !special {
+		missing = append(missing, "a special character")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain %s", strings.Join(missing, ", "))
+	}
+	return nil
+}